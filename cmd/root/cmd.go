@@ -3,8 +3,10 @@ package root
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 
 	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/activitywatch"
 	"github.com/gabor-boros/minutes/internal/pkg/worklog"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -59,11 +62,25 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	initCommonFlags()
+	initActivityWatchFlags()
 	initClockifyFlags()
+	initCSVFlags()
+	initGitFlags()
 	initHarvestFlags()
+	initICalFlags()
+	initJSONFlags()
+	initKimaiFlags()
+	initOrgFlags()
+	initRedmineFlags()
 	initTempoFlags()
 	initTimewarriorFlags()
 	initTogglFlags()
+	initWatsonFlags()
+
+	initFetchFlags()
+	initUploadFlags()
+
+	rootCmd.AddCommand(fetchCmd, uploadCmd)
 }
 
 func initConfig() {
@@ -89,17 +106,75 @@ func initConfig() {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			cobra.CheckErr(err)
 		}
-	} else {
-		fmt.Println("Using config file:", viper.ConfigFileUsed(), configFile)
 	}
 
 	// Bind flags to config value
-	cobra.CheckErr(viper.BindPFlags(rootCmd.Flags()))
+	cobra.CheckErr(viper.BindPFlags(rootCmd.PersistentFlags()))
+	cobra.CheckErr(viper.BindPFlags(fetchCmd.Flags()))
+	cobra.CheckErr(viper.BindPFlags(uploadCmd.Flags()))
+
+	// The output depends on the target, so the config must be read first
+	if viper.ConfigFileUsed() != "" {
+		_, _ = fmt.Fprintln(getMessageOutput(), "Using config file:", viper.ConfigFileUsed(), configFile)
+	}
 }
 
-func runRootCmd(_ *cobra.Command, _ []string) {
-	var err error
+// getLocation returns the timezone set by the "timezone" option. If not set,
+// the local timezone returns.
+func getLocation() *time.Location {
+	rawTimezone := viper.GetString("timezone")
+	if rawTimezone == "" {
+		return time.Local
+	}
+
+	// The timezone is validated already
+	timezone, _ := time.LoadLocation(rawTimezone)
+	return timezone
+}
+
+// getTimezone returns the timezone of the source or target set by the
+// "<name>-timezone" option. If not set, the timezone set by the "timezone"
+// option returns.
+func getTimezone(name string) *time.Location {
+	rawTimezone := viper.GetString(fmt.Sprintf("%s-timezone", name))
+	if rawTimezone == "" {
+		return getLocation()
+	}
+
+	// The timezones are validated already
+	timezone, _ := time.LoadLocation(rawTimezone)
+	return timezone
+}
+
+// getActivityWatchRules returns the rules set by the "activitywatch-rules"
+// option, that is available as config table only.
+func getActivityWatchRules() ([]activitywatch.Rule, error) {
+	var rules []activitywatch.Rule
+	if err := viper.UnmarshalKey("activitywatch-rules", &rules); err != nil {
+		return nil, err
+	}
 
+	return rules, nil
+}
+
+// getRedmineActivities returns the activity IDs by name set by the
+// "redmine-activities" option, either as flag or as config table.
+func getRedmineActivities() (map[string]int, error) {
+	activities := make(map[string]int)
+
+	for name, rawID := range viper.GetStringMap("redmine-activities") {
+		activityID, err := strconv.Atoi(fmt.Sprint(rawID))
+		if err != nil {
+			return nil, fmt.Errorf("invalid redmine activity ID of %s: %v", name, rawID)
+		}
+
+		activities[name] = activityID
+	}
+
+	return activities, nil
+}
+
+func runRootCmd(_ *cobra.Command, _ []string) {
 	if viper.GetBool("version") {
 		if version == "" || len(commit) < 7 || date == "" {
 			fmt.Println("dirty build")
@@ -111,44 +186,108 @@ func runRootCmd(_ *cobra.Command, _ []string) {
 
 	validateFlags()
 
-	dateFormat := viper.GetString("date-format")
-
-	start, err := utils.GetTime(viper.GetString("start"), dateFormat)
+	uploader, err := getUploader()
 	cobra.CheckErr(err)
 
-	rawEnd := viper.GetString("end")
-	end, err := utils.GetTime(rawEnd, dateFormat)
-	cobra.CheckErr(err)
+	start, end := getDateRange()
+	entries := fetchSourceEntries(start, end)
 
-	// No end date was set, hence we are setting the end date to next day midnight
-	if rawEnd == "" {
-		end = end.Add(time.Hour * 24)
-	}
+	completeEntries := reviewEntries(start, end, entries, !viper.GetBool("dry-run"))
+	uploadEntries(uploader, completeEntries, viper.GetBool("round-to-closest-minute"))
+}
 
-	fetcher, err := getFetcher()
+// getMessageOutput returns the output of the messages shown to the user. If
+// the target writes the entries to the standard output, the messages are
+// written to the standard error.
+func getMessageOutput() io.Writer {
+	return utils.MessageOutput(getTargetPath())
+}
+
+// getDateRange returns the date range set by the "start" and "end" options.
+func getDateRange() (time.Time, time.Time) {
+	// The week start is validated already
+	weekStart, _ := utils.ParseWeekday(viper.GetString("week-start"))
+
+	start, end, err := utils.GetDateRange(
+		viper.GetString("start"),
+		viper.GetString("end"),
+		viper.GetString("date-format"),
+		time.Now().In(getLocation()),
+		weekStart,
+	)
 	cobra.CheckErr(err)
 
-	uploader, err := getUploader()
+	return start, end
+}
+
+// fetchSourceEntries fetches the entries of the source within the date range.
+func fetchSourceEntries(start time.Time, end time.Time) worklog.Entries {
+	fetcher, err := getPlannedFetcher()
 	cobra.CheckErr(err)
 
 	tagsAsTasksRegex, err := regexp.Compile(viper.GetString("tags-as-tasks-regex"))
 	cobra.CheckErr(err)
 
-	entries, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+	entries, err := fetchEntries(context.Background(), fetcher, &client.FetchOpts{
 		End:              end,
 		Start:            start,
 		User:             viper.GetString("source-user"),
 		TagsAsTasksRegex: tagsAsTasksRegex,
+		RunningEntries:   viper.GetString("running-entries"),
 	})
 	cobra.CheckErr(err)
 
+	return entries
+}
+
+// getFilterOpts returns the filter options set by the "filter-*" and
+// "exclude-*" options.
+func getFilterOpts() *worklog.FilterOpts {
 	// It is safe to use MustCompile when compiling regex as we already
 	// validated its correctness
-	wl := worklog.NewWorklog(entries, &worklog.FilterOpts{
-		Client:  regexp.MustCompile(viper.GetString("filter-client")),
-		Project: regexp.MustCompile(viper.GetString("filter-project")),
+	return &worklog.FilterOpts{
+		Client:         regexp.MustCompile(viper.GetString("filter-client")),
+		Project:        regexp.MustCompile(viper.GetString("filter-project")),
+		Task:           regexp.MustCompile(viper.GetString("filter-task")),
+		Summary:        regexp.MustCompile(viper.GetString("filter-summary")),
+		Tag:            regexp.MustCompile(viper.GetString("filter-tag")),
+		ExcludeClient:  regexp.MustCompile(viper.GetString("exclude-client")),
+		ExcludeProject: regexp.MustCompile(viper.GetString("exclude-project")),
+		ExcludeTask:    regexp.MustCompile(viper.GetString("exclude-task")),
+		ExcludeSummary: regexp.MustCompile(viper.GetString("exclude-summary")),
+		ExcludeTag:     regexp.MustCompile(viper.GetString("exclude-tag")),
+		BillableOnly:   viper.GetBool("billable-only"),
+		UnbillableOnly: viper.GetBool("unbillable-only"),
+		MinDuration:    viper.GetDuration("min-entry-duration"),
+	}
+}
+
+// reviewEntries prints the worklog built from the entries, and reports the
+// validation errors and overlapping entries. If abort is set, the command
+// exits when errors or overlaps are found and not allowed. The complete
+// entries of the worklog return.
+func reviewEntries(start time.Time, end time.Time, entries worklog.Entries, abort bool) worklog.Entries {
+	validationRules, err := getValidationRules()
+	cobra.CheckErr(err)
+
+	filterOpts := getFilterOpts()
+	location := getLocation()
+
+	// Time of day values are already validated, so we can ignore the errors
+	workdayStart, _ := utils.ParseTimeOfDay(viper.GetString("workday-start"))
+	workdayEnd, _ := utils.ParseTimeOfDay(viper.GetString("workday-end"))
+
+	// The analysis must happen before merging the entries, otherwise the
+	// start and end dates are not precise
+	analysis := worklog.Analyze(worklog.FilterEntries(entries, filterOpts), &worklog.AnalyzeOpts{
+		WorkdayStart:     workdayStart,
+		WorkdayEnd:       workdayEnd,
+		IdleGapThreshold: viper.GetDuration("idle-gap-threshold"),
+		Location:         location,
 	})
 
+	wl := worklog.NewWorklog(entries, filterOpts)
+
 	completeEntries := wl.CompleteEntries()
 	incompleteEntries := wl.IncompleteEntries()
 
@@ -156,11 +295,13 @@ func runRootCmd(_ *cobra.Command, _ []string) {
 	err = viper.UnmarshalKey("table-column-truncates", &columnTruncates)
 	cobra.CheckErr(err)
 
+	output := getMessageOutput()
+
 	tablePrinter := utils.NewTablePrinter(&utils.TablePrinterOpts{
 		BasePrinterOpts: utils.BasePrinterOpts{
-			Output:        os.Stdout,
+			Output:        output,
 			AutoIndex:     true,
-			Title:         fmt.Sprintf("Worklog entries (%s - %s)", start.Local().String(), end.Local().String()),
+			Title:         fmt.Sprintf("Worklog entries (%s - %s)", start.In(location).String(), end.In(location).String()),
 			SortBy:        viper.GetStringSlice("table-sort-by"),
 			HiddenColumns: viper.GetStringSlice("table-hide-column"),
 		},
@@ -170,38 +311,74 @@ func runRootCmd(_ *cobra.Command, _ []string) {
 			viper.GetStringSlice("table-hide-column"),
 		),
 		ColumnTruncates: columnTruncates,
+		Warnings:        analysis.Warnings(),
+		Location:        location,
 	})
 
 	err = tablePrinter.Print(completeEntries, incompleteEntries)
 	cobra.CheckErr(err)
 
-	if strings.ToLower(utils.Prompt("Continue? [y/n]: ")) != "y" {
-		fmt.Println("User interruption. Aborting.")
+	validationReport := wl.Validate(validationRules)
+	utils.PrintValidationReport(output, validationReport, table.StyleLight)
+
+	if validationReport.HasErrors() && !viper.GetBool("ignore-validation-errors") && abort {
+		_, _ = fmt.Fprintln(output, "Validation failed. Resolve the errors or use --ignore-validation-errors. Aborting.")
+		os.Exit(1)
+	}
+
+	if analysis.HasOverlaps() && !viper.GetBool("allow-overlaps") && abort {
+		_, _ = fmt.Fprintf(output, "Found %d overlapping entries. Resolve them or use --allow-overlaps. Aborting.\n", len(analysis.Overlaps))
+		os.Exit(1)
+	}
+
+	return completeEntries
+}
+
+// uploadEntries uploads the complete entries to the target after confirming
+// the upload, unless it is a dry run. The confirmation is skipped if the "yes"
+// option is set.
+func uploadEntries(uploader client.Uploader, completeEntries worklog.Entries, roundToClosestMinute bool) {
+	commentTemplate, err := getCommentTemplate()
+	cobra.CheckErr(err)
+
+	output := getMessageOutput()
+
+	if !viper.GetBool("yes") && strings.ToLower(utils.Prompt(output, "Continue? [y/n]: ")) != "y" {
+		_, _ = fmt.Fprintln(output, "User interruption. Aborting.")
 		os.Exit(0)
 	}
 
 	// In worst case, the maximum number of errors will match the number of entries
 	uploadErrChan := make(chan error, len(completeEntries))
 
-	fmt.Printf("\nUploading worklog entries:\n\n")
+	_, _ = fmt.Fprintf(output, "\nUploading worklog entries:\n\n")
 	if !viper.GetBool("dry-run") {
 		progressUpdateFrequency := progress.DefaultUpdateFrequency
 		progressWriter := utils.NewProgressWriter(progressUpdateFrequency)
+		progressWriter.SetOutputWriter(output)
 
 		// Intentionally called as a goroutine
 		go progressWriter.Render()
 
 		uploader.UploadEntries(context.Background(), completeEntries, uploadErrChan, &client.UploadOpts{
-			RoundToClosestMinute:   viper.GetBool("round-to-closest-minute"),
+			RoundToClosestMinute:   roundToClosestMinute,
 			TreatDurationAsBilled:  viper.GetBool("force-billed-duration"),
 			CreateMissingResources: false,
 			User:                   viper.GetString("target-user"),
 			ProgressWriter:         progressWriter,
+			CommentTemplate:        commentTemplate,
 		})
 
 		// Wait for at least one tracker to appear and while the rendering is in progress,
 		// wait for the remaining updates to render.
 		time.Sleep(time.Second)
+
+		// Uploaders writing to the standard output are not tracking the
+		// progress, so the rendering would never stop by itself
+		if progressWriter.Length() == 0 {
+			progressWriter.Stop()
+		}
+
 		for progressWriter.IsRenderInProgress() {
 			time.Sleep(progressUpdateFrequency)
 		}
@@ -215,14 +392,14 @@ func runRootCmd(_ *cobra.Command, _ []string) {
 	}
 
 	if errCount := len(uploadErrors); errCount != 0 {
-		fmt.Printf("\nFailed to upload %d worklog entries!\n\n", errCount)
+		_, _ = fmt.Fprintf(output, "\nFailed to upload %d worklog entries!\n\n", errCount)
 		for _, err := range uploadErrors {
-			fmt.Println(err)
+			_, _ = fmt.Fprintln(output, err)
 		}
 		os.Exit(1)
 	}
 
-	fmt.Printf("\nSuccessfully uploaded %d worklog entries!\n", len(completeEntries))
+	_, _ = fmt.Fprintf(output, "\nSuccessfully uploaded %d worklog entries!\n", len(completeEntries))
 }
 
 func Execute(buildVersion string, buildCommit string, buildDate string) {
@@ -0,0 +1,56 @@
+package root
+
+import (
+	"fmt"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client/document"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	fetchCmd = &cobra.Command{
+		Use:   "fetch",
+		Short: "Fetch worklog entries from the source and save them for a later upload.",
+		Long: `
+Fetch the worklog entries from the source and save them to a versioned JSON
+document, without uploading them. The saved document can be reviewed, then
+uploaded later or on another machine using the "upload" command.
+
+The source related, filtering, and reporting flags are used the same way as
+they are used by syncing.`,
+		Example: fmt.Sprintf("  %s fetch --source clockify --start 2021-W42 --save week42.json", program),
+		Run:     runFetchCmd,
+	}
+)
+
+func initFetchFlags() {
+	fetchCmd.Flags().StringP("save", "", "", "set the path of the document the fetched entries are saved to")
+}
+
+func runFetchCmd(_ *cobra.Command, _ []string) {
+	validateCommonFlags()
+	validateSourceFlags()
+
+	path := viper.GetString("save")
+	if path == "" {
+		cobra.CheckErr("save path must be set")
+	}
+
+	start, end := getDateRange()
+	entries := worklog.FilterEntries(fetchSourceEntries(start, end), getFilterOpts())
+
+	// The entries are saved for a later review, so errors are reported only
+	reviewEntries(start, end, entries, false)
+
+	err := document.WriteFile(path, worklog.NewDocument(entries, &worklog.DocumentOpts{
+		Source:               viper.GetString("source"),
+		Start:                start,
+		End:                  end,
+		RoundToClosestMinute: viper.GetBool("round-to-closest-minute"),
+	}))
+	cobra.CheckErr(err)
+
+	fmt.Printf("\nSuccessfully saved %d worklog entries to %s!\n", len(entries), path)
+}
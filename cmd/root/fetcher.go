@@ -1,15 +1,28 @@
 package root
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os/exec"
+	"strconv"
+	"unicode/utf8"
 
 	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/activitywatch"
 	"github.com/gabor-boros/minutes/internal/pkg/client/clockify"
+	"github.com/gabor-boros/minutes/internal/pkg/client/csv"
+	"github.com/gabor-boros/minutes/internal/pkg/client/git"
 	"github.com/gabor-boros/minutes/internal/pkg/client/harvest"
+	"github.com/gabor-boros/minutes/internal/pkg/client/ical"
+	"github.com/gabor-boros/minutes/internal/pkg/client/kimai"
+	"github.com/gabor-boros/minutes/internal/pkg/client/org"
+	"github.com/gabor-boros/minutes/internal/pkg/client/redmine"
 	"github.com/gabor-boros/minutes/internal/pkg/client/tempo"
 	"github.com/gabor-boros/minutes/internal/pkg/client/timewarrior"
 	"github.com/gabor-boros/minutes/internal/pkg/client/toggl"
+	"github.com/gabor-boros/minutes/internal/pkg/client/watson"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
 	"github.com/spf13/viper"
 )
 
@@ -17,10 +30,29 @@ var (
 	ErrNoSourceImplementation = errors.New("no source implementation found")
 )
 
+func getActivityWatchFetcher() (client.Fetcher, error) {
+	rules, err := getActivityWatchRules()
+	if err != nil {
+		return nil, err
+	}
+
+	return activitywatch.NewFetcher(&activitywatch.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("activitywatch"),
+		},
+		BaseURL:  viper.GetString("activitywatch-url"),
+		Hostname: viper.GetString("activitywatch-hostname"),
+		MergeGap: viper.GetDuration("activitywatch-merge-gap"),
+		Rules:    rules,
+	})
+}
+
 func getClockifyFetcher() (client.Fetcher, error) {
 	return clockify.NewFetcher(&clockify.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("clockify"),
 		},
 		TokenAuth: client.TokenAuth{
 			Header: "X-Api-Key",
@@ -31,24 +63,141 @@ func getClockifyFetcher() (client.Fetcher, error) {
 	})
 }
 
+func getCSVFetcher() (client.Fetcher, error) {
+	// The delimiter is validated already
+	delimiter, _ := utf8.DecodeRuneInString(viper.GetString("csv-delimiter"))
+
+	return csv.NewFetcher(&csv.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("csv"),
+		},
+		Path: viper.GetString("csv-path"),
+		Columns: csv.ColumnMapping{
+			Date:     viper.GetString("csv-column-date"),
+			Start:    viper.GetString("csv-column-start"),
+			End:      viper.GetString("csv-column-end"),
+			Duration: viper.GetString("csv-column-duration"),
+			Client:   viper.GetString("csv-column-client"),
+			Project:  viper.GetString("csv-column-project"),
+			Task:     viper.GetString("csv-column-task"),
+			Summary:  viper.GetString("csv-column-summary"),
+			Notes:    viper.GetString("csv-column-notes"),
+			Billable: viper.GetString("csv-column-billable"),
+			Tags:     viper.GetString("csv-column-tags"),
+		},
+		Delimiter:     delimiter,
+		DateFormat:    viper.GetString("csv-date-format"),
+		TimeFormat:    viper.GetString("csv-time-format"),
+		TagsSeparator: viper.GetString("csv-tags-separator"),
+		DayStart:      viper.GetDuration("csv-day-start"),
+	})
+}
+
+func getGitFetcher() (client.Fetcher, error) {
+	return git.NewFetcher(&git.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("git"),
+		},
+		CLIClient: client.CLIClient{
+			Command:            viper.GetString("git-command"),
+			CommandArguments:   viper.GetStringSlice("git-arguments"),
+			CommandCtxExecutor: exec.CommandContext,
+		},
+		Repositories: viper.GetStringSlice("git-repositories"),
+		Client:       viper.GetString("git-client"),
+		SessionGap:   viper.GetDuration("git-session-gap"),
+		LeadIn:       viper.GetDuration("git-lead-in"),
+		TaskRegex:    viper.GetString("git-task-regex"),
+	})
+}
+
 func getHarvestFetcher() (client.Fetcher, error) {
 	return harvest.NewFetcher(&harvest.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("harvest"),
 		},
 		TokenAuth: client.TokenAuth{
 			TokenName: "Bearer",
 			Token:     viper.GetString("harvest-api-key"),
 		},
-		BaseURL: "https://api.harvestapp.com",
-		Account: viper.GetInt("harvest-account"),
+		BaseURL:      "https://api.harvestapp.com",
+		Account:      viper.GetInt("harvest-account"),
+		RoundedHours: viper.GetBool("harvest-rounded-hours"),
+	})
+}
+
+func getICalFetcher() (client.Fetcher, error) {
+	return ical.NewFetcher(&ical.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("ical"),
+		},
+		Path:                 viper.GetString("ical-path"),
+		UnbillableCategory:   viper.GetString("ical-unbillable-category"),
+		ClientCategoryRegex:  viper.GetString("ical-client-category-regex"),
+		ProjectCategoryRegex: viper.GetString("ical-project-category-regex"),
+		Warnings:             getMessageOutput(),
+	})
+}
+
+func getKimaiFetcher() (client.Fetcher, error) {
+	return kimai.NewFetcher(&kimai.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("kimai"),
+		},
+		TokenAuth: client.TokenAuth{
+			Token: viper.GetString("kimai-api-token"),
+		},
+		Username: viper.GetString("kimai-username"),
+		BaseURL:  viper.GetString("kimai-url"),
+	})
+}
+
+func getOrgFetcher() (client.Fetcher, error) {
+	return org.NewFetcher(&org.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("org"),
+		},
+		Paths:           viper.GetStringSlice("org-paths"),
+		ClientProperty:  viper.GetString("org-client-property"),
+		ProjectProperty: viper.GetString("org-project-property"),
+		TaskProperty:    viper.GetString("org-task-property"),
+		UnbillableTag:   viper.GetString("org-unbillable-tag"),
+	})
+}
+
+func getRedmineFetcher() (client.Fetcher, error) {
+	activities, err := getRedmineActivities()
+	if err != nil {
+		return nil, err
+	}
+
+	return redmine.NewFetcher(&redmine.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("redmine"),
+		},
+		TokenAuth: client.TokenAuth{
+			Token: viper.GetString("redmine-api-key"),
+		},
+		BaseURL:         viper.GetString("redmine-url"),
+		Client:          viper.GetString("redmine-client"),
+		DayStart:        viper.GetDuration("redmine-day-start"),
+		Activities:      activities,
+		DefaultActivity: viper.GetInt("redmine-default-activity"),
 	})
 }
 
 func getTempoFetcher() (client.Fetcher, error) {
 	return tempo.NewFetcher(&tempo.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("tempo"),
 		},
 		BasicAuth: client.BasicAuth{
 			Username: viper.GetString("tempo-username"),
@@ -61,13 +210,16 @@ func getTempoFetcher() (client.Fetcher, error) {
 func getTimeWarriorFetcher() (client.Fetcher, error) {
 	return timewarrior.NewFetcher(&timewarrior.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("timewarrior"),
 		},
 		CLIClient: client.CLIClient{
 			Command:            viper.GetString("timewarrior-command"),
 			CommandArguments:   viper.GetStringSlice("timewarrior-arguments"),
 			CommandCtxExecutor: exec.CommandContext,
 		},
+		Backend:         viper.GetString("timewarrior-backend"),
+		DataDir:         viper.GetString("timewarrior-data-dir"),
 		UnbillableTag:   viper.GetString("timewarrior-unbillable-tag"),
 		ClientTagRegex:  viper.GetString("timewarrior-client-tag-regex"),
 		ProjectTagRegex: viper.GetString("timewarrior-project-tag-regex"),
@@ -77,7 +229,8 @@ func getTimeWarriorFetcher() (client.Fetcher, error) {
 func getTogglFetcher() (client.Fetcher, error) {
 	return toggl.NewFetcher(&toggl.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("toggl"),
 		},
 		BasicAuth: client.BasicAuth{
 			Username: viper.GetString("toggl-api-key"),
@@ -88,22 +241,97 @@ func getTogglFetcher() (client.Fetcher, error) {
 	})
 }
 
+func getWatsonFetcher() (client.Fetcher, error) {
+	return watson.NewFetcher(&watson.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: getTimezone("watson"),
+		},
+		Dir:            viper.GetString("watson-dir"),
+		UnbillableTag:  viper.GetString("watson-unbillable-tag"),
+		ClientTagRegex: viper.GetString("watson-client-tag-regex"),
+	})
+}
+
+// fetchEntries fetches the entries page by page if the fetcher supports it, and
+// reports the progress after every page. Otherwise, the entries are fetched at
+// once.
+func fetchEntries(ctx context.Context, fetcher client.Fetcher, opts *client.FetchOpts) (worklog.Entries, error) {
+	streamFetcher, ok := fetcher.(client.StreamFetcher)
+	if !ok {
+		return fetcher.FetchEntries(ctx, opts)
+	}
+
+	var entries worklog.Entries
+
+	for page := range streamFetcher.FetchEntriesStream(ctx, opts) {
+		if page.Err != nil {
+			return nil, page.Err
+		}
+
+		entries = append(entries, page.Entries...)
+
+		pageNumber := strconv.Itoa(page.Page)
+		if page.TotalPages > 0 {
+			pageNumber = fmt.Sprintf("%d/%d", page.Page, page.TotalPages)
+		}
+
+		_, _ = fmt.Fprintf(getMessageOutput(), "Fetched page %s, %d entries so far\n", pageNumber, len(entries))
+	}
+
+	return entries, nil
+}
+
+// getPlannedFetcher wraps the fetcher of the source to fetch the date range in
+// windows if the "fetch-window" option is set.
+func getPlannedFetcher() (client.Fetcher, error) {
+	fetcher, err := getFetcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if viper.GetDuration("fetch-window") == 0 {
+		return fetcher, nil
+	}
+
+	return client.NewPlannedFetcher(fetcher, &client.PlannedFetcherOpts{
+		Window:      viper.GetDuration("fetch-window"),
+		Concurrency: viper.GetInt("fetch-concurrency"),
+	}), nil
+}
+
 func getFetcher() (client.Fetcher, error) {
 
 	var fetcher client.Fetcher
 	var err error
 
 	switch viper.GetString("source") {
+	case "activitywatch":
+		fetcher, err = getActivityWatchFetcher()
 	case "clockify":
 		fetcher, err = getClockifyFetcher()
+	case "csv":
+		fetcher, err = getCSVFetcher()
+	case "git":
+		fetcher, err = getGitFetcher()
 	case "harvest":
 		fetcher, err = getHarvestFetcher()
+	case "ical":
+		fetcher, err = getICalFetcher()
+	case "kimai":
+		fetcher, err = getKimaiFetcher()
+	case "org":
+		fetcher, err = getOrgFetcher()
+	case "redmine":
+		fetcher, err = getRedmineFetcher()
 	case "tempo":
 		fetcher, err = getTempoFetcher()
 	case "timewarrior":
 		fetcher, err = getTimeWarriorFetcher()
 	case "toggl":
 		fetcher, err = getTogglFetcher()
+	case "watson":
+		fetcher, err = getWatsonFetcher()
 	default:
 		fetcher, err = nil, ErrNoSourceImplementation
 	}
@@ -4,103 +4,272 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gabor-boros/minutes/internal/cmd/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/activitywatch"
+	"github.com/gabor-boros/minutes/internal/pkg/client/csv"
+	"github.com/gabor-boros/minutes/internal/pkg/client/git"
+	"github.com/gabor-boros/minutes/internal/pkg/client/org"
+	"github.com/gabor-boros/minutes/internal/pkg/client/redmine"
+	"github.com/gabor-boros/minutes/internal/pkg/client/timewarrior"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	sources = []string{"clockify", "harvest", "tempo", "timewarrior", "toggl"}
-	targets = []string{"tempo"}
+	sources = []string{"activitywatch", "clockify", "csv", "git", "harvest", "ical", "kimai", "org", "redmine", "tempo", "timewarrior", "toggl", "watson"}
+	targets = []string{"csv", "ical", "json", "kimai", "redmine", "tempo"}
+
+	filterFlags = []string{
+		"filter-client",
+		"filter-project",
+		"filter-task",
+		"filter-summary",
+		"filter-tag",
+		"exclude-client",
+		"exclude-project",
+		"exclude-task",
+		"exclude-summary",
+		"exclude-tag",
+	}
+
+	validationRuleNames = []string{
+		worklog.RuleMaxDailyDuration,
+		worklog.RuleMinEntryDuration,
+		worklog.RuleMaxWeeklyClientBillable,
+		worklog.RuleNoWeekends,
+	}
 )
 
 func initCommonFlags() {
 	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", fmt.Sprintf("config file (default is $HOME/.%s.yaml)", program))
 
-	rootCmd.Flags().StringP("start", "", "", "set the start date (defaults to 00:00:00)")
-	rootCmd.Flags().StringP("end", "", "", "set the end date (defaults to now)")
-	rootCmd.Flags().StringP("date-format", "", defaultDateFormat, "set start and end date format (in Go style)")
+	rootCmd.PersistentFlags().StringP("start", "", "", fmt.Sprintf("set the start date or date range %v, -<n>d, -<n>w or ISO week like 2021-W41 (defaults to 00:00:00)", utils.DateRanges))
+	rootCmd.PersistentFlags().StringP("end", "", "", "set the end date or date range (defaults to now)")
+	rootCmd.PersistentFlags().StringP("date-format", "", defaultDateFormat, "set start and end date format (in Go style)")
+	rootCmd.PersistentFlags().StringP("week-start", "", "monday", "set the first day of the week used by week based date ranges")
+	rootCmd.PersistentFlags().StringP("timezone", "", "", "set the timezone, like Europe/Berlin (defaults to the local timezone)")
+
+	rootCmd.PersistentFlags().DurationP("fetch-window", "", 0, "split the date range into windows fetched separately, like 168h (0 disables)")
+	rootCmd.PersistentFlags().IntP("fetch-concurrency", "", client.DefaultFetchConcurrency, "set the maximum number of windows fetched at the same time")
+	rootCmd.PersistentFlags().StringP("running-entries", "", client.RunningEntriesSkip, fmt.Sprintf("set how running entries are handled %v", client.RunningEntriesPolicies))
+
+	rootCmd.PersistentFlags().StringP("source-user", "", "", "set the source user ID")
+	rootCmd.PersistentFlags().StringP("source", "s", "", fmt.Sprintf("set the source of the sync %v", sources))
+
+	rootCmd.PersistentFlags().StringP("target-user", "", "", "set the source user ID")
+	rootCmd.PersistentFlags().StringP("target", "t", "", fmt.Sprintf("set the target of the sync %v", targets))
+
+	rootCmd.PersistentFlags().StringSliceP("table-sort-by", "", []string{utils.ColumnStart, utils.ColumnProject, utils.ColumnTask, utils.ColumnSummary}, fmt.Sprintf("sort table by column %v", utils.Columns))
+	rootCmd.PersistentFlags().StringSliceP("table-hide-column", "", []string{}, fmt.Sprintf("hide table column %v", utils.HideableColumns))
+
+	rootCmd.PersistentFlags().StringP("tags-as-tasks-regex", "", "", "regex of the task pattern")
+
+	rootCmd.PersistentFlags().BoolP("round-to-closest-minute", "", false, "round time to closest minute")
+	rootCmd.PersistentFlags().BoolP("force-billed-duration", "", false, "treat every second spent as billed")
+
+	rootCmd.PersistentFlags().StringP("filter-client", "", "", "filter for client name after fetching")
+	rootCmd.PersistentFlags().StringP("filter-project", "", "", "filter for project name after fetching")
+	rootCmd.PersistentFlags().StringP("filter-task", "", "", "filter for task name after fetching")
+	rootCmd.PersistentFlags().StringP("filter-summary", "", "", "filter for summary after fetching")
+	rootCmd.PersistentFlags().StringP("filter-tag", "", "", "filter for entries having a matching tag after fetching")
+	rootCmd.PersistentFlags().StringP("exclude-client", "", "", "exclude client name after fetching")
+	rootCmd.PersistentFlags().StringP("exclude-project", "", "", "exclude project name after fetching")
+	rootCmd.PersistentFlags().StringP("exclude-task", "", "", "exclude task name after fetching")
+	rootCmd.PersistentFlags().StringP("exclude-summary", "", "", "exclude summary after fetching")
+	rootCmd.PersistentFlags().StringP("exclude-tag", "", "", "exclude entries having a matching tag after fetching")
+	rootCmd.PersistentFlags().BoolP("billable-only", "", false, "keep only the entries having billable time")
+	rootCmd.PersistentFlags().BoolP("unbillable-only", "", false, "keep only the entries having unbillable time")
+
+	rootCmd.PersistentFlags().BoolP("allow-overlaps", "", false, "allow uploading overlapping entries")
+	rootCmd.PersistentFlags().StringP("workday-start", "", "09:00", "set the start of working hours used for idle gap detection")
+	rootCmd.PersistentFlags().StringP("workday-end", "", "17:00", "set the end of working hours used for idle gap detection")
+	rootCmd.PersistentFlags().DurationP("idle-gap-threshold", "", 0, "report idle gaps within working hours longer than the threshold (0 disables)")
+
+	rootCmd.PersistentFlags().DurationP("max-daily-duration", "", 0, "report days exceeding the duration (0 disables)")
+	rootCmd.PersistentFlags().DurationP("min-entry-duration", "", 0, "drop and report entries shorter than the duration (0 disables)")
+	rootCmd.PersistentFlags().DurationP("max-weekly-client-billable", "", 0, "report clients exceeding the weekly billable duration (0 disables)")
+	rootCmd.PersistentFlags().BoolP("no-weekends", "", false, "report entries logged on weekends")
+	rootCmd.PersistentFlags().BoolP("ignore-validation-errors", "", false, "sync entries even if validation errors were reported")
+
+	rootCmd.PersistentFlags().BoolP("dry-run", "", false, "fetch entries, but do not sync them")
+	rootCmd.PersistentFlags().BoolP("yes", "y", false, "sync entries without asking for confirmation")
+	rootCmd.PersistentFlags().BoolP("version", "", false, "show command version")
+}
 
-	rootCmd.Flags().StringP("source-user", "", "", "set the source user ID")
-	rootCmd.Flags().StringP("source", "s", "", fmt.Sprintf("set the source of the sync %v", sources))
+func initActivityWatchFlags() {
+	rootCmd.PersistentFlags().StringP("activitywatch-url", "", activitywatch.DefaultURL, "set the base URL of the aw-server")
+	rootCmd.PersistentFlags().StringP("activitywatch-hostname", "", "", "set the hostname of the watched machine (defaults to the hostname of this machine)")
+	rootCmd.PersistentFlags().DurationP("activitywatch-merge-gap", "", activitywatch.DefaultMergeGap, "set the maximum gap between the activities of a session")
+	rootCmd.PersistentFlags().StringP("activitywatch-timezone", "", "", "set the timezone of the entries (defaults to timezone)")
+}
 
-	rootCmd.Flags().StringP("target-user", "", "", "set the source user ID")
-	rootCmd.Flags().StringP("target", "t", "", fmt.Sprintf("set the target of the sync %v", targets))
+func initClockifyFlags() {
+	rootCmd.PersistentFlags().StringP("clockify-url", "", "https://api.clockify.me", "set the base URL")
+	rootCmd.PersistentFlags().StringP("clockify-api-key", "", "", "set the API key")
+	rootCmd.PersistentFlags().StringP("clockify-workspace", "", "", "set the workspace ID")
+	rootCmd.PersistentFlags().StringP("clockify-timezone", "", "", "set the timezone of the API (defaults to timezone)")
+}
 
-	rootCmd.Flags().StringSliceP("table-sort-by", "", []string{utils.ColumnStart, utils.ColumnProject, utils.ColumnTask, utils.ColumnSummary}, fmt.Sprintf("sort table by column %v", utils.Columns))
-	rootCmd.Flags().StringSliceP("table-hide-column", "", []string{}, fmt.Sprintf("hide table column %v", utils.HideableColumns))
+func initCSVFlags() {
+	columns := csv.DefaultColumnMapping()
+
+	rootCmd.PersistentFlags().StringP("csv-path", "", "", "set the path of the CSV file (\"-\" reads the standard input)")
+	rootCmd.PersistentFlags().StringP("csv-delimiter", "", ",", "set the column delimiter")
+	rootCmd.PersistentFlags().StringP("csv-date-format", "", csv.DefaultDateFormat, "set the date column format (in Go style)")
+	rootCmd.PersistentFlags().StringP("csv-time-format", "", csv.DefaultTimeFormat, "set the start and end column format (in Go style)")
+	rootCmd.PersistentFlags().StringP("csv-tags-separator", "", csv.DefaultTagsSeparator, "set the separator of tags")
+	rootCmd.PersistentFlags().DurationP("csv-day-start", "", csv.DefaultDayStart, "set the start of the first row of a day, if the rows have no start column")
+
+	rootCmd.PersistentFlags().StringP("csv-column-date", "", columns.Date, "set the name of the date column")
+	rootCmd.PersistentFlags().StringP("csv-column-start", "", columns.Start, "set the name of the start column")
+	rootCmd.PersistentFlags().StringP("csv-column-end", "", columns.End, "set the name of the end column")
+	rootCmd.PersistentFlags().StringP("csv-column-duration", "", columns.Duration, "set the name of the duration column")
+	rootCmd.PersistentFlags().StringP("csv-column-client", "", columns.Client, "set the name of the client column")
+	rootCmd.PersistentFlags().StringP("csv-column-project", "", columns.Project, "set the name of the project column")
+	rootCmd.PersistentFlags().StringP("csv-column-task", "", columns.Task, "set the name of the task column")
+	rootCmd.PersistentFlags().StringP("csv-column-summary", "", columns.Summary, "set the name of the summary column")
+	rootCmd.PersistentFlags().StringP("csv-column-notes", "", columns.Notes, "set the name of the notes column")
+	rootCmd.PersistentFlags().StringP("csv-column-billable", "", columns.Billable, "set the name of the billable column")
+	rootCmd.PersistentFlags().StringP("csv-column-tags", "", columns.Tags, "set the name of the tags column")
+	rootCmd.PersistentFlags().StringP("csv-target-path", "", "", "set the path of the written CSV file (\"-\" writes the standard output)")
+	rootCmd.PersistentFlags().StringP("csv-target-delimiter", "", ",", "set the column delimiter of the written file")
+	rootCmd.PersistentFlags().StringP("csv-target-tags-separator", "", csv.DefaultTagsSeparator, "set the separator of tags in the written file")
+	rootCmd.PersistentFlags().StringP("csv-target-timezone", "", "", "set the timezone of the written file (defaults to timezone)")
+	rootCmd.PersistentFlags().BoolP("csv-append", "", false, "append the entries to the file instead of overwriting it")
+	rootCmd.PersistentFlags().BoolP("csv-rotate-monthly", "", false, "write the entries to a file per month")
+	rootCmd.PersistentFlags().StringP("csv-comment-template", "", client.DefaultCommentTemplate, "set the template of the comment column")
+	rootCmd.PersistentFlags().StringP("csv-timezone", "", "", "set the timezone of the file (defaults to timezone)")
+}
 
-	rootCmd.Flags().StringP("tags-as-tasks-regex", "", "", "regex of the task pattern")
+func initGitFlags() {
+	rootCmd.PersistentFlags().StringP("git-command", "", "git", "set the executable name")
+	rootCmd.PersistentFlags().StringSliceP("git-arguments", "", []string{}, "set additional arguments of the log command")
+	rootCmd.PersistentFlags().StringSliceP("git-repositories", "", []string{}, "set the paths of the repositories")
+	rootCmd.PersistentFlags().StringP("git-client", "", "", "set the client of the entries")
+	rootCmd.PersistentFlags().DurationP("git-session-gap", "", git.DefaultSessionGap, "set the maximum gap between the commits of a session")
+	rootCmd.PersistentFlags().DurationP("git-lead-in", "", git.DefaultLeadIn, "set the time spent before the first commit of a session")
+	rootCmd.PersistentFlags().StringP("git-task-regex", "", git.DefaultTaskRegex, "regex of task keys in branch names and commit messages")
+	rootCmd.PersistentFlags().StringP("git-timezone", "", "", "set the timezone of the entries (defaults to timezone)")
+}
 
-	rootCmd.Flags().BoolP("round-to-closest-minute", "", false, "round time to closest minute")
-	rootCmd.Flags().BoolP("force-billed-duration", "", false, "treat every second spent as billed")
+func initHarvestFlags() {
+	rootCmd.PersistentFlags().StringP("harvest-api-key", "", "", "set the API key")
+	rootCmd.PersistentFlags().IntP("harvest-account", "", 0, "set the Account ID")
+	rootCmd.PersistentFlags().BoolP("harvest-rounded-hours", "", false, "use the hours rounded by Harvest")
+	rootCmd.PersistentFlags().StringP("harvest-timezone", "", "", "set the timezone of the API (defaults to timezone)")
+}
 
-	rootCmd.Flags().StringP("filter-client", "", "", "filter for client name after fetching")
-	rootCmd.Flags().StringP("filter-project", "", "", "filter for project name after fetching")
+func initICalFlags() {
+	rootCmd.PersistentFlags().StringP("ical-path", "", "", "set the path of the calendar file or a directory of calendar files")
+	rootCmd.PersistentFlags().StringP("ical-target-path", "", "", "set the path of the written calendar file (\"-\" writes the standard output)")
+	rootCmd.PersistentFlags().StringP("ical-target-timezone", "", "", "set the timezone used to determine the month of the written entries (defaults to timezone)")
+	rootCmd.PersistentFlags().StringP("ical-unbillable-category", "", "unbillable", "set the unbillable category")
+	rootCmd.PersistentFlags().StringP("ical-client-category-regex", "", "", "regex of client category pattern")
+	rootCmd.PersistentFlags().StringP("ical-project-category-regex", "", "", "regex of project category pattern")
+	rootCmd.PersistentFlags().StringP("ical-calendar-name", "", "", "set the name of the written calendar")
+	rootCmd.PersistentFlags().BoolP("ical-append", "", false, "merge the entries with the events of the file instead of overwriting it")
+	rootCmd.PersistentFlags().BoolP("ical-rotate-monthly", "", false, "write the entries to a file per month")
+	rootCmd.PersistentFlags().StringP("ical-comment-template", "", client.DefaultCommentTemplate, "set the template of the event description")
+	rootCmd.PersistentFlags().StringP("ical-timezone", "", "", "set the timezone of the dates without timezone (defaults to timezone)")
+}
 
-	rootCmd.Flags().BoolP("dry-run", "", false, "fetch entries, but do not sync them")
-	rootCmd.Flags().BoolP("version", "", false, "show command version")
+func initJSONFlags() {
+	rootCmd.PersistentFlags().StringP("json-path", "", "", "set the path of the JSON file (\"-\" writes the standard output)")
+	rootCmd.PersistentFlags().BoolP("json-append", "", false, "append the entries to the file instead of overwriting it")
+	rootCmd.PersistentFlags().BoolP("json-rotate-monthly", "", false, "write the entries to a file per month")
+	rootCmd.PersistentFlags().StringP("json-comment-template", "", client.DefaultCommentTemplate, "set the template of the comment field")
+	rootCmd.PersistentFlags().StringP("json-timezone", "", "", "set the timezone of the file (defaults to timezone)")
 }
 
-func initClockifyFlags() {
-	rootCmd.Flags().StringP("clockify-url", "", "https://api.clockify.me", "set the base URL")
-	rootCmd.Flags().StringP("clockify-api-key", "", "", "set the API key")
-	rootCmd.Flags().StringP("clockify-workspace", "", "", "set the workspace ID")
+func initKimaiFlags() {
+	rootCmd.PersistentFlags().StringP("kimai-url", "", "", "set the base URL")
+	rootCmd.PersistentFlags().StringP("kimai-username", "", "", "set the username of the API token (uses the API token as bearer token if not set)")
+	rootCmd.PersistentFlags().StringP("kimai-api-token", "", "", "set the API token")
+	rootCmd.PersistentFlags().StringP("kimai-comment-template", "", client.DefaultCommentTemplate, "set the template of the timesheet description")
+	rootCmd.PersistentFlags().StringP("kimai-timezone", "", "", "set the timezone of the user (defaults to timezone)")
 }
 
-func initHarvestFlags() {
-	rootCmd.Flags().StringP("harvest-api-key", "", "", "set the API key")
-	rootCmd.Flags().IntP("harvest-account", "", 0, "set the Account ID")
+func initOrgFlags() {
+	rootCmd.PersistentFlags().StringSliceP("org-paths", "", []string{}, "set the paths or glob patterns of the Org files")
+	rootCmd.PersistentFlags().StringP("org-client-property", "", org.DefaultClientProperty, "set the property of the client name")
+	rootCmd.PersistentFlags().StringP("org-project-property", "", org.DefaultProjectProperty, "set the property of the project name")
+	rootCmd.PersistentFlags().StringP("org-task-property", "", org.DefaultTaskProperty, "set the property of the task name")
+	rootCmd.PersistentFlags().StringP("org-unbillable-tag", "", "unbillable", "set the unbillable tag")
+	rootCmd.PersistentFlags().StringP("org-timezone", "", "", "set the timezone of the clock timestamps (defaults to timezone)")
+}
+
+func initRedmineFlags() {
+	rootCmd.PersistentFlags().StringP("redmine-url", "", "", "set the base URL")
+	rootCmd.PersistentFlags().StringP("redmine-api-key", "", "", "set the API key")
+	rootCmd.PersistentFlags().StringP("redmine-client", "", "", "set the client of the fetched entries (defaults to the project)")
+	rootCmd.PersistentFlags().StringToIntP("redmine-activities", "", map[string]int{}, "set the activity IDs by tag or task name, like Development=9")
+	rootCmd.PersistentFlags().IntP("redmine-default-activity", "", 0, "set the activity ID used if no activity matches (0 uses the default activity of Redmine)")
+	rootCmd.PersistentFlags().DurationP("redmine-day-start", "", redmine.DefaultDayStart, "set the start of the first entry of a day, since time entries have no start time")
+	rootCmd.PersistentFlags().StringP("redmine-comment-template", "", client.DefaultCommentTemplate, "set the template of the time entry comments")
+	rootCmd.PersistentFlags().StringP("redmine-timezone", "", "", "set the timezone of the user (defaults to timezone)")
 }
 
 func initTempoFlags() {
-	rootCmd.Flags().StringP("tempo-url", "", "", "set the base URL")
-	rootCmd.Flags().StringP("tempo-username", "", "", "set the login user ID")
-	rootCmd.Flags().StringP("tempo-password", "", "", "set the login password")
+	rootCmd.PersistentFlags().StringP("tempo-url", "", "", "set the base URL")
+	rootCmd.PersistentFlags().StringP("tempo-username", "", "", "set the login user ID")
+	rootCmd.PersistentFlags().StringP("tempo-password", "", "", "set the login password")
+	rootCmd.PersistentFlags().StringP("tempo-comment-template", "", client.DefaultCommentTemplate, "set the template of the worklog comment")
+	rootCmd.PersistentFlags().StringP("tempo-timezone", "", "", "set the timezone of the API (defaults to timezone)")
 }
 
 func initTimewarriorFlags() {
-	rootCmd.Flags().StringP("timewarrior-command", "", "timew", "set the executable name")
-	rootCmd.Flags().StringSliceP("timewarrior-arguments", "", []string{}, "set additional arguments")
-
-	rootCmd.Flags().StringP("timewarrior-unbillable-tag", "", "unbillable", "set the unbillable tag")
-	rootCmd.Flags().StringP("timewarrior-client-tag-regex", "", "", "regex of client tag pattern")
-	rootCmd.Flags().StringP("timewarrior-project-tag-regex", "", "", "regex of project tag pattern")
+	rootCmd.PersistentFlags().StringP("timewarrior-command", "", "timew", "set the executable name")
+	rootCmd.PersistentFlags().StringSliceP("timewarrior-arguments", "", []string{}, "set additional arguments")
+	rootCmd.PersistentFlags().StringP("timewarrior-backend", "", timewarrior.BackendCLI, "set how entries are read (\"cli\" or \"data\")")
+	rootCmd.PersistentFlags().StringP("timewarrior-data-dir", "", "", "set the directory of data files (defaults to $TIMEWARRIORDB/data or ~/.timewarrior/data)")
+
+	rootCmd.PersistentFlags().StringP("timewarrior-unbillable-tag", "", "unbillable", "set the unbillable tag")
+	rootCmd.PersistentFlags().StringP("timewarrior-client-tag-regex", "", "", "regex of client tag pattern")
+	rootCmd.PersistentFlags().StringP("timewarrior-project-tag-regex", "", "", "regex of project tag pattern")
+	rootCmd.PersistentFlags().StringP("timewarrior-timezone", "", "", "set the timezone of the CLI (defaults to timezone)")
 }
 
 func initTogglFlags() {
-	rootCmd.Flags().StringP("toggl-api-key", "", "", "set the API key")
-	rootCmd.Flags().IntP("toggl-workspace", "", 0, "set the workspace ID")
+	rootCmd.PersistentFlags().StringP("toggl-api-key", "", "", "set the API key")
+	rootCmd.PersistentFlags().IntP("toggl-workspace", "", 0, "set the workspace ID")
+	rootCmd.PersistentFlags().StringP("toggl-timezone", "", "", "set the timezone of the API (defaults to timezone)")
 }
 
-func validateFlags() {
-	var err error
-	source := viper.GetString("source")
-	target := viper.GetString("target")
-
-	if source == "" {
-		cobra.CheckErr("sync source must be set")
-	}
+func initWatsonFlags() {
+	rootCmd.PersistentFlags().StringP("watson-dir", "", "", "set the directory of Watson files (defaults to $WATSON_DIR or ~/.config/watson)")
+	rootCmd.PersistentFlags().StringP("watson-unbillable-tag", "", "unbillable", "set the unbillable tag")
+	rootCmd.PersistentFlags().StringP("watson-client-tag-regex", "", "", "regex of client tag pattern")
+	rootCmd.PersistentFlags().StringP("watson-timezone", "", "", "set the timezone of the entries (defaults to timezone)")
+}
 
-	if target == "" {
-		cobra.CheckErr("sync target must be set")
-	}
+// validateFlags validates the flags used by syncing, having both source and
+// target set.
+func validateFlags() {
+	validateCommonFlags()
+	validateSourceFlags()
+	validateTargetFlags()
 
-	if source == target {
+	if viper.GetString("source") == viper.GetString("target") {
 		cobra.CheckErr("sync source cannot match the target")
 	}
 
-	if !utils.IsSliceContains(source, sources) {
-		cobra.CheckErr(fmt.Sprintf("\"%s\" is not part of the supported sources %v\n", source, sources))
+	// The confirmation would be read from the already consumed standard input
+	if viper.GetString("source") == "csv" && viper.GetString("csv-path") == csv.PathStdin && !viper.GetBool("yes") {
+		cobra.CheckErr("yes must be set when reading the csv file from the standard input")
 	}
+}
 
-	if !utils.IsSliceContains(target, targets) {
-		cobra.CheckErr(fmt.Sprintf("\"%s\" is not part of the supported targets %v\n", target, targets))
-	}
+// validateCommonFlags validates the flags used regardless of the source and
+// target.
+func validateCommonFlags() {
+	var err error
 
-	tagsAsTasksRegex := viper.GetString("tags-as-tasks-regex")
-	_, err = regexp.Compile(tagsAsTasksRegex)
+	_, err = time.LoadLocation(viper.GetString("timezone"))
 	cobra.CheckErr(err)
 
 	for _, sortBy := range viper.GetStringSlice("table-sort-by") {
@@ -121,15 +290,152 @@ func validateFlags() {
 		}
 	}
 
-	_, err = regexp.Compile(viper.GetString("filter-client"))
+	for _, filter := range filterFlags {
+		_, err = regexp.Compile(viper.GetString(filter))
+		cobra.CheckErr(err)
+	}
+
+	if viper.GetBool("billable-only") && viper.GetBool("unbillable-only") {
+		cobra.CheckErr("billable-only and unbillable-only cannot be set at the same time")
+	}
+
+	_, err = utils.ParseWeekday(viper.GetString("week-start"))
+	cobra.CheckErr(err)
+
+	workdayStart, err := utils.ParseTimeOfDay(viper.GetString("workday-start"))
 	cobra.CheckErr(err)
 
-	_, err = regexp.Compile(viper.GetString("filter-project"))
+	workdayEnd, err := utils.ParseTimeOfDay(viper.GetString("workday-end"))
 	cobra.CheckErr(err)
 
+	if workdayEnd <= workdayStart {
+		cobra.CheckErr("workday end must be after workday start")
+	}
+
+	validationSeverities := map[string]string{}
+	cobra.CheckErr(viper.UnmarshalKey("validation-severities", &validationSeverities))
+
+	for rule, severity := range validationSeverities {
+		if !utils.IsSliceContains(rule, validationRuleNames) {
+			cobra.CheckErr(fmt.Sprintf("\"%s\" is not part of the validation rules %v\n", rule, validationRuleNames))
+		}
+
+		_, err = worklog.ParseSeverity(severity)
+		cobra.CheckErr(err)
+	}
+}
+
+// validateSourceFlags validates the flags used by fetching.
+func validateSourceFlags() {
+	var err error
+	source := viper.GetString("source")
+
+	if source == "" {
+		cobra.CheckErr("sync source must be set")
+	}
+
+	if !utils.IsSliceContains(source, sources) {
+		cobra.CheckErr(fmt.Sprintf("\"%s\" is not part of the supported sources %v\n", source, sources))
+	}
+
+	_, err = time.LoadLocation(viper.GetString(source + "-timezone"))
+	cobra.CheckErr(err)
+
+	tagsAsTasksRegex := viper.GetString("tags-as-tasks-regex")
+	_, err = regexp.Compile(tagsAsTasksRegex)
+	cobra.CheckErr(err)
+
+	if viper.GetDuration("fetch-window") < 0 {
+		cobra.CheckErr("fetch-window cannot be negative")
+	}
+
+	if viper.GetInt("fetch-concurrency") <= 0 {
+		cobra.CheckErr("fetch-concurrency must be positive")
+	}
+
+	if runningEntries := viper.GetString("running-entries"); !utils.IsSliceContains(runningEntries, client.RunningEntriesPolicies) {
+		cobra.CheckErr(fmt.Sprintf("\"%s\" is not part of the running entries policies %v\n", runningEntries, client.RunningEntriesPolicies))
+	}
+
 	switch source {
+	case "activitywatch":
+		if viper.GetString("activitywatch-url") == "" {
+			cobra.CheckErr("activitywatch url must be set")
+		}
+
+		if viper.GetDuration("activitywatch-merge-gap") < 0 {
+			cobra.CheckErr("activitywatch merge gap cannot be negative")
+		}
+
+		rules, err := getActivityWatchRules()
+		cobra.CheckErr(err)
+
+		if len(rules) == 0 {
+			cobra.CheckErr("activitywatch rules must be set")
+		}
+
+		for _, rule := range rules {
+			if !utils.IsSliceContains(rule.Field, activitywatch.RuleFields) {
+				cobra.CheckErr(fmt.Sprintf("\"%s\" is not part of the supported activitywatch rule fields %v\n", rule.Field, activitywatch.RuleFields))
+			}
+
+			_, err = regexp.Compile(rule.Regex)
+			cobra.CheckErr(err)
+		}
+	case "csv":
+		validateCSVFlags()
+
+		if viper.GetDuration("csv-day-start") < 0 || viper.GetDuration("csv-day-start") >= 24*time.Hour {
+			cobra.CheckErr("csv day start must be between 0 and 24h")
+		}
+	case "git":
+		if viper.GetString("git-command") == "" {
+			cobra.CheckErr("git command must be set")
+		}
+
+		if len(viper.GetStringSlice("git-repositories")) == 0 {
+			cobra.CheckErr("git repositories must be set")
+		}
+
+		if viper.GetDuration("git-session-gap") <= 0 {
+			cobra.CheckErr("git session gap must be positive")
+		}
+
+		if viper.GetDuration("git-lead-in") < 0 {
+			cobra.CheckErr("git lead-in cannot be negative")
+		}
+
+		_, err = regexp.Compile(viper.GetString("git-task-regex"))
+		cobra.CheckErr(err)
+	case "ical":
+		if viper.GetString("ical-path") == "" {
+			cobra.CheckErr("ical path must be set")
+		}
+
+		_, err = regexp.Compile(viper.GetString("ical-client-category-regex"))
+		cobra.CheckErr(err)
+
+		_, err = regexp.Compile(viper.GetString("ical-project-category-regex"))
+		cobra.CheckErr(err)
+	case "kimai":
+		validateKimaiFlags()
+	case "org":
+		if len(viper.GetStringSlice("org-paths")) == 0 {
+			cobra.CheckErr("org paths must be set")
+		}
+	case "redmine":
+		validateRedmineFlags()
+
+		if viper.GetDuration("redmine-day-start") < 0 || viper.GetDuration("redmine-day-start") >= 24*time.Hour {
+			cobra.CheckErr("redmine day start must be between 0 and 24h")
+		}
 	case "timewarrior":
-		if viper.GetString("timewarrior-command") == "" {
+		backend := viper.GetString("timewarrior-backend")
+		if backend != timewarrior.BackendCLI && backend != timewarrior.BackendData {
+			cobra.CheckErr(fmt.Sprintf("\"%s\" is not part of the supported timewarrior backends %v\n", backend, []string{timewarrior.BackendCLI, timewarrior.BackendData}))
+		}
+
+		if backend == timewarrior.BackendCLI && viper.GetString("timewarrior-command") == "" {
 			cobra.CheckErr("timewarrior command must be set")
 		}
 
@@ -144,5 +450,95 @@ func validateFlags() {
 		if viper.GetString("timewarrior-project-tag-regex") == "" {
 			cobra.CheckErr("timewarrior project tag regex must be set")
 		}
+	case "watson":
+		if viper.GetString("watson-unbillable-tag") == "" {
+			cobra.CheckErr("watson unbillable tag must be set")
+		}
+
+		_, err = regexp.Compile(viper.GetString("watson-client-tag-regex"))
+		cobra.CheckErr(err)
+	}
+}
+
+// validateTargetFlags validates the flags used by uploading.
+func validateTargetFlags() {
+	var err error
+	target := viper.GetString("target")
+
+	if target == "" {
+		cobra.CheckErr("sync target must be set")
+	}
+
+	if !utils.IsSliceContains(target, targets) {
+		cobra.CheckErr(fmt.Sprintf("\"%s\" is not part of the supported targets %v\n", target, targets))
+	}
+
+	_, err = getCommentTemplate()
+	cobra.CheckErr(err)
+
+	_, err = time.LoadLocation(viper.GetString(getTargetOption(target, "timezone")))
+	cobra.CheckErr(err)
+
+	switch target {
+	case "csv":
+		validateFileTargetFlags("csv")
+
+		if utf8.RuneCountInString(viper.GetString("csv-target-delimiter")) != 1 {
+			cobra.CheckErr("csv target delimiter must be a single character")
+		}
+	case "ical":
+		validateFileTargetFlags("ical")
+	case "json":
+		validateFileTargetFlags("json")
+	case "kimai":
+		validateKimaiFlags()
+	case "redmine":
+		validateRedmineFlags()
+	}
+}
+
+func validateCSVFlags() {
+	if viper.GetString("csv-path") == "" {
+		cobra.CheckErr("csv path must be set")
+	}
+
+	if utf8.RuneCountInString(viper.GetString("csv-delimiter")) != 1 {
+		cobra.CheckErr("csv delimiter must be a single character")
+	}
+}
+
+func validateKimaiFlags() {
+	if viper.GetString("kimai-url") == "" {
+		cobra.CheckErr("kimai url must be set")
+	}
+
+	if viper.GetString("kimai-api-token") == "" {
+		cobra.CheckErr("kimai api token must be set")
+	}
+}
+
+func validateRedmineFlags() {
+	if viper.GetString("redmine-url") == "" {
+		cobra.CheckErr("redmine url must be set")
+	}
+
+	if viper.GetString("redmine-api-key") == "" {
+		cobra.CheckErr("redmine api key must be set")
+	}
+
+	_, err := getRedmineActivities()
+	cobra.CheckErr(err)
+}
+
+// validateFileTargetFlags validates the flags of targets writing files.
+func validateFileTargetFlags(target string) {
+	path := viper.GetString(getTargetOption(target, "path"))
+
+	if path == "" {
+		cobra.CheckErr(fmt.Sprintf("%s path must be set", target))
+	}
+
+	if path == client.PathStdout && viper.GetBool(target+"-rotate-monthly") {
+		cobra.CheckErr(fmt.Sprintf("%s rotate monthly cannot be set when writing the standard output", target))
 	}
 }
@@ -0,0 +1,75 @@
+package root
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/document"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	uploadCmd = &cobra.Command{
+		Use:   "upload",
+		Short: "Upload worklog entries saved by the fetch command to the target.",
+		Long: `
+Upload the worklog entries saved by the "fetch" command to the target. The date
+range and the source of the entries are read from the saved document, hence
+the source related flags are not used.
+
+The target related, filtering, validation, and reporting flags are used the
+same way as they are used by syncing.`,
+		Example: fmt.Sprintf("  %s upload --target tempo --from week42.json", program),
+		Run:     runUploadCmd,
+	}
+)
+
+func initUploadFlags() {
+	uploadCmd.Flags().StringP("from", "", "", "set the path of the document saved by the fetch command")
+}
+
+func runUploadCmd(cmd *cobra.Command, _ []string) {
+	validateCommonFlags()
+	validateTargetFlags()
+
+	path := viper.GetString("from")
+	if path == "" {
+		cobra.CheckErr("from path must be set")
+	}
+
+	uploader, err := getUploader()
+	cobra.CheckErr(err)
+
+	savedDocument, err := document.ReadFile(path)
+	cobra.CheckErr(err)
+
+	fetcher, err := document.NewFetcher(&document.ClientOpts{
+		Document: savedDocument,
+	})
+	cobra.CheckErr(err)
+
+	entries, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: savedDocument.Start,
+		End:   savedDocument.End,
+	})
+	cobra.CheckErr(err)
+
+	_, _ = fmt.Fprintf(
+		getMessageOutput(),
+		"Loaded %d worklog entries fetched from %s at %s\n\n",
+		len(entries),
+		savedDocument.Source,
+		savedDocument.CreatedAt.In(getLocation()).Format(defaultDateFormat),
+	)
+
+	completeEntries := reviewEntries(savedDocument.Start, savedDocument.End, entries, !viper.GetBool("dry-run"))
+	// Rounding requested when fetching is applied, since the document holds
+	// the raw durations, unless the flag is set explicitly
+	roundToClosestMinute := viper.GetBool("round-to-closest-minute") || savedDocument.RoundToClosestMinute
+	if cmd.Flags().Changed("round-to-closest-minute") {
+		roundToClosestMinute = viper.GetBool("round-to-closest-minute")
+	}
+	uploadEntries(uploader, completeEntries, roundToClosestMinute)
+}
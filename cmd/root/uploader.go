@@ -2,8 +2,16 @@ package root
 
 import (
 	"errors"
+	"fmt"
+	"text/template"
+	"unicode/utf8"
 
 	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/csv"
+	"github.com/gabor-boros/minutes/internal/pkg/client/ical"
+	"github.com/gabor-boros/minutes/internal/pkg/client/json"
+	"github.com/gabor-boros/minutes/internal/pkg/client/kimai"
+	"github.com/gabor-boros/minutes/internal/pkg/client/redmine"
 	"github.com/gabor-boros/minutes/internal/pkg/client/tempo"
 	"github.com/spf13/viper"
 )
@@ -12,12 +20,112 @@ var (
 	ErrNoTargetImplementation = errors.New("no target implementation found")
 )
 
+// getCommentTemplate returns the comment template of the target. Every target
+// can have its own template set by the "<target>-comment-template" option.
+func getCommentTemplate() (*template.Template, error) {
+	return client.NewCommentTemplate(viper.GetString(fmt.Sprintf("%s-comment-template", viper.GetString("target"))))
+}
+
+// getTargetOption returns the name of the target option. The CSV and iCalendar
+// files are both read and written, so the options of the written file are
+// prefixed by "<target>-target", to not read and write the same file.
+func getTargetOption(target string, option string) string {
+	switch target {
+	case "csv", "ical":
+		return fmt.Sprintf("%s-target-%s", target, option)
+	default:
+		return fmt.Sprintf("%s-%s", target, option)
+	}
+}
+
+// getTargetPath returns the path of the file written by the file based
+// targets. For other targets, an empty string returns.
+func getTargetPath() string {
+	switch target := viper.GetString("target"); target {
+	case "csv", "ical", "json":
+		return viper.GetString(getTargetOption(target, "path"))
+	default:
+		return ""
+	}
+}
+
 func getUploader() (client.Uploader, error) {
 	switch viper.GetString("target") {
+	case "csv":
+		// The delimiter is validated already
+		delimiter, _ := utf8.DecodeRuneInString(viper.GetString("csv-target-delimiter"))
+
+		return csv.NewUploader(&csv.ClientOpts{
+			BaseClientOpts: client.BaseClientOpts{
+				Timeout:  client.DefaultRequestTimeout,
+				Timezone: getTimezone("csv-target"),
+			},
+			Path:          viper.GetString("csv-target-path"),
+			Delimiter:     delimiter,
+			TagsSeparator: viper.GetString("csv-target-tags-separator"),
+			Append:        viper.GetBool("csv-append"),
+			RotateMonthly: viper.GetBool("csv-rotate-monthly"),
+		})
+	case "ical":
+		return ical.NewUploader(&ical.ClientOpts{
+			BaseClientOpts: client.BaseClientOpts{
+				Timeout:  client.DefaultRequestTimeout,
+				Timezone: getTimezone("ical-target"),
+			},
+			Path:               viper.GetString("ical-target-path"),
+			UnbillableCategory: viper.GetString("ical-unbillable-category"),
+			CalendarName:       viper.GetString("ical-calendar-name"),
+			Append:             viper.GetBool("ical-append"),
+			RotateMonthly:      viper.GetBool("ical-rotate-monthly"),
+		})
+	case "json":
+		return json.NewUploader(&json.ClientOpts{
+			BaseClientOpts: client.BaseClientOpts{
+				Timeout:  client.DefaultRequestTimeout,
+				Timezone: getTimezone("json"),
+			},
+			FileTargetOpts: client.FileTargetOpts{
+				Path:          viper.GetString("json-path"),
+				Append:        viper.GetBool("json-append"),
+				RotateMonthly: viper.GetBool("json-rotate-monthly"),
+			},
+		})
+	case "kimai":
+		return kimai.NewUploader(&kimai.ClientOpts{
+			BaseClientOpts: client.BaseClientOpts{
+				Timeout:  client.DefaultRequestTimeout,
+				Timezone: getTimezone("kimai"),
+			},
+			TokenAuth: client.TokenAuth{
+				Token: viper.GetString("kimai-api-token"),
+			},
+			Username: viper.GetString("kimai-username"),
+			BaseURL:  viper.GetString("kimai-url"),
+		})
+	case "redmine":
+		activities, err := getRedmineActivities()
+		if err != nil {
+			return nil, err
+		}
+
+		return redmine.NewUploader(&redmine.ClientOpts{
+			BaseClientOpts: client.BaseClientOpts{
+				Timeout:  client.DefaultRequestTimeout,
+				Timezone: getTimezone("redmine"),
+			},
+			TokenAuth: client.TokenAuth{
+				Token: viper.GetString("redmine-api-key"),
+			},
+			BaseURL:         viper.GetString("redmine-url"),
+			DayStart:        viper.GetDuration("redmine-day-start"),
+			Activities:      activities,
+			DefaultActivity: viper.GetInt("redmine-default-activity"),
+		})
 	case "tempo":
 		return tempo.NewUploader(&tempo.ClientOpts{
 			BaseClientOpts: client.BaseClientOpts{
-				Timeout: client.DefaultRequestTimeout,
+				Timeout:  client.DefaultRequestTimeout,
+				Timezone: getTimezone("tempo"),
 			},
 			BasicAuth: client.BasicAuth{
 				Username: viper.GetString("tempo-username"),
@@ -0,0 +1,70 @@
+package root
+
+import (
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/spf13/viper"
+)
+
+var (
+	defaultValidationSeverities = map[string]worklog.Severity{
+		worklog.RuleMaxDailyDuration:        worklog.SeverityError,
+		worklog.RuleMinEntryDuration:        worklog.SeverityWarn,
+		worklog.RuleMaxWeeklyClientBillable: worklog.SeverityError,
+		worklog.RuleNoWeekends:              worklog.SeverityWarn,
+	}
+)
+
+// getValidationSeverity returns the severity of the rule set in the config,
+// falling back to the default severity. The severities are already validated,
+// so the parsing errors can be ignored.
+func getValidationSeverity(rule string, severities map[string]string) worklog.Severity {
+	if rawSeverity, ok := severities[rule]; ok {
+		severity, _ := worklog.ParseSeverity(rawSeverity)
+		return severity
+	}
+
+	return defaultValidationSeverities[rule]
+}
+
+func getValidationRules() ([]worklog.Rule, error) {
+	var rules []worklog.Rule
+	location := getLocation()
+
+	severities := map[string]string{}
+	if err := viper.UnmarshalKey("validation-severities", &severities); err != nil {
+		return nil, err
+	}
+
+	if maxDaily := viper.GetDuration("max-daily-duration"); maxDaily > 0 {
+		rules = append(rules, &worklog.MaxDailyDurationRule{
+			Severity: getValidationSeverity(worklog.RuleMaxDailyDuration, severities),
+			Max:      maxDaily,
+			Location: location,
+		})
+	}
+
+	if minEntry := viper.GetDuration("min-entry-duration"); minEntry > 0 {
+		rules = append(rules, &worklog.MinEntryDurationRule{
+			Severity: getValidationSeverity(worklog.RuleMinEntryDuration, severities),
+			Min:      minEntry,
+			Location: location,
+		})
+	}
+
+	if maxWeekly := viper.GetDuration("max-weekly-client-billable"); maxWeekly > 0 {
+		rules = append(rules, &worklog.MaxWeeklyClientBillableRule{
+			Severity: getValidationSeverity(worklog.RuleMaxWeeklyClientBillable, severities),
+			Max:      maxWeekly,
+			Location: location,
+		})
+	}
+
+	if viper.GetBool("no-weekends") {
+		rules = append(rules, &worklog.NoWeekendsRule{
+			Severity: getValidationSeverity(worklog.RuleNoWeekends, severities),
+			Location: location,
+		})
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DateRangeToday     string = "today"
+	DateRangeYesterday string = "yesterday"
+	DateRangeThisWeek  string = "this-week"
+	DateRangeLastWeek  string = "last-week"
+	DateRangeThisMonth string = "this-month"
+	DateRangeLastMonth string = "last-month"
+)
+
+var (
+	// ErrNoDateRange returns when the string is not a date range shortcut.
+	ErrNoDateRange = errors.New("not a date range shortcut")
+	// ErrInvalidISOWeek returns when the ISO week is out of range for the year.
+	ErrInvalidISOWeek = errors.New("invalid ISO week")
+	// ErrInvalidWeekday returns when the weekday name is not known.
+	ErrInvalidWeekday = errors.New("invalid weekday")
+
+	// DateRanges lists the named date range shortcuts.
+	DateRanges = []string{
+		DateRangeToday,
+		DateRangeYesterday,
+		DateRangeThisWeek,
+		DateRangeLastWeek,
+		DateRangeThisMonth,
+		DateRangeLastMonth,
+	}
+
+	relativeDaysRegex = regexp.MustCompile(`^-(\d+)([dw])$`)
+	isoWeekRegex      = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+)
+
+// DateRange represents a time interval, including the Start and excluding the
+// End of the range.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ParseWeekday returns the weekday for its case-insensitive English name.
+func ParseWeekday(rawWeekday string) (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(weekday.String(), rawWeekday) {
+			return weekday, nil
+		}
+	}
+
+	return time.Sunday, fmt.Errorf("%v: %s", ErrInvalidWeekday, rawWeekday)
+}
+
+// midnight returns the start of the day the given time is on.
+func midnight(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// weekStartOf returns the start of the week the given time is on.
+func weekStartOf(t time.Time, weekStart time.Weekday) time.Time {
+	offset := (int(t.Weekday()) - int(weekStart) + 7) % 7
+	return midnight(t).AddDate(0, 0, -offset)
+}
+
+// isoWeekStart returns the Monday of the given ISO week. The 4th of January is
+// always part of the first ISO week of the year.
+func isoWeekStart(year int, week int, loc *time.Location) (time.Time, error) {
+	_, weeksInYear := time.Date(year, time.December, 28, 0, 0, 0, 0, loc).ISOWeek()
+	if week < 1 || week > weeksInYear {
+		return time.Time{}, fmt.Errorf("%v: %d-W%02d", ErrInvalidISOWeek, year, week)
+	}
+
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	return weekStartOf(jan4, time.Monday).AddDate(0, 0, (week-1)*7), nil
+}
+
+// ParseDateRange parses the date range shortcuts relative to now. Besides the
+// named shortcuts listed in DateRanges, it accepts "-<n>d" and "-<n>w" for the
+// last n days or weeks including today, and ISO weeks like "2021-W41". The
+// weekStart is used for the week based shortcuts, except for ISO weeks as
+// those are always starting on Monday. If the string is not a shortcut,
+// ErrNoDateRange returns.
+func ParseDateRange(rawRange string, now time.Time, weekStart time.Weekday) (DateRange, error) {
+	today := midnight(now)
+
+	switch rawRange {
+	case DateRangeToday:
+		return DateRange{Start: today, End: today.AddDate(0, 0, 1)}, nil
+	case DateRangeYesterday:
+		return DateRange{Start: today.AddDate(0, 0, -1), End: today}, nil
+	case DateRangeThisWeek:
+		start := weekStartOf(now, weekStart)
+		return DateRange{Start: start, End: start.AddDate(0, 0, 7)}, nil
+	case DateRangeLastWeek:
+		end := weekStartOf(now, weekStart)
+		return DateRange{Start: end.AddDate(0, 0, -7), End: end}, nil
+	case DateRangeThisMonth:
+		start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		return DateRange{Start: start, End: start.AddDate(0, 1, 0)}, nil
+	case DateRangeLastMonth:
+		end := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		return DateRange{Start: end.AddDate(0, -1, 0), End: end}, nil
+	}
+
+	if match := relativeDaysRegex.FindStringSubmatch(rawRange); match != nil {
+		// The regex ensures that the value is a number
+		days, _ := strconv.Atoi(match[1])
+		if match[2] == "w" {
+			days *= 7
+		}
+
+		return DateRange{Start: today.AddDate(0, 0, -days), End: today.AddDate(0, 0, 1)}, nil
+	}
+
+	if match := isoWeekRegex.FindStringSubmatch(rawRange); match != nil {
+		// The regex ensures that the values are numbers
+		year, _ := strconv.Atoi(match[1])
+		week, _ := strconv.Atoi(match[2])
+
+		start, err := isoWeekStart(year, week, now.Location())
+		if err != nil {
+			return DateRange{}, err
+		}
+
+		return DateRange{Start: start, End: start.AddDate(0, 0, 7)}, nil
+	}
+
+	return DateRange{}, ErrNoDateRange
+}
+
+// GetDateRange returns the start and end dates parsed from the raw values.
+// Both values can be a date in the given format or a date range shortcut. When
+// a shortcut is used for the start, its end will be used as the end date unless
+// the end is set explicitly. When a shortcut is used for the end, the end of
+// the range will be used. If the start is not set, today's midnight, if the end
+// is not set at all, the next day's midnight will be used. The dates are parsed
+// in the location of now.
+func GetDateRange(rawStart string, rawEnd string, dateFormat string, now time.Time, weekStart time.Weekday) (time.Time, time.Time, error) {
+	var start time.Time
+	var end time.Time
+
+	defaultEnd := midnight(now).AddDate(0, 0, 1)
+
+	startRange, err := ParseDateRange(rawStart, now, weekStart)
+	if rawStart == "" {
+		start = midnight(now)
+	} else if err == nil {
+		start = startRange.Start
+		defaultEnd = startRange.End
+	} else if errors.Is(err, ErrNoDateRange) {
+		if start, err = GetTime(rawStart, dateFormat, now.Location()); err != nil {
+			return start, end, err
+		}
+	} else {
+		return start, end, err
+	}
+
+	if rawEnd == "" {
+		return start, defaultEnd, nil
+	}
+
+	endRange, err := ParseDateRange(rawEnd, now, weekStart)
+	if err == nil {
+		end = endRange.End
+	} else if errors.Is(err, ErrNoDateRange) {
+		end, err = GetTime(rawEnd, dateFormat, now.Location())
+	}
+
+	return start, end, err
+}
@@ -0,0 +1,217 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/cmd/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWeekday(t *testing.T) {
+	weekday, err := utils.ParseWeekday("Sunday")
+	require.Nil(t, err)
+	require.Equal(t, time.Sunday, weekday)
+
+	weekday, err = utils.ParseWeekday("monday")
+	require.Nil(t, err)
+	require.Equal(t, time.Monday, weekday)
+
+	_, err = utils.ParseWeekday("someday")
+	require.EqualError(t, err, "invalid weekday: someday")
+}
+
+func TestParseDateRange(t *testing.T) {
+	// Wednesday
+	now := time.Date(2021, 10, 13, 14, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		rawRange  string
+		weekStart time.Weekday
+		want      utils.DateRange
+	}{
+		{
+			name:     "today",
+			rawRange: "today",
+			want: utils.DateRange{
+				Start: time.Date(2021, 10, 13, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 14, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:     "yesterday",
+			rawRange: "yesterday",
+			want: utils.DateRange{
+				Start: time.Date(2021, 10, 12, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 13, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:      "this week starting on monday",
+			rawRange:  "this-week",
+			weekStart: time.Monday,
+			want: utils.DateRange{
+				Start: time.Date(2021, 10, 11, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 18, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:      "last week starting on monday",
+			rawRange:  "last-week",
+			weekStart: time.Monday,
+			want: utils.DateRange{
+				Start: time.Date(2021, 10, 4, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 11, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:      "last week starting on sunday",
+			rawRange:  "last-week",
+			weekStart: time.Sunday,
+			want: utils.DateRange{
+				Start: time.Date(2021, 10, 3, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 10, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:      "this week starting today",
+			rawRange:  "this-week",
+			weekStart: time.Wednesday,
+			want: utils.DateRange{
+				Start: time.Date(2021, 10, 13, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 20, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:     "this month",
+			rawRange: "this-month",
+			want: utils.DateRange{
+				Start: time.Date(2021, 10, 1, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 11, 1, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:     "last month",
+			rawRange: "last-month",
+			want: utils.DateRange{
+				Start: time.Date(2021, 9, 1, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 1, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:     "last days",
+			rawRange: "-3d",
+			want: utils.DateRange{
+				Start: time.Date(2021, 10, 10, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 14, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:     "last weeks",
+			rawRange: "-2w",
+			want: utils.DateRange{
+				Start: time.Date(2021, 9, 29, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 14, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:      "ISO week",
+			rawRange:  "2021-W41",
+			weekStart: time.Sunday,
+			want: utils.DateRange{
+				Start: time.Date(2021, 10, 11, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 10, 18, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:     "ISO week in previous year",
+			rawRange: "2021-W01",
+			want: utils.DateRange{
+				Start: time.Date(2021, 1, 4, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 1, 11, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:     "ISO week 53",
+			rawRange: "2020-W53",
+			want: utils.DateRange{
+				Start: time.Date(2020, 12, 28, 0, 0, 0, 0, time.Local),
+				End:   time.Date(2021, 1, 4, 0, 0, 0, 0, time.Local),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dateRange, err := utils.ParseDateRange(tt.rawRange, now, tt.weekStart)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, dateRange)
+		})
+	}
+}
+
+func TestParseDateRangeErrors(t *testing.T) {
+	now := time.Date(2021, 10, 13, 14, 30, 0, 0, time.Local)
+
+	_, err := utils.ParseDateRange("2021-10-13", now, time.Monday)
+	require.ErrorIs(t, err, utils.ErrNoDateRange)
+
+	_, err = utils.ParseDateRange("2021-W53", now, time.Monday)
+	require.EqualError(t, err, "invalid ISO week: 2021-W53")
+}
+
+func TestGetDateRange(t *testing.T) {
+	now := time.Date(2021, 10, 13, 14, 30, 0, 0, time.Local)
+	dateFormat := "2006-01-02"
+
+	start, end, err := utils.GetDateRange("", "", dateFormat, now, time.Monday)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 13, 0, 0, 0, 0, time.Local), start)
+	require.Equal(t, time.Date(2021, 10, 14, 0, 0, 0, 0, time.Local), end)
+
+	start, end, err = utils.GetDateRange("2021-10-01", "", dateFormat, now, time.Monday)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 1, 0, 0, 0, 0, time.Local), start)
+	require.Equal(t, time.Date(2021, 10, 14, 0, 0, 0, 0, time.Local), end)
+
+	start, end, err = utils.GetDateRange("last-week", "", dateFormat, now, time.Monday)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 4, 0, 0, 0, 0, time.Local), start)
+	require.Equal(t, time.Date(2021, 10, 11, 0, 0, 0, 0, time.Local), end)
+
+	start, end, err = utils.GetDateRange("last-month", "2021-09-15", dateFormat, now, time.Monday)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 9, 1, 0, 0, 0, 0, time.Local), start)
+	require.Equal(t, time.Date(2021, 9, 15, 0, 0, 0, 0, time.Local), end)
+
+	start, end, err = utils.GetDateRange("2021-09-20", "yesterday", dateFormat, now, time.Monday)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 9, 20, 0, 0, 0, 0, time.Local), start)
+	require.Equal(t, time.Date(2021, 10, 13, 0, 0, 0, 0, time.Local), end)
+
+	_, _, err = utils.GetDateRange("2021/10/01", "", dateFormat, now, time.Monday)
+	require.Error(t, err)
+
+	_, _, err = utils.GetDateRange("today", "2021/10/01", dateFormat, now, time.Monday)
+	require.Error(t, err)
+}
+
+func TestGetDateRange_Location(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.Nil(t, err)
+
+	// It is already the next day in Tokyo
+	now := time.Date(2021, 10, 13, 20, 0, 0, 0, time.UTC).In(tokyo)
+	dateFormat := "2006-01-02"
+
+	start, end, err := utils.GetDateRange("", "", dateFormat, now, time.Monday)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 14, 0, 0, 0, 0, tokyo), start)
+	require.Equal(t, time.Date(2021, 10, 15, 0, 0, 0, 0, tokyo), end)
+
+	start, end, err = utils.GetDateRange("2021-10-01", "2021-10-02", dateFormat, now, time.Monday)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 9, 30, 15, 0, 0, 0, time.UTC), start.UTC())
+	require.Equal(t, time.Date(2021, 10, 1, 15, 0, 0, 0, time.UTC), end.UTC())
+}
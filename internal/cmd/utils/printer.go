@@ -16,6 +16,7 @@ import (
 
 const (
 	rowDateFormat    string = "2006-01-02 15:04:05"
+	runningMark      string = " (running)"
 	ColumnTask       string = "task"
 	ColumnSummary    string = "summary"
 	ColumnProject    string = "project"
@@ -24,6 +25,7 @@ const (
 	ColumnEnd        string = "end"
 	ColumnBillable   string = "billable"
 	ColumnUnbillable string = "unbillable"
+	ColumnWarnings   string = "warnings"
 )
 
 // Columns lists all available columns that can be printed.
@@ -36,6 +38,7 @@ var Columns = []string{
 	ColumnEnd,
 	ColumnBillable,
 	ColumnUnbillable,
+	ColumnWarnings,
 }
 
 // HideableColumns lists all columns that can be hidden when printing.
@@ -45,6 +48,7 @@ var HideableColumns = []string{
 	ColumnClient,
 	ColumnStart,
 	ColumnEnd,
+	ColumnWarnings,
 }
 
 // TableColumnConfig represents the configuration of a column.
@@ -88,16 +92,29 @@ type TablePrinterOpts struct {
 	Style           table.Style
 	ColumnConfig    []table.ColumnConfig
 	ColumnTruncates map[string]int
+	// Warnings maps the entry keys to the list of warnings shown in the
+	// warnings column.
+	Warnings map[string][]string
+	// Location sets the timezone of the printed dates. If not set, the local
+	// timezone is used.
+	Location *time.Location
 }
 
 type tablePrinter struct {
 	writer      table.Writer
 	truncateMap map[string]int
+	warnings    map[string][]string
+	location    *time.Location
 }
 
 func (p *tablePrinter) convertEntryToRow(entry *worklog.Entry) table.Row {
-	entryStart := entry.Start.Local()
-	timeSpent := entry.BillableDuration + entry.UnbillableDuration
+	entryStart := entry.Start.In(p.location)
+	entryEnd := entry.EndTime().In(p.location).Format(rowDateFormat)
+
+	// The end of running entries is the time of fetching, not the real end
+	if entry.Running {
+		entryEnd += runningMark
+	}
 
 	return table.Row{
 		Truncate(entry.Task.Name, p.truncateMap[ColumnTask]),
@@ -105,9 +122,10 @@ func (p *tablePrinter) convertEntryToRow(entry *worklog.Entry) table.Row {
 		Truncate(entry.Project.Name, p.truncateMap[ColumnProject]),
 		Truncate(entry.Client.Name, p.truncateMap[ColumnClient]),
 		entryStart.Format(rowDateFormat),
-		entryStart.Add(timeSpent).Format(rowDateFormat),
+		entryEnd,
 		entry.BillableDuration,
 		entry.UnbillableDuration,
+		Truncate(strings.Join(p.warnings[entry.Key()], "; "), p.truncateMap[ColumnWarnings]),
 	}
 }
 
@@ -135,7 +153,7 @@ func (p *tablePrinter) Print(completeEntries worklog.Entries, incompleteEntries
 	p.generateRows(completeEntries, &totalBillable, &totalUnbillable)
 
 	p.writer.AppendFooter(table.Row{
-		"", "", "", "", "", "total time spent", totalBillable.String(), totalUnbillable.String(),
+		"", "", "", "", "", "total time spent", totalBillable.String(), totalUnbillable.String(), "",
 	})
 	p.writer.SetCaption(
 		"You have %d complete and %d incomplete items. Before proceeding, please double-check them.\n",
@@ -175,10 +193,37 @@ func NewTablePrinter(opts *TablePrinterOpts) Printer {
 
 	writer.SortBy(sortBy)
 
+	location := opts.Location
+	if location == nil {
+		location = time.Local
+	}
+
 	return &tablePrinter{
 		writer:      writer,
 		truncateMap: opts.ColumnTruncates,
+		warnings:    opts.Warnings,
+		location:    location,
+	}
+}
+
+// PrintValidationReport prints the violations of the validation rules as a
+// table. If the report is empty, nothing will be printed.
+func PrintValidationReport(output io.Writer, report worklog.ValidationReport, style table.Style) {
+	if len(report) == 0 {
+		return
+	}
+
+	writer := table.NewWriter()
+	writer.SetOutputMirror(output)
+	writer.SetTitle("Validation report")
+	writer.SetStyle(style)
+	writer.AppendHeader(table.Row{"severity", "rule", "message"})
+
+	for _, violation := range report {
+		writer.AppendRow(table.Row{violation.Severity, violation.Rule, violation.Message})
 	}
+
+	writer.Render()
 }
 
 // ParseColumnConfigs parses the column configs taken from the config file.
@@ -3,10 +3,12 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/gabor-boros/minutes/internal/pkg/client"
 	"github.com/spf13/cobra"
 )
 
@@ -41,9 +43,22 @@ func IsSliceContains(entry string, slice []string) bool {
 	return false
 }
 
-// Prompt shows the user a message and asks for input, then returns that.
-func Prompt(message string) string {
-	fmt.Print(message)
+// MessageOutput returns the output of the messages shown to the user, like the
+// tables, reports, and prompts. If the target path is the standard output, the
+// messages are written to the standard error, so the written entries can be
+// piped or redirected.
+func MessageOutput(targetPath string) io.Writer {
+	if targetPath == client.PathStdout {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+// Prompt shows the user a message on the output and asks for input, then
+// returns that.
+func Prompt(output io.Writer, message string) string {
+	_, _ = fmt.Fprint(output, message)
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -52,13 +67,25 @@ func Prompt(message string) string {
 	return strings.TrimSpace(input)
 }
 
-// GetTime parses a string based on the given format and returns the time.
-// If the rawDate was an empty string, the today's midnight will return.
-func GetTime(rawDate string, dateFormat string) (time.Time, error) {
+// GetTime parses a string based on the given format in the given location and
+// returns the time. If the rawDate was an empty string, the today's midnight
+// will return.
+func GetTime(rawDate string, dateFormat string, location *time.Location) (time.Time, error) {
 	if rawDate == "" {
-		year, month, day := time.Now().Date()
-		return time.Date(year, month, day, 0, 0, 0, 0, time.Local), nil
+		year, month, day := time.Now().In(location).Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, location), nil
+	}
+
+	return time.ParseInLocation(dateFormat, rawDate, location)
+}
+
+// ParseTimeOfDay parses a "15:04" formatted time of the day and returns its
+// offset from midnight.
+func ParseTimeOfDay(rawTime string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", rawTime)
+	if err != nil {
+		return 0, err
 	}
 
-	return time.ParseInLocation(dateFormat, rawDate, time.Local)
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
 }
@@ -1,10 +1,12 @@
 package utils_test
 
 import (
+	"os"
 	"testing"
 	"time"
 
 	"github.com/gabor-boros/minutes/internal/cmd/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/client"
 
 	"github.com/stretchr/testify/require"
 )
@@ -45,11 +47,35 @@ func TestGetTime(t *testing.T) {
 
 	year, month, day := time.Now().Date()
 
-	parsed, err = utils.GetTime("2021-01-01 01:00:00", "2006-01-02 15:04:05")
+	parsed, err = utils.GetTime("2021-01-01 01:00:00", "2006-01-02 15:04:05", time.Local)
 	require.Nil(t, err)
 	require.Equal(t, time.Date(2021, 1, 1, 1, 0, 0, 0, time.Local), parsed)
 
-	parsed, err = utils.GetTime("", "2006-01-02")
+	parsed, err = utils.GetTime("", "2006-01-02", time.Local)
 	require.Nil(t, err)
 	require.Equal(t, time.Date(year, month, day, 0, 0, 0, 0, time.Local), parsed)
 }
+
+func TestGetTime_Location(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.Nil(t, err)
+
+	parsed, err := utils.GetTime("2021-01-01 01:00:00", "2006-01-02 15:04:05", tokyo)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2020, 12, 31, 16, 0, 0, 0, time.UTC), parsed.UTC())
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	offset, err := utils.ParseTimeOfDay("09:30")
+	require.Nil(t, err)
+	require.Equal(t, time.Hour*9+time.Minute*30, offset)
+
+	_, err = utils.ParseTimeOfDay("9 o'clock")
+	require.Error(t, err)
+}
+
+func TestMessageOutput(t *testing.T) {
+	require.Equal(t, os.Stdout, utils.MessageOutput(""))
+	require.Equal(t, os.Stdout, utils.MessageOutput("worklog.json"))
+	require.Equal(t, os.Stderr, utils.MessageOutput(client.PathStdout))
+}
@@ -0,0 +1,440 @@
+package activitywatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "activitywatch"
+	// DefaultURL is the default URL of the local aw-server.
+	DefaultURL string = "http://localhost:5600"
+	// DefaultMergeGap is the default maximum gap between the activities of a
+	// session.
+	DefaultMergeGap time.Duration = 5 * time.Minute
+	// PathBuckets is the API endpoint used to list the buckets.
+	PathBuckets string = "/api/0/buckets/"
+	// PathEvents is the API endpoint used to list the events of a bucket.
+	PathEvents string = "/api/0/buckets/%s/events"
+
+	// RuleFieldApp matches the rule on the application name of the window.
+	RuleFieldApp string = "app"
+	// RuleFieldTitle matches the rule on the title of the window.
+	RuleFieldTitle string = "title"
+	// RuleFieldURL matches the rule on the URL of the browser tab.
+	RuleFieldURL string = "url"
+)
+
+var (
+	// ErrBucketNotFound returns when no window bucket found for the hostname.
+	ErrBucketNotFound = errors.New("bucket not found")
+	// ErrInvalidRule returns when a rule has an unknown field or invalid regex.
+	ErrInvalidRule = errors.New("invalid rule")
+
+	// RuleFields are the fields the rules can match on.
+	RuleFields = []string{RuleFieldApp, RuleFieldTitle, RuleFieldURL}
+)
+
+// Rule maps the activities having a Field matching the Regex to a client,
+// project, and task. The Client, Project, Task, and Summary may refer to the
+// submatches of the regex, like "$1" or "${key}". If the Task is not set, the
+// whole match is the task. If the Summary is not set, the window title having
+// the most time spent in the session is the summary.
+type Rule struct {
+	Field      string
+	Regex      string
+	Client     string
+	Project    string
+	Task       string
+	Summary    string
+	Unbillable bool
+}
+
+type compiledRule struct {
+	Rule
+	regex *regexp.Regexp
+}
+
+// ruleMatch is the outcome of a rule matching an activity. Consecutive
+// activities having the same outcome belong to the same session.
+type ruleMatch struct {
+	client     string
+	project    string
+	task       string
+	summary    string
+	unbillable bool
+}
+
+// match returns the outcome of the rule if it matches the activity.
+func (r *compiledRule) match(activity *Activity) (ruleMatch, bool) {
+	var value string
+	switch r.Field {
+	case RuleFieldApp:
+		value = activity.App
+	case RuleFieldTitle:
+		value = activity.Title
+	case RuleFieldURL:
+		value = activity.URL
+	}
+
+	submatches := r.regex.FindStringSubmatchIndex(value)
+	if submatches == nil {
+		return ruleMatch{}, false
+	}
+
+	expand := func(template string) string {
+		return string(r.regex.ExpandString(nil, template, value, submatches))
+	}
+
+	task := expand(r.Task)
+	if r.Task == "" {
+		task = value[submatches[0]:submatches[1]]
+	}
+
+	return ruleMatch{
+		client:     expand(r.Client),
+		project:    expand(r.Project),
+		task:       task,
+		summary:    expand(r.Summary),
+		unbillable: r.Unbillable,
+	}, true
+}
+
+// compileRules validates the fields and compiles the regexes of the rules.
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	compiledRules := make([]compiledRule, len(rules))
+
+	for i, rule := range rules {
+		switch rule.Field {
+		case RuleFieldApp, RuleFieldTitle, RuleFieldURL:
+		default:
+			return nil, fmt.Errorf("%v: unknown field %q", ErrInvalidRule, rule.Field)
+		}
+
+		regex, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", ErrInvalidRule, err)
+		}
+
+		compiledRules[i] = compiledRule{Rule: rule, regex: regex}
+	}
+
+	return compiledRules, nil
+}
+
+// session represents consecutive activities matched by the same outcome.
+type session struct {
+	ruleMatch
+	start       time.Time
+	end         time.Time
+	duration    time.Duration
+	eventID     int
+	lastEventID int
+	titles      map[string]time.Duration
+	running     bool
+}
+
+func (s *session) add(activity *Activity) {
+	s.end = activity.End
+	s.duration += activity.Duration()
+	s.titles[activity.Title] += activity.Duration()
+	s.lastEventID = activity.EventID
+}
+
+// isRunning returns true if the session ends with the given window event,
+// which is still extended by the heartbeats of the watcher.
+func (s *session) isRunning(latestEvent *Event) bool {
+	return s.lastEventID == latestEvent.ID && s.end.Equal(latestEvent.End())
+}
+
+// extendTo extends the running session until the end, as the time since the
+// last heartbeat is spent in the same window.
+func (s *session) extendTo(end time.Time) {
+	if end.After(s.end) {
+		s.duration += end.Sub(s.end)
+		s.end = end
+	}
+
+	s.running = true
+}
+
+// sortedTitles returns the window titles of the session, ordered by the time
+// spent on them.
+func (s *session) sortedTitles() []string {
+	titles := make([]string, 0, len(s.titles))
+	for title := range s.titles {
+		if title != "" {
+			titles = append(titles, title)
+		}
+	}
+
+	sort.Slice(titles, func(i, j int) bool {
+		if s.titles[titles[i]] != s.titles[titles[j]] {
+			return s.titles[titles[i]] > s.titles[titles[j]]
+		}
+
+		return titles[i] < titles[j]
+	})
+
+	return titles
+}
+
+func (s *session) entry() worklog.Entry {
+	titles := s.sortedTitles()
+
+	summary := s.summary
+	if summary == "" && len(titles) > 0 {
+		summary = titles[0]
+	}
+
+	entry := worklog.Entry{
+		Client:   worklog.IDNameField{ID: s.client, Name: s.client},
+		Project:  worklog.IDNameField{ID: s.project, Name: s.project},
+		Task:     worklog.IDNameField{ID: s.task, Name: s.task},
+		Summary:  summary,
+		Notes:    strings.Join(titles, "\n"),
+		Start:    s.start,
+		End:      s.end,
+		Source:   SourceName,
+		SourceID: strconv.Itoa(s.eventID),
+		Running:  s.running,
+	}
+
+	if s.unbillable {
+		entry.UnbillableDuration = s.duration.Round(time.Second)
+	} else {
+		entry.BillableDuration = s.duration.Round(time.Second)
+	}
+
+	return entry
+}
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// The events are read from the buckets of the Hostname, defaulting to the host
+// of the machine. The activities are mapped by the first matching Rule, and
+// the consecutive activities of the same outcome are merged into a session if
+// the gap between them is not longer than the MergeGap.
+type ClientOpts struct {
+	client.BaseClientOpts
+	BaseURL  string
+	Hostname string
+	MergeGap time.Duration
+	Rules    []Rule
+}
+
+type activityWatchClient struct {
+	*client.BaseClientOpts
+	*client.HTTPClient
+	hostname string
+	mergeGap time.Duration
+	rules    []compiledRule
+}
+
+func (c *activityWatchClient) get(ctx context.Context, path string, params map[string]string, data interface{}) error {
+	reqURL, err := c.URL(path, params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Call(ctx, &client.HTTPRequestOpts{
+		Method:  http.MethodGet,
+		Url:     reqURL,
+		Timeout: c.Timeout,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(resp, data)
+}
+
+// fetchEvents returns the events of the buckets within the date range. Since
+// the events can start before the date range, they are clipped later.
+func (c *activityWatchClient) fetchEvents(ctx context.Context, buckets []Bucket, opts *client.FetchOpts) ([]Event, error) {
+	events := []Event{}
+
+	for _, bucket := range buckets {
+		var bucketEvents []Event
+		err := c.get(ctx, fmt.Sprintf(PathEvents, url.PathEscape(bucket.ID)), map[string]string{
+			"start": utils.DateFormatRFC3339UTC.Format(opts.Start),
+			"end":   utils.DateFormatRFC3339UTC.Format(opts.End),
+			"limit": "-1",
+		}, &bucketEvents)
+
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, bucketEvents...)
+	}
+
+	return events, nil
+}
+
+// sessions merges the consecutive activities matched by the same outcome into
+// sessions. The activities matched by no rule are dropped.
+func (c *activityWatchClient) sessions(activities []Activity) []*session {
+	var sessions []*session
+	var current *session
+
+	for i := range activities {
+		activity := &activities[i]
+
+		var outcome ruleMatch
+		matched := false
+		for _, rule := range c.rules {
+			if outcome, matched = rule.match(activity); matched {
+				break
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		if current != nil && current.ruleMatch == outcome && activity.Start.Sub(current.end) <= c.mergeGap {
+			current.add(activity)
+			continue
+		}
+
+		current = &session{
+			ruleMatch: outcome,
+			start:     activity.Start,
+			eventID:   activity.EventID,
+			titles:    make(map[string]time.Duration),
+		}
+
+		current.add(activity)
+		sessions = append(sessions, current)
+	}
+
+	return sessions
+}
+
+func (c *activityWatchClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	var buckets map[string]Bucket
+	if err := c.get(ctx, PathBuckets, map[string]string{}, &buckets); err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	bucketsByType := make(map[string][]Bucket)
+	for _, bucket := range buckets {
+		// The web watcher runs in the browser, so its buckets have no hostname
+		if bucket.Hostname == c.hostname || bucket.Type == BucketTypeWeb {
+			bucketsByType[bucket.Type] = append(bucketsByType[bucket.Type], bucket)
+		}
+	}
+
+	if len(bucketsByType[BucketTypeWindow]) == 0 {
+		return nil, fmt.Errorf("%v: %v: %s for %s", client.ErrFetchEntries, ErrBucketNotFound, BucketTypeWindow, c.hostname)
+	}
+
+	windowEvents, err := c.fetchEvents(ctx, bucketsByType[BucketTypeWindow], opts)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	// Without the AFK watcher, the user is considered active all the time
+	var afkEvents []Event
+	if len(bucketsByType[BucketTypeAFK]) > 0 {
+		if afkEvents, err = c.fetchEvents(ctx, bucketsByType[BucketTypeAFK], opts); err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+	}
+
+	webEvents, err := c.fetchEvents(ctx, bucketsByType[BucketTypeWeb], opts)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	activities := Activities(windowEvents, afkEvents, webEvents, opts.Start, opts.End)
+	for i := range activities {
+		activities[i].Start = activities[i].Start.In(c.Location())
+		activities[i].End = activities[i].End.In(c.Location())
+	}
+
+	// The latest window event is extended by heartbeats while the window is
+	// active, so it is not finished if the date range includes now
+	var latestEvent *Event
+	if opts.End.After(time.Now()) {
+		latestEvent = latestWindowEvent(windowEvents)
+	}
+
+	var entries worklog.Entries
+	for _, s := range c.sessions(activities) {
+		if latestEvent != nil && s.isRunning(latestEvent) {
+			end, keep, err := opts.RunningEntryEnd(s.start)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+			}
+
+			if !keep {
+				continue
+			}
+
+			s.extendTo(end)
+		}
+
+		entries = append(entries, s.entry())
+	}
+
+	return entries, nil
+}
+
+// latestWindowEvent returns the window event started the latest. If there are
+// no events, nil returns.
+func latestWindowEvent(windowEvents []Event) *Event {
+	var latestEvent *Event
+
+	for i := range windowEvents {
+		if latestEvent == nil || windowEvents[i].Timestamp.After(latestEvent.Timestamp) {
+			latestEvent = &windowEvents[i]
+		}
+	}
+
+	return latestEvent
+}
+
+// NewFetcher returns a new ActivityWatch client for fetching entries.
+func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
+	baseURL, err := url.Parse(opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := compileRules(opts.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname := opts.Hostname
+	if hostname == "" {
+		if hostname, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &activityWatchClient{
+		BaseClientOpts: &opts.BaseClientOpts,
+		HTTPClient:     &client.HTTPClient{BaseURL: baseURL},
+		hostname:       hostname,
+		mergeGap:       opts.MergeGap,
+		rules:          rules,
+	}, nil
+}
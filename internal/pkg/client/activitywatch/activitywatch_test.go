@@ -0,0 +1,295 @@
+package activitywatch_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/activitywatch"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func newClientOpts(baseURL string) *activitywatch.ClientOpts {
+	return &activitywatch.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
+		},
+		BaseURL:  baseURL,
+		Hostname: "laptop",
+		MergeGap: activitywatch.DefaultMergeGap,
+		Rules: []activitywatch.Rule{
+			{
+				Field:   activitywatch.RuleFieldURL,
+				Regex:   `atlassian\.net/browse/(?P<key>[A-Z]+-\d+)`,
+				Client:  "ACME",
+				Project: "Jira",
+				Task:    "${key}",
+			},
+			{
+				Field:   activitywatch.RuleFieldTitle,
+				Regex:   `(ABC)-\d+`,
+				Client:  "ACME",
+				Project: "$1",
+			},
+			{
+				Field:      activitywatch.RuleFieldApp,
+				Regex:      `^Slack$`,
+				Client:     "ACME",
+				Project:    "Internal",
+				Task:       "Communication",
+				Summary:    "Chatting",
+				Unbillable: true,
+			},
+		},
+	}
+}
+
+func TestActivityWatchClient_FetchEntries(t *testing.T) {
+	start := time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 2, 12, 0, 0, 0, time.UTC)
+
+	bucketEvents := map[string][]activitywatch.Event{
+		"aw-watcher-window_laptop": {
+			newEvent(1, start, 20*time.Minute, activitywatch.EventData{App: "Code", Title: "ABC-1 main.go - minutes"}),
+			newEvent(2, start.Add(22*time.Minute), 8*time.Minute, activitywatch.EventData{App: "Code", Title: "ABC-1 parser.go - minutes"}),
+			newEvent(3, start.Add(30*time.Minute), 10*time.Minute, activitywatch.EventData{App: "Slack", Title: "Slack"}),
+			newEvent(4, start.Add(40*time.Minute), 20*time.Minute, activitywatch.EventData{App: "Firefox", Title: "ABC-2 Review - Jira - Mozilla Firefox"}),
+			newEvent(5, start.Add(time.Hour), 30*time.Minute, activitywatch.EventData{App: "Code", Title: "ABC-1 main.go - minutes"}),
+			newEvent(6, start.Add(105*time.Minute), 15*time.Minute, activitywatch.EventData{App: "Spotify", Title: "Spotify"}),
+		},
+		"aw-watcher-afk_laptop": {
+			newEvent(1, start.Add(-time.Hour), 2*time.Hour, activitywatch.EventData{Status: activitywatch.StatusNotAFK}),
+			newEvent(2, start.Add(time.Hour), 30*time.Minute, activitywatch.EventData{Status: "afk"}),
+			newEvent(3, start.Add(90*time.Minute), time.Hour, activitywatch.EventData{Status: activitywatch.StatusNotAFK}),
+		},
+		"aw-watcher-web-firefox": {
+			newEvent(1, start.Add(40*time.Minute), 10*time.Minute, activitywatch.EventData{
+				Title: "ABC-2 Review - Jira",
+				URL:   "https://example.atlassian.net/browse/ABC-2",
+			}),
+		},
+	}
+
+	var queries []url.Values
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+
+		var err error
+		if r.URL.Path == activitywatch.PathBuckets {
+			err = json.NewEncoder(w).Encode(map[string]activitywatch.Bucket{
+				"aw-watcher-window_laptop":  {ID: "aw-watcher-window_laptop", Type: activitywatch.BucketTypeWindow, Hostname: "laptop"},
+				"aw-watcher-window_desktop": {ID: "aw-watcher-window_desktop", Type: activitywatch.BucketTypeWindow, Hostname: "desktop"},
+				"aw-watcher-afk_laptop":     {ID: "aw-watcher-afk_laptop", Type: activitywatch.BucketTypeAFK, Hostname: "laptop"},
+				"aw-watcher-web-firefox":    {ID: "aw-watcher-web-firefox", Type: activitywatch.BucketTypeWeb, Hostname: "unknown"},
+			})
+			require.Nil(t, err, "cannot encode response data")
+			return
+		}
+
+		for bucketID, events := range bucketEvents {
+			if r.URL.Path == fmt.Sprintf(activitywatch.PathEvents, bucketID) {
+				queries = append(queries, r.URL.Query())
+				err = json.NewEncoder(w).Encode(events)
+				require.Nil(t, err, "cannot encode response data")
+				return
+			}
+		}
+
+		require.Failf(t, "unexpected path", r.URL.Path)
+	}))
+	defer mockServer.Close()
+
+	activityWatchClient, err := activitywatch.NewFetcher(newClientOpts(mockServer.URL))
+	require.Nil(t, err)
+
+	entries, err := activityWatchClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: start,
+		End:   end,
+	})
+	require.Nil(t, err, "cannot fetch entries")
+
+	acme := worklog.IDNameField{ID: "ACME", Name: "ACME"}
+
+	require.Equal(t, worklog.Entries{
+		{
+			Client:           acme,
+			Project:          worklog.IDNameField{ID: "ABC", Name: "ABC"},
+			Task:             worklog.IDNameField{ID: "ABC-1", Name: "ABC-1"},
+			Summary:          "ABC-1 main.go - minutes",
+			Notes:            "ABC-1 main.go - minutes\nABC-1 parser.go - minutes",
+			Start:            start,
+			End:              start.Add(30 * time.Minute),
+			BillableDuration: 28 * time.Minute,
+			Source:           activitywatch.SourceName,
+			SourceID:         "1",
+		},
+		{
+			Client:             acme,
+			Project:            worklog.IDNameField{ID: "Internal", Name: "Internal"},
+			Task:               worklog.IDNameField{ID: "Communication", Name: "Communication"},
+			Summary:            "Chatting",
+			Notes:              "Slack",
+			Start:              start.Add(30 * time.Minute),
+			End:                start.Add(40 * time.Minute),
+			UnbillableDuration: 10 * time.Minute,
+			Source:             activitywatch.SourceName,
+			SourceID:           "3",
+		},
+		{
+			Client:           acme,
+			Project:          worklog.IDNameField{ID: "Jira", Name: "Jira"},
+			Task:             worklog.IDNameField{ID: "ABC-2", Name: "ABC-2"},
+			Summary:          "ABC-2 Review - Jira - Mozilla Firefox",
+			Notes:            "ABC-2 Review - Jira - Mozilla Firefox",
+			Start:            start.Add(40 * time.Minute),
+			End:              start.Add(50 * time.Minute),
+			BillableDuration: 10 * time.Minute,
+			Source:           activitywatch.SourceName,
+			SourceID:         "4",
+		},
+		{
+			Client:           acme,
+			Project:          worklog.IDNameField{ID: "ABC", Name: "ABC"},
+			Task:             worklog.IDNameField{ID: "ABC-2", Name: "ABC-2"},
+			Summary:          "ABC-2 Review - Jira - Mozilla Firefox",
+			Notes:            "ABC-2 Review - Jira - Mozilla Firefox",
+			Start:            start.Add(50 * time.Minute),
+			End:              start.Add(time.Hour),
+			BillableDuration: 10 * time.Minute,
+			Source:           activitywatch.SourceName,
+			SourceID:         "4",
+		},
+	}, entries)
+
+	require.Len(t, queries, 3)
+	for _, query := range queries {
+		require.Equal(t, "2021-10-02T09:00:00Z", query.Get("start"))
+		require.Equal(t, "2021-10-02T12:00:00Z", query.Get("end"))
+		require.Equal(t, "-1", query.Get("limit"))
+	}
+}
+
+func TestActivityWatchClient_FetchEntries_Timezone(t *testing.T) {
+	start := time.Date(2021, 10, 2, 20, 0, 0, 0, time.UTC)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.Nil(t, err)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if r.URL.Path == activitywatch.PathBuckets {
+			err = json.NewEncoder(w).Encode(map[string]activitywatch.Bucket{
+				"aw-watcher-window_laptop": {ID: "aw-watcher-window_laptop", Type: activitywatch.BucketTypeWindow, Hostname: "laptop"},
+			})
+		} else {
+			err = json.NewEncoder(w).Encode([]activitywatch.Event{
+				newEvent(1, start, time.Hour, activitywatch.EventData{App: "Code", Title: "ABC-1 main.go - minutes"}),
+			})
+		}
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	clientOpts := newClientOpts(mockServer.URL)
+	clientOpts.Timezone = tokyo
+
+	activityWatchClient, err := activitywatch.NewFetcher(clientOpts)
+	require.Nil(t, err)
+
+	entries, err := activityWatchClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: start,
+		End:   start.Add(2 * time.Hour),
+	})
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, 1)
+
+	// The evening work in UTC is on the next day in Tokyo
+	require.Equal(t, tokyo, entries[0].Start.Location())
+	require.Equal(t, time.Date(2021, 10, 3, 5, 0, 0, 0, tokyo), entries[0].Start)
+	require.Equal(t, time.Date(2021, 10, 3, 6, 0, 0, 0, tokyo), entries[0].End)
+}
+
+func TestActivityWatchClient_FetchEntries_Running(t *testing.T) {
+	// The latest event is extended by heartbeats, the last one was sent a
+	// minute ago
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if r.URL.Path == activitywatch.PathBuckets {
+			err = json.NewEncoder(w).Encode(map[string]activitywatch.Bucket{
+				"aw-watcher-window_laptop": {ID: "aw-watcher-window_laptop", Type: activitywatch.BucketTypeWindow, Hostname: "laptop"},
+			})
+		} else {
+			err = json.NewEncoder(w).Encode([]activitywatch.Event{
+				newEvent(1, start, 30*time.Minute, activitywatch.EventData{App: "Slack", Title: "Slack"}),
+				newEvent(2, start.Add(30*time.Minute), 29*time.Minute, activitywatch.EventData{App: "Code", Title: "ABC-1 main.go - minutes"}),
+			})
+		}
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	activityWatchClient, err := activitywatch.NewFetcher(newClientOpts(mockServer.URL))
+	require.Nil(t, err)
+
+	opts := &client.FetchOpts{
+		Start:          start.Add(-time.Hour),
+		End:            start.Add(2 * time.Hour),
+		RunningEntries: client.RunningEntriesUntilNow,
+	}
+
+	entries, err := activityWatchClient.FetchEntries(context.Background(), opts)
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, 2)
+	require.False(t, entries[0].Running)
+	require.True(t, entries[1].Running)
+	require.WithinDuration(t, time.Now(), entries[1].End, 5*time.Second)
+	require.WithinDuration(t, start.Add(time.Hour), entries[1].End, 5*time.Second)
+	require.Equal(t, entries[1].End.Sub(entries[1].Start).Round(time.Second), entries[1].BillableDuration)
+
+	opts.RunningEntries = client.RunningEntriesSkip
+	entries, err = activityWatchClient.FetchEntries(context.Background(), opts)
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, 1)
+	require.Equal(t, "Chatting", entries[0].Summary)
+
+	opts.RunningEntries = client.RunningEntriesError
+	_, err = activityWatchClient.FetchEntries(context.Background(), opts)
+	require.ErrorContains(t, err, client.ErrRunningEntry.Error())
+}
+
+func TestActivityWatchClient_FetchEntries_BucketNotFound(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(map[string]activitywatch.Bucket{
+			"aw-watcher-window_desktop": {ID: "aw-watcher-window_desktop", Type: activitywatch.BucketTypeWindow, Hostname: "desktop"},
+		})
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	activityWatchClient, err := activitywatch.NewFetcher(newClientOpts(mockServer.URL))
+	require.Nil(t, err)
+
+	_, err = activityWatchClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 2, 12, 0, 0, 0, time.UTC),
+	})
+	require.ErrorContains(t, err, activitywatch.ErrBucketNotFound.Error())
+}
+
+func TestNewFetcher_InvalidRule(t *testing.T) {
+	opts := newClientOpts("http://localhost:5600")
+	opts.Rules = []activitywatch.Rule{{Field: "class", Regex: ".*"}}
+
+	_, err := activitywatch.NewFetcher(opts)
+	require.ErrorContains(t, err, activitywatch.ErrInvalidRule.Error())
+}
@@ -0,0 +1,220 @@
+package activitywatch
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// BucketTypeWindow is the type of the buckets created by aw-watcher-window.
+	BucketTypeWindow string = "currentwindow"
+	// BucketTypeAFK is the type of the buckets created by aw-watcher-afk.
+	BucketTypeAFK string = "afkstatus"
+	// BucketTypeWeb is the type of the buckets created by aw-watcher-web.
+	BucketTypeWeb string = "web.tab.current"
+	// StatusNotAFK is the status of the AFK events when the user is active.
+	StatusNotAFK string = "not-afk"
+)
+
+// Bucket represents a bucket of events, created by a watcher.
+type Bucket struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Client   string `json:"client"`
+	Hostname string `json:"hostname"`
+}
+
+// EventData represents the data of the window, AFK, and web events. Only the
+// fields of the event's bucket type are set.
+type EventData struct {
+	App    string `json:"app"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
+}
+
+// Event represents an event of a bucket. The duration is in seconds.
+type Event struct {
+	ID        int       `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Duration  float64   `json:"duration"`
+	Data      EventData `json:"data"`
+}
+
+// End returns the end of the event.
+func (e *Event) End() time.Time {
+	return e.Timestamp.Add(time.Duration(e.Duration * float64(time.Second)))
+}
+
+// Activity represents the time spent in a window while the user was not AFK.
+// If the window is a browser tab recorded by the web watcher, the URL is set.
+type Activity struct {
+	// EventID is the ID of the window event the activity belongs to.
+	EventID int
+	Start   time.Time
+	End     time.Time
+	App     string
+	Title   string
+	URL     string
+}
+
+// Duration returns the duration of the activity.
+func (a *Activity) Duration() time.Duration {
+	return a.End.Sub(a.Start)
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// intersect returns the common part of the intervals, if any.
+func (i interval) intersect(other interval) (interval, bool) {
+	start, end := i.start, i.end
+
+	if other.start.After(start) {
+		start = other.start
+	}
+
+	if other.end.Before(end) {
+		end = other.end
+	}
+
+	return interval{start: start, end: end}, start.Before(end)
+}
+
+// activeIntervals returns the intervals of the date range when the user was not
+// AFK, merging the overlapping events. If the AFK events are nil, the whole
+// date range is active.
+func activeIntervals(afkEvents []Event, dateRange interval) []interval {
+	if afkEvents == nil {
+		return []interval{dateRange}
+	}
+
+	var intervals []interval
+	for _, event := range afkEvents {
+		if event.Data.Status != StatusNotAFK {
+			continue
+		}
+
+		if active, ok := dateRange.intersect(interval{start: event.Timestamp, end: event.End()}); ok {
+			intervals = append(intervals, active)
+		}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	var merged []interval
+	for _, active := range intervals {
+		if last := len(merged) - 1; last >= 0 && !active.start.After(merged[last].end) {
+			if active.end.After(merged[last].end) {
+				merged[last].end = active.end
+			}
+			continue
+		}
+
+		merged = append(merged, active)
+	}
+
+	return merged
+}
+
+// isWebEventOf returns true if the web event belongs to the window. Browsers
+// show the title of the tab in the window title, so the web events are matched
+// by their title instead of maintaining the list of browser applications.
+func isWebEventOf(webEvent *Event, activity *Activity) bool {
+	return webEvent.Data.Title != "" && strings.Contains(activity.Title, webEvent.Data.Title)
+}
+
+// splitByWebEvents splits the activity at the boundaries of the web events
+// belonging to its window, and sets the URL of the parts spent on a tab.
+func splitByWebEvents(activity Activity, webEvents []Event) []Activity {
+	activityInterval := interval{start: activity.Start, end: activity.End}
+	bounds := []time.Time{activity.Start, activity.End}
+
+	var relatedEvents []Event
+	for _, event := range webEvents {
+		if !isWebEventOf(&event, &activity) {
+			continue
+		}
+
+		if _, ok := activityInterval.intersect(interval{start: event.Timestamp, end: event.End()}); !ok {
+			continue
+		}
+
+		relatedEvents = append(relatedEvents, event)
+		for _, bound := range []time.Time{event.Timestamp, event.End()} {
+			if bound.After(activity.Start) && bound.Before(activity.End) {
+				bounds = append(bounds, bound)
+			}
+		}
+	}
+
+	if len(relatedEvents) == 0 {
+		return []Activity{activity}
+	}
+
+	sort.Slice(bounds, func(i, j int) bool {
+		return bounds[i].Before(bounds[j])
+	})
+
+	var activities []Activity
+	for i := 1; i < len(bounds); i++ {
+		if !bounds[i-1].Before(bounds[i]) {
+			continue
+		}
+
+		part := activity
+		part.Start = bounds[i-1]
+		part.End = bounds[i]
+
+		for _, event := range relatedEvents {
+			if !event.Timestamp.After(part.Start) && !event.End().Before(part.End) {
+				part.URL = event.Data.URL
+				break
+			}
+		}
+
+		activities = append(activities, part)
+	}
+
+	return activities
+}
+
+// Activities returns the activities of the window events within the date range,
+// ordered by their start. The parts of the window events when the user was AFK
+// are excluded. If the AFK events are nil, the user is considered active all
+// the time. The web events set the URL of the browser windows.
+func Activities(windowEvents []Event, afkEvents []Event, webEvents []Event, start time.Time, end time.Time) []Activity {
+	dateRange := interval{start: start, end: end}
+	intervals := activeIntervals(afkEvents, dateRange)
+
+	var activities []Activity
+	for _, event := range windowEvents {
+		eventInterval := interval{start: event.Timestamp, end: event.End()}
+
+		for _, active := range intervals {
+			activeEventInterval, ok := eventInterval.intersect(active)
+			if !ok {
+				continue
+			}
+
+			activities = append(activities, splitByWebEvents(Activity{
+				EventID: event.ID,
+				Start:   activeEventInterval.start,
+				End:     activeEventInterval.end,
+				App:     event.Data.App,
+				Title:   event.Data.Title,
+			}, webEvents)...)
+		}
+	}
+
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].Start.Before(activities[j].Start)
+	})
+
+	return activities
+}
@@ -0,0 +1,91 @@
+package activitywatch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client/activitywatch"
+	"github.com/stretchr/testify/require"
+)
+
+func newEvent(id int, start time.Time, duration time.Duration, data activitywatch.EventData) activitywatch.Event {
+	return activitywatch.Event{
+		ID:        id,
+		Timestamp: start,
+		Duration:  duration.Seconds(),
+		Data:      data,
+	}
+}
+
+func TestActivities(t *testing.T) {
+	start := time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 2, 12, 0, 0, 0, time.UTC)
+
+	windowEvents := []activitywatch.Event{
+		// Started before the date range
+		newEvent(1, start.Add(-30*time.Minute), time.Hour, activitywatch.EventData{App: "code", Title: "main.go - minutes"}),
+		newEvent(2, start.Add(30*time.Minute), time.Hour, activitywatch.EventData{App: "firefox", Title: "ABC-1 Fix the bug - Jira - Mozilla Firefox"}),
+	}
+
+	afkEvents := []activitywatch.Event{
+		newEvent(1, start.Add(-time.Hour), 80*time.Minute, activitywatch.EventData{Status: activitywatch.StatusNotAFK}),
+		// Overlapping with the previous not-afk event
+		newEvent(2, start.Add(15*time.Minute), 10*time.Minute, activitywatch.EventData{Status: activitywatch.StatusNotAFK}),
+		newEvent(3, start.Add(25*time.Minute), 20*time.Minute, activitywatch.EventData{Status: "afk"}),
+		newEvent(4, start.Add(45*time.Minute), time.Hour, activitywatch.EventData{Status: activitywatch.StatusNotAFK}),
+	}
+
+	webEvents := []activitywatch.Event{
+		newEvent(1, start.Add(time.Hour), 10*time.Minute, activitywatch.EventData{Title: "ABC-1 Fix the bug - Jira", URL: "https://example.atlassian.net/browse/ABC-1"}),
+		// Belongs to an other browser window
+		newEvent(2, start.Add(70*time.Minute), 10*time.Minute, activitywatch.EventData{Title: "Inbox", URL: "https://mail.example.com"}),
+	}
+
+	require.Equal(t, []activitywatch.Activity{
+		{
+			EventID: 1,
+			Start:   start,
+			End:     start.Add(25 * time.Minute),
+			App:     "code",
+			Title:   "main.go - minutes",
+		},
+		{
+			EventID: 2,
+			Start:   start.Add(45 * time.Minute),
+			End:     start.Add(time.Hour),
+			App:     "firefox",
+			Title:   "ABC-1 Fix the bug - Jira - Mozilla Firefox",
+		},
+		{
+			EventID: 2,
+			Start:   start.Add(time.Hour),
+			End:     start.Add(70 * time.Minute),
+			App:     "firefox",
+			Title:   "ABC-1 Fix the bug - Jira - Mozilla Firefox",
+			URL:     "https://example.atlassian.net/browse/ABC-1",
+		},
+		{
+			EventID: 2,
+			Start:   start.Add(70 * time.Minute),
+			End:     start.Add(90 * time.Minute),
+			App:     "firefox",
+			Title:   "ABC-1 Fix the bug - Jira - Mozilla Firefox",
+		},
+	}, activitywatch.Activities(windowEvents, afkEvents, webEvents, start, end))
+}
+
+func TestActivities_NoAFKEvents(t *testing.T) {
+	start := time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 2, 12, 0, 0, 0, time.UTC)
+
+	windowEvents := []activitywatch.Event{
+		newEvent(1, start, time.Hour, activitywatch.EventData{App: "code", Title: "main.go - minutes"}),
+	}
+
+	activities := activitywatch.Activities(windowEvents, nil, nil, start, end)
+	require.Len(t, activities, 1)
+	require.Equal(t, time.Hour, activities[0].Duration())
+
+	// The AFK watcher was running, but the user was never active
+	require.Empty(t, activitywatch.Activities(windowEvents, []activitywatch.Event{}, nil, start, end))
+}
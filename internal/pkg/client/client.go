@@ -10,8 +10,6 @@ import (
 	"net/http"
 	netURL "net/url"
 	"os/exec"
-	"reflect"
-	"strconv"
 	"time"
 
 	"github.com/gabor-boros/minutes/internal/pkg/worklog"
@@ -43,6 +41,20 @@ type BaseClientOpts struct {
 	// while in the case of CLI based clients it will be applied on the command
 	// execution.
 	Timeout time.Duration
+	// Timezone sets the timezone used by the client to convert the dates
+	// having no offset information, like the dates sent as query params or the
+	// dates parsed from responses. If not set, the local timezone is used.
+	Timezone *time.Location
+}
+
+// Location returns the timezone of the client, defaulting to the local
+// timezone if no timezone is set.
+func (o *BaseClientOpts) Location() *time.Location {
+	if o.Timezone == nil {
+		return time.Local
+	}
+
+	return o.Timezone
 }
 
 // Authenticator is responsible for setting the necessary parameters for
@@ -178,88 +190,126 @@ func (c *HTTPClient) URL(path string, params map[string]string) (string, error)
 // Call fires an HTTP request with the given method and body (in its body) to
 // the API URL returned by the `URL` method.
 func (c *HTTPClient) Call(ctx context.Context, opts *HTTPRequestOpts) ([]byte, error) {
+	body, _, err := c.CallWithHeader(ctx, opts)
+	return body, err
+}
+
+// CallWithHeader is similar to Call, though it returns the header of the
+// response too, like the Link header used by LinkHeaderPagination.
+func (c *HTTPClient) CallWithHeader(ctx context.Context, opts *HTTPRequestOpts) ([]byte, http.Header, error) {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
 
 	req, err := c.newRequest(ctxWithTimeout, opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	resp, err := c.sendRequest(c.Client, req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, resp.Header, nil
 }
 
 // PaginatedFetch fetches the entries from the given paginated API.
 // I helps working with paginated APIs and gives a unified entrypoint
 // to fetch and parse entries.
-// TODO: Write separate unit tests
 func (c *HTTPClient) PaginatedFetch(ctx context.Context, opts *PaginatedFetchOpts) (worklog.Entries, error) {
-	var entries worklog.Entries
+	return CollectFetchPages(ctx, c.PaginatedFetchStream(ctx, opts))
+}
 
-	currentPage := 1
+// PaginatedFetchStream fetches the entries from the given paginated API in the
+// background, and sends the parsed entries page by page on the returned
+// channel. The pages are navigated using the Pagination of the options. The
+// fetching stops when the context is done.
+func (c *HTTPClient) PaginatedFetchStream(ctx context.Context, opts *PaginatedFetchOpts) <-chan FetchPage {
+	pages := make(chan FetchPage)
 
 	pageSize := opts.PageSize
 	if pageSize <= 0 {
 		pageSize = DefaultPageSize
 	}
 
-	pageSizeParam := opts.PageSizeParam
-	if pageSizeParam == "" {
-		pageSizeParam = DefaultPageSizeParam
+	pagination := opts.Pagination
+	if pagination == nil {
+		pagination = &PageNumberPagination{}
 	}
 
-	pageParam := opts.PageParam
-	if pageParam == "" {
-		pageParam = DefaultPageParam
+	// send returns false if the page could not be sent as the context is done
+	send := func(page FetchPage) bool {
+		select {
+		case pages <- page:
+			return true
+		case <-ctx.Done():
+			return false
+		}
 	}
 
-	for {
-		url, err := c.URL(opts.URL, map[string]string{
-			pageParam:     strconv.Itoa(currentPage),
-			pageSizeParam: strconv.Itoa(pageSize),
-		})
+	go func() {
+		defer close(pages)
 
+		apiURL, err := c.URL(opts.URL, map[string]string{})
 		if err != nil {
-			return nil, fmt.Errorf("%v: %v", ErrFetchEntries, err)
+			send(FetchPage{Page: 1, Err: fmt.Errorf("%v: %v", ErrFetchEntries, err)})
+			return
 		}
 
-		rawEntries, paginatedResponse, err := opts.FetchFunc(ctx, url)
+		pageURL, err := pagination.FirstPage(apiURL, pageSize)
 		if err != nil {
-			return nil, fmt.Errorf("%v: %v", ErrFetchEntries, err)
+			send(FetchPage{Page: 1, Err: fmt.Errorf("%v: %v", ErrFetchEntries, err)})
+			return
 		}
 
-		// No entries were returned, no need to parse entries
-		if reflect.ValueOf(rawEntries).Len() == 0 {
-			break
-		}
+		for currentPage := 1; pageURL != "" && ctx.Err() == nil; currentPage++ {
+			rawEntries, paginatedResponse, err := opts.FetchFunc(ctx, pageURL)
+			if err != nil {
+				send(FetchPage{Page: currentPage, Err: fmt.Errorf("%v: %v", ErrFetchEntries, err)})
+				return
+			}
 
-		parsedEntries, err := opts.ParseFunc(rawEntries, opts.BaseFetchOpts)
-		if err != nil {
-			return nil, fmt.Errorf("%v: %v", ErrFetchEntries, err)
-		}
+			// No entries were returned, no need to parse entries
+			if paginatedResponse.PageEntries == 0 {
+				return
+			}
 
-		entries = append(entries, parsedEntries...)
+			parsedEntries, err := opts.ParseFunc(rawEntries, opts.BaseFetchOpts)
+			if err != nil {
+				send(FetchPage{Page: currentPage, Err: fmt.Errorf("%v: %v", ErrFetchEntries, err)})
+				return
+			}
 
-		if paginatedResponse.EntriesPerPage > 0 {
-			pageSize = paginatedResponse.EntriesPerPage
-		}
+			if paginatedResponse.EntriesPerPage > 0 {
+				pageSize = paginatedResponse.EntriesPerPage
+			}
 
-		// If the number of entries known, break the loop if all entries are fetched
-		if paginatedResponse.TotalEntries > 0 {
-			if paginatedResponse.TotalEntries-pageSize*currentPage <= 0 {
-				break
+			// If the number of entries known, the number of pages is known too
+			totalPages := 0
+			if paginatedResponse.TotalEntries > 0 {
+				totalPages = (paginatedResponse.TotalEntries + pageSize - 1) / pageSize
 			}
-		}
 
-		currentPage++
-	}
+			if !send(FetchPage{Page: currentPage, TotalPages: totalPages, Entries: parsedEntries}) {
+				return
+			}
+
+			pageURL, err = pagination.NextPage(pageURL, pageSize, paginatedResponse)
+			if err != nil {
+				send(FetchPage{Page: currentPage + 1, Err: fmt.Errorf("%v: %v", ErrFetchEntries, err)})
+				return
+			}
+		}
+	}()
 
-	return entries, nil
+	return pages
 }
 
 func (c *HTTPClient) newRequest(ctx context.Context, opts *HTTPRequestOpts) (*http.Request, error) {
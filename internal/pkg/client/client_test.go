@@ -3,6 +3,7 @@ package client_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,8 +13,10 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -499,3 +502,206 @@ func TestHTTPClient_Call_Failure(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+type mockPaginatedAPI struct {
+	totalEntries  int
+	reportTotal   bool
+	requestedURLs []string
+}
+
+func (a *mockPaginatedAPI) fetch(_ context.Context, reqURL string) (interface{}, *client.PaginatedFetchResponse, error) {
+	a.requestedURLs = append(a.requestedURLs, reqURL)
+
+	parsedURL, err := url.Parse(reqURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page, _ := strconv.Atoi(parsedURL.Query().Get(client.DefaultPageParam))
+	pageSize, _ := strconv.Atoi(parsedURL.Query().Get(client.DefaultPageSizeParam))
+
+	var ids []string
+	for id := (page - 1) * pageSize; id < page*pageSize && id < a.totalEntries; id++ {
+		ids = append(ids, strconv.Itoa(id))
+	}
+
+	paginatedResponse := &client.PaginatedFetchResponse{
+		PageEntries: len(ids),
+	}
+
+	if a.reportTotal {
+		paginatedResponse.TotalEntries = a.totalEntries
+	}
+
+	return ids, paginatedResponse, nil
+}
+
+func (a *mockPaginatedAPI) parse(rawEntries interface{}, _ *client.FetchOpts) (worklog.Entries, error) {
+	var entries worklog.Entries
+
+	for _, id := range rawEntries.([]string) {
+		entries = append(entries, worklog.Entry{Source: "mock", SourceID: id})
+	}
+
+	return entries, nil
+}
+
+func (a *mockPaginatedAPI) fetchOpts() *client.PaginatedFetchOpts {
+	return &client.PaginatedFetchOpts{
+		BaseFetchOpts: &client.FetchOpts{},
+		URL:           "/entries",
+		PageSize:      2,
+		FetchFunc:     a.fetch,
+		ParseFunc:     a.parse,
+	}
+}
+
+func getPaginatedTestClient(t *testing.T) *client.HTTPClient {
+	baseURL, err := url.Parse("https://example.com")
+	require.Nil(t, err)
+
+	return &client.HTTPClient{
+		BaseURL: baseURL,
+	}
+}
+
+func TestHTTPClient_PaginatedFetchStream(t *testing.T) {
+	api := &mockPaginatedAPI{totalEntries: 5, reportTotal: true}
+	httpClient := getPaginatedTestClient(t)
+
+	var pages []client.FetchPage
+	for page := range httpClient.PaginatedFetchStream(context.Background(), api.fetchOpts()) {
+		pages = append(pages, page)
+	}
+
+	require.Equal(t, []client.FetchPage{
+		{Page: 1, TotalPages: 3, Entries: worklog.Entries{{Source: "mock", SourceID: "0"}, {Source: "mock", SourceID: "1"}}},
+		{Page: 2, TotalPages: 3, Entries: worklog.Entries{{Source: "mock", SourceID: "2"}, {Source: "mock", SourceID: "3"}}},
+		{Page: 3, TotalPages: 3, Entries: worklog.Entries{{Source: "mock", SourceID: "4"}}},
+	}, pages)
+
+	// No extra request should be made when the total is known
+	require.Len(t, api.requestedURLs, 3)
+}
+
+func TestHTTPClient_PaginatedFetchStream_UnknownTotal(t *testing.T) {
+	api := &mockPaginatedAPI{totalEntries: 3}
+	httpClient := getPaginatedTestClient(t)
+
+	var pages []client.FetchPage
+	for page := range httpClient.PaginatedFetchStream(context.Background(), api.fetchOpts()) {
+		pages = append(pages, page)
+	}
+
+	require.Equal(t, []client.FetchPage{
+		{Page: 1, Entries: worklog.Entries{{Source: "mock", SourceID: "0"}, {Source: "mock", SourceID: "1"}}},
+		{Page: 2, Entries: worklog.Entries{{Source: "mock", SourceID: "2"}}},
+	}, pages)
+
+	// The short page ends the fetching, no need to fetch an empty page
+	require.Len(t, api.requestedURLs, 2)
+}
+
+func TestHTTPClient_PaginatedFetchStream_Cancel(t *testing.T) {
+	api := &mockPaginatedAPI{totalEntries: 100, reportTotal: true}
+	httpClient := getPaginatedTestClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pages := httpClient.PaginatedFetchStream(ctx, api.fetchOpts())
+
+	page := <-pages
+	require.Equal(t, 1, page.Page)
+	cancel()
+
+	// The stream must be closed after the cancellation, though a page fetched
+	// before the cancellation may be received
+	for range pages {
+	}
+
+	require.Less(t, len(api.requestedURLs), 50)
+}
+
+func TestHTTPClient_PaginatedFetchStream_Error(t *testing.T) {
+	httpClient := getPaginatedTestClient(t)
+	opts := &client.PaginatedFetchOpts{
+		BaseFetchOpts: &client.FetchOpts{},
+		URL:           "/entries",
+		FetchFunc: func(_ context.Context, _ string) (interface{}, *client.PaginatedFetchResponse, error) {
+			return nil, nil, errors.New("500: internal server error")
+		},
+	}
+
+	var pages []client.FetchPage
+	for page := range httpClient.PaginatedFetchStream(context.Background(), opts) {
+		pages = append(pages, page)
+	}
+
+	require.Len(t, pages, 1)
+	require.EqualError(t, pages[0].Err, "failed to fetch entries: 500: internal server error")
+}
+
+func TestHTTPClient_PaginatedFetch(t *testing.T) {
+	api := &mockPaginatedAPI{totalEntries: 3, reportTotal: true}
+	httpClient := getPaginatedTestClient(t)
+
+	entries, err := httpClient.PaginatedFetch(context.Background(), api.fetchOpts())
+	require.Nil(t, err)
+	require.Equal(t, worklog.Entries{
+		{Source: "mock", SourceID: "0"},
+		{Source: "mock", SourceID: "1"},
+		{Source: "mock", SourceID: "2"},
+	}, entries)
+}
+
+func TestCollectFetchPages_Cancelled(t *testing.T) {
+	api := &mockPaginatedAPI{totalEntries: 3, reportTotal: true}
+	httpClient := getPaginatedTestClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	entries, err := client.CollectFetchPages(ctx, httpClient.PaginatedFetchStream(ctx, api.fetchOpts()))
+	require.Nil(t, entries)
+	require.EqualError(t, err, "failed to fetch entries: context canceled")
+}
+
+func TestFailedFetchStream(t *testing.T) {
+	entries, err := client.CollectFetchPages(context.Background(), client.FailedFetchStream(errors.New("failed to fetch entries: invalid URL")))
+	require.Nil(t, entries)
+	require.EqualError(t, err, "failed to fetch entries: invalid URL")
+}
+
+func TestFetchOpts_SkipsRunningEntries(t *testing.T) {
+	require.True(t, (&client.FetchOpts{}).SkipsRunningEntries())
+	require.True(t, (&client.FetchOpts{RunningEntries: client.RunningEntriesSkip}).SkipsRunningEntries())
+	require.False(t, (&client.FetchOpts{RunningEntries: client.RunningEntriesUntilNow}).SkipsRunningEntries())
+	require.False(t, (&client.FetchOpts{RunningEntries: client.RunningEntriesError}).SkipsRunningEntries())
+}
+
+func TestFetchOpts_RunningEntryEnd(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+
+	end, keep, err := (&client.FetchOpts{}).RunningEntryEnd(start)
+	require.Nil(t, err)
+	require.False(t, keep)
+	require.True(t, end.IsZero())
+
+	end, keep, err = (&client.FetchOpts{RunningEntries: client.RunningEntriesUntilNow}).RunningEntryEnd(start)
+	require.Nil(t, err)
+	require.True(t, keep)
+	require.WithinDuration(t, time.Now(), end, time.Second)
+
+	end, keep, err = (&client.FetchOpts{RunningEntries: client.RunningEntriesError}).RunningEntryEnd(start)
+	require.ErrorContains(t, err, client.ErrRunningEntry.Error())
+	require.False(t, keep)
+	require.True(t, end.IsZero())
+}
+
+func TestFetchOpts_RunningEntryEnd_StartedInFuture(t *testing.T) {
+	start := time.Now().Add(time.Hour)
+
+	end, keep, err := (&client.FetchOpts{RunningEntries: client.RunningEntriesUntilNow}).RunningEntryEnd(start)
+	require.Nil(t, err)
+	require.True(t, keep)
+	require.Equal(t, start, end)
+}
@@ -16,6 +16,8 @@ import (
 )
 
 const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "clockify"
 	// PathWorklog is the API endpoint used to search and create worklogs.
 	PathWorklog string = "/api/v1/workspaces/%s/user/%s/time-entries"
 )
@@ -35,6 +37,7 @@ type Interval struct {
 
 // FetchEntry represents the entry fetched from Clockify.
 type FetchEntry struct {
+	ID           string                `json:"id"`
 	Description  string                `json:"description"`
 	Billable     bool                  `json:"billable"`
 	Project      Project               `json:"project"`
@@ -71,6 +74,7 @@ type clockifyClient struct {
 }
 
 func (c *clockifyClient) parseEntries(rawEntries interface{}, opts *client.FetchOpts) (worklog.Entries, error) {
+	var err error
 	var entries worklog.Entries
 
 	fetchedEntries, ok := rawEntries.([]FetchEntry)
@@ -79,7 +83,24 @@ func (c *clockifyClient) parseEntries(rawEntries interface{}, opts *client.Fetch
 	}
 
 	for _, entry := range fetchedEntries {
-		billableDuration := entry.TimeInterval.End.Sub(entry.TimeInterval.Start)
+		// The API returns the dates in UTC, so they are converted to the
+		// timezone set for the API
+		start := entry.TimeInterval.Start.In(c.Location())
+
+		// The running entries have no end
+		end := entry.TimeInterval.End.In(c.Location())
+		running := entry.TimeInterval.End.IsZero()
+
+		if running {
+			var keep bool
+			if end, keep, err = opts.RunningEntryEnd(start); err != nil {
+				return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+			} else if !keep {
+				continue
+			}
+		}
+
+		billableDuration := end.Sub(start)
 		unbillableDuration := time.Duration(0)
 
 		if !entry.Billable {
@@ -102,9 +123,14 @@ func (c *clockifyClient) parseEntries(rawEntries interface{}, opts *client.Fetch
 			},
 			Summary:            entry.Task.Name,
 			Notes:              entry.Description,
-			Start:              entry.TimeInterval.Start,
+			Tags:               entry.Tags,
+			Start:              start,
+			End:                end,
 			BillableDuration:   billableDuration,
 			UnbillableDuration: unbillableDuration,
+			Source:             SourceName,
+			SourceID:           entry.ID,
+			Running:            running,
 		}
 
 		// If the entry's summary is empty, but we have notes, let's use notes for summary too
@@ -141,30 +167,46 @@ func (c *clockifyClient) fetchEntries(ctx context.Context, reqURL string) (inter
 		return nil, nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
 	}
 
-	return fetchedEntries, &client.PaginatedFetchResponse{}, err
+	paginatedResponse := &client.PaginatedFetchResponse{
+		PageEntries: len(fetchedEntries),
+	}
+
+	return fetchedEntries, paginatedResponse, err
 }
 
-func (c *clockifyClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
-	fetchURL, err := c.URL(fmt.Sprintf(PathWorklog, c.workspace, opts.User), map[string]string{
-		"start":       utils.DateFormatRFC3339UTC.Format(opts.Start.Local()),
-		"end":         utils.DateFormatRFC3339UTC.Format(opts.End.Local()),
-		"hydrated":    strconv.FormatBool(true),
-		"in-progress": strconv.FormatBool(false),
-	})
+func (c *clockifyClient) FetchEntriesStream(ctx context.Context, opts *client.FetchOpts) <-chan client.FetchPage {
+	params := map[string]string{
+		"start":    utils.DateFormatRFC3339UTC.Format(opts.Start),
+		"end":      utils.DateFormatRFC3339UTC.Format(opts.End),
+		"hydrated": strconv.FormatBool(true),
+	}
+
+	// The running entries are needed unless they are skipped anyway
+	if opts.SkipsRunningEntries() {
+		params["in-progress"] = strconv.FormatBool(false)
+	}
+
+	fetchURL, err := c.URL(fmt.Sprintf(PathWorklog, c.workspace, opts.User), params)
 
 	if err != nil {
-		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		return client.FailedFetchStream(fmt.Errorf("%v: %v", client.ErrFetchEntries, err))
 	}
 
-	return c.PaginatedFetch(ctx, &client.PaginatedFetchOpts{
+	return c.PaginatedFetchStream(ctx, &client.PaginatedFetchOpts{
 		BaseFetchOpts: opts,
 		URL:           fetchURL,
-		PageSizeParam: "page-size",
-		FetchFunc:     c.fetchEntries,
-		ParseFunc:     c.parseEntries,
+		Pagination: &client.PageNumberPagination{
+			PageSizeParam: "page-size",
+		},
+		FetchFunc: c.fetchEntries,
+		ParseFunc: c.parseEntries,
 	})
 }
 
+func (c *clockifyClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	return client.CollectFetchPages(ctx, c.FetchEntriesStream(ctx, opts))
+}
+
 // NewFetcher returns a new Clockify client for fetching entries.
 func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
 	baseURL, err := url.Parse(opts.BaseURL)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"testing"
 	"time"
@@ -77,11 +78,28 @@ func TestClockifyClient_FetchEntries(t *testing.T) {
 				ID:   "789",
 				Name: "Meet with Iron Man",
 			},
-			Summary:            "Meet with Iron Man",
-			Notes:              "Have a coffee with Tony",
+			Summary: "Meet with Iron Man",
+			Notes:   "Have a coffee with Tony",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "1234",
+					Name: "Coffee",
+				},
+				{
+					ID:   "5678",
+					Name: "Meeting",
+				},
+				{
+					ID:   "9876",
+					Name: "TASK-1234",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   end.Sub(start),
 			UnbillableDuration: 0,
+			Source:             clockify.SourceName,
+			SourceID:           "coffee-id",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -96,11 +114,32 @@ func TestClockifyClient_FetchEntries(t *testing.T) {
 				ID:   "789",
 				Name: "Meet with Iron Man",
 			},
-			Summary:            "Meet with Iron Man",
-			Notes:              "Go back for my wallet",
+			Summary: "Meet with Iron Man",
+			Notes:   "Go back for my wallet",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "1234",
+					Name: "Coffee",
+				},
+				{
+					ID:   "5678",
+					Name: "Meeting",
+				},
+				{
+					ID:   "9876",
+					Name: "TASK-1234",
+				},
+				{
+					ID:   "5432",
+					Name: "TASK-5678",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start),
+			Source:             clockify.SourceName,
+			SourceID:           "wallet-id",
 		},
 	}
 
@@ -113,6 +152,7 @@ func TestClockifyClient_FetchEntries(t *testing.T) {
 		RemainingCalls: &remainingCalls,
 		ResponseData: &[]clockify.FetchEntry{
 			{
+				ID:          "coffee-id",
 				Description: "Have a coffee with Tony",
 				Billable:    true,
 				Project: clockify.Project{
@@ -147,6 +187,7 @@ func TestClockifyClient_FetchEntries(t *testing.T) {
 				},
 			},
 			{
+				ID:          "wallet-id",
 				Description: "Go back for my wallet",
 				Billable:    false,
 				Project: clockify.Project{
@@ -190,7 +231,8 @@ func TestClockifyClient_FetchEntries(t *testing.T) {
 
 	clockifyClient, err := clockify.NewFetcher(&clockify.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
 		},
 		TokenAuth: client.TokenAuth{
 			Header: "X-Api-Key",
@@ -231,11 +273,28 @@ func TestClockifyClient_FetchEntries_TagsAsTasks(t *testing.T) {
 				ID:   "9876",
 				Name: "TASK-1234",
 			},
-			Summary:            "Have a coffee with Tony",
-			Notes:              "Have a coffee with Tony",
+			Summary: "Have a coffee with Tony",
+			Notes:   "Have a coffee with Tony",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "1234",
+					Name: "Coffee",
+				},
+				{
+					ID:   "5678",
+					Name: "Meeting",
+				},
+				{
+					ID:   "9876",
+					Name: "TASK-1234",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   end.Sub(start),
 			UnbillableDuration: 0,
+			Source:             clockify.SourceName,
+			SourceID:           "coffee-id",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -250,11 +309,32 @@ func TestClockifyClient_FetchEntries_TagsAsTasks(t *testing.T) {
 				ID:   "9876",
 				Name: "TASK-1234",
 			},
-			Summary:            "Go back for my wallet",
-			Notes:              "Go back for my wallet",
+			Summary: "Go back for my wallet",
+			Notes:   "Go back for my wallet",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "1234",
+					Name: "Coffee",
+				},
+				{
+					ID:   "5678",
+					Name: "Meeting",
+				},
+				{
+					ID:   "9876",
+					Name: "TASK-1234",
+				},
+				{
+					ID:   "5432",
+					Name: "TASK-5678",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start) / 2,
+			Source:             clockify.SourceName,
+			SourceID:           "wallet-id",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -269,11 +349,32 @@ func TestClockifyClient_FetchEntries_TagsAsTasks(t *testing.T) {
 				ID:   "5432",
 				Name: "TASK-5678",
 			},
-			Summary:            "Go back for my wallet",
-			Notes:              "Go back for my wallet",
+			Summary: "Go back for my wallet",
+			Notes:   "Go back for my wallet",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "1234",
+					Name: "Coffee",
+				},
+				{
+					ID:   "5678",
+					Name: "Meeting",
+				},
+				{
+					ID:   "9876",
+					Name: "TASK-1234",
+				},
+				{
+					ID:   "5432",
+					Name: "TASK-5678",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start) / 2,
+			Source:             clockify.SourceName,
+			SourceID:           "wallet-id",
 		},
 	}
 
@@ -286,6 +387,7 @@ func TestClockifyClient_FetchEntries_TagsAsTasks(t *testing.T) {
 		RemainingCalls: &remainingCalls,
 		ResponseData: &[]clockify.FetchEntry{
 			{
+				ID:          "coffee-id",
 				Description: "Have a coffee with Tony",
 				Billable:    true,
 				Project: clockify.Project{
@@ -317,6 +419,7 @@ func TestClockifyClient_FetchEntries_TagsAsTasks(t *testing.T) {
 				},
 			},
 			{
+				ID:          "wallet-id",
 				Description: "Go back for my wallet",
 				Billable:    false,
 				Project: clockify.Project{
@@ -357,7 +460,8 @@ func TestClockifyClient_FetchEntries_TagsAsTasks(t *testing.T) {
 
 	clockifyClient, err := clockify.NewFetcher(&clockify.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
 		},
 		TokenAuth: client.TokenAuth{
 			Header: "X-Api-Key",
@@ -398,9 +502,13 @@ func TestClockifyClient_FetchEntries_TagsAsTasks_NoTags(t *testing.T) {
 			Task:               worklog.IDNameField{},
 			Summary:            "Have a coffee with Tony",
 			Notes:              "Have a coffee with Tony",
+			Tags:               []worklog.IDNameField{},
 			Start:              start,
+			End:                end,
 			BillableDuration:   end.Sub(start),
 			UnbillableDuration: 0,
+			Source:             clockify.SourceName,
+			SourceID:           "coffee-id",
 		},
 	}
 
@@ -413,6 +521,7 @@ func TestClockifyClient_FetchEntries_TagsAsTasks_NoTags(t *testing.T) {
 		RemainingCalls: &remainingCalls,
 		ResponseData: &[]clockify.FetchEntry{
 			{
+				ID:          "coffee-id",
 				Description: "Have a coffee with Tony",
 				Billable:    true,
 				Project: clockify.Project{
@@ -436,7 +545,8 @@ func TestClockifyClient_FetchEntries_TagsAsTasks_NoTags(t *testing.T) {
 
 	clockifyClient, err := clockify.NewFetcher(&clockify.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
 		},
 		TokenAuth: client.TokenAuth{
 			Header: "X-Api-Key",
@@ -458,3 +568,108 @@ func TestClockifyClient_FetchEntries_TagsAsTasks_NoTags(t *testing.T) {
 	require.Nil(t, err, "cannot fetch entries")
 	require.ElementsMatch(t, expectedEntries, entries, "fetched entries are not matching")
 }
+
+func TestClockifyClient_FetchEntries_RunningEntries(t *testing.T) {
+	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 2, 23, 59, 59, 0, time.UTC)
+	started := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	var queries []url.Values
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+
+		err := json.NewEncoder(w).Encode([]clockify.FetchEntry{
+			{
+				ID:          "running-entry",
+				Description: "Fighting with Thanos",
+				Billable:    true,
+				TimeInterval: clockify.Interval{
+					Start: started,
+				},
+			},
+		})
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	clockifyClient, err := clockify.NewFetcher(&clockify.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
+		},
+		TokenAuth: client.TokenAuth{
+			Header: "X-Api-Key",
+			Token:  "t-o-k-e-n",
+		},
+		BaseURL:   mockServer.URL,
+		Workspace: "marvel-studios",
+	})
+	require.Nil(t, err)
+
+	entries, err := clockifyClient.FetchEntries(context.Background(), &client.FetchOpts{
+		User:           "steve-rogers",
+		Start:          start,
+		End:            end,
+		RunningEntries: client.RunningEntriesUntilNow,
+	})
+
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].Running)
+	require.WithinDuration(t, time.Now(), entries[0].End, time.Second)
+	require.Equal(t, entries[0].End.Sub(started), entries[0].BillableDuration)
+
+	require.Len(t, queries, 1)
+	require.NotContains(t, queries[0], "in-progress")
+}
+
+func TestClockifyClient_FetchEntries_Timezone(t *testing.T) {
+	start := time.Date(2021, 10, 2, 20, 0, 0, 0, time.UTC)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.Nil(t, err)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode([]clockify.FetchEntry{
+			{
+				ID:          "evening-entry",
+				Description: "Fighting with Thanos",
+				Billable:    true,
+				TimeInterval: clockify.Interval{
+					Start: start,
+					End:   start.Add(time.Hour),
+				},
+			},
+		})
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	clockifyClient, err := clockify.NewFetcher(&clockify.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: tokyo,
+		},
+		TokenAuth: client.TokenAuth{
+			Header: "X-Api-Key",
+			Token:  "t-o-k-e-n",
+		},
+		BaseURL:   mockServer.URL,
+		Workspace: "marvel-studios",
+	})
+	require.Nil(t, err)
+
+	entries, err := clockifyClient.FetchEntries(context.Background(), &client.FetchOpts{
+		User:  "steve-rogers",
+		Start: start,
+		End:   start.Add(2 * time.Hour),
+	})
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, 1)
+
+	// The evening work in UTC is on the next day in Tokyo
+	require.Equal(t, tokyo, entries[0].Start.Location())
+	require.Equal(t, time.Date(2021, 10, 3, 5, 0, 0, 0, tokyo), entries[0].Start)
+	require.Equal(t, time.Date(2021, 10, 3, 6, 0, 0, 0, tokyo), entries[0].End)
+	require.Equal(t, time.Hour, entries[0].BillableDuration)
+}
@@ -0,0 +1,547 @@
+package csv
+
+import (
+	"context"
+	encodingCSV "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "csv"
+	// PathStdin is the path used to read the entries from the standard input.
+	PathStdin string = "-"
+	// PathStdout is the path used to write the entries to the standard output.
+	PathStdout string = client.PathStdout
+	// DefaultDateFormat is the default format of the date column.
+	DefaultDateFormat string = "2006-01-02"
+	// DefaultTimeFormat is the default format of the start and end columns.
+	DefaultTimeFormat string = "15:04"
+	// DefaultTagsSeparator is the default separator of the tags column.
+	DefaultTagsSeparator string = ","
+	// DefaultDayStart is the default offset from midnight when the first row
+	// of a day without start time starts.
+	DefaultDayStart time.Duration = 9 * time.Hour
+)
+
+var (
+	// ErrMissingColumn returns when a column of the mapping is not in the
+	// header of the file.
+	ErrMissingColumn = errors.New("missing column")
+	// ErrNoDuration returns when the duration of a row cannot be determined,
+	// as neither the end nor the duration is set.
+	ErrNoDuration = errors.New("no end or duration")
+	// ErrInvalidBillable returns when the billable flag cannot be parsed.
+	ErrInvalidBillable = errors.New("invalid billable flag")
+)
+
+// ColumnMapping maps the fields of an entry to the column names of the header.
+// Columns left empty are not read. Either the Start or the Date column must be
+// set, and either the End or the Duration column must be set.
+type ColumnMapping struct {
+	Date     string
+	Start    string
+	End      string
+	Duration string
+	Client   string
+	Project  string
+	Task     string
+	Summary  string
+	Notes    string
+	Billable string
+	Tags     string
+}
+
+// DefaultColumnMapping returns the mapping used when the header names are
+// matching the field names.
+func DefaultColumnMapping() ColumnMapping {
+	return ColumnMapping{
+		Date:     "date",
+		Start:    "start",
+		End:      "end",
+		Duration: "duration",
+		Client:   "client",
+		Project:  "project",
+		Task:     "task",
+		Summary:  "summary",
+		Notes:    "notes",
+		Billable: "billable",
+		Tags:     "tags",
+	}
+}
+
+// exportHeader is the header of the written files, following the schema of
+// client.ExportEntry.
+var exportHeader = []string{
+	"start", "end", "client_id", "client", "project_id", "project", "task_id",
+	"task", "summary", "notes", "comment", "tags", "billable_seconds",
+	"unbillable_seconds", "total_seconds", "source", "source_id", "user",
+}
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// Since the CSV files are read from and written to the disk, the HTTP related
+// options are not used by the client.
+type ClientOpts struct {
+	client.BaseClientOpts
+	// Path is the path of the CSV file. If set to PathStdin, the file is read
+	// from the standard input, or written to the standard output when used as
+	// a target.
+	Path string
+	// Columns maps the columns of the file to the fields of entries.
+	Columns ColumnMapping
+	// Delimiter separates the columns, defaults to comma.
+	Delimiter rune
+	// DateFormat is the format of the date column in Go style.
+	DateFormat string
+	// TimeFormat is the format of the start and end columns in Go style. When
+	// the date column is set, the start and end are times of the date.
+	TimeFormat string
+	// TagsSeparator separates the tags within the tags column.
+	TagsSeparator string
+	// DayStart is the offset from midnight when the first row of a day starts,
+	// if the rows have a date, but no start time. The rows of the day are
+	// placed one after the other, so they do not overlap.
+	DayStart time.Duration
+	// Stdin is read when the path is PathStdin, defaults to os.Stdin.
+	Stdin io.Reader
+	// Append indicates to append the uploaded entries to the existing file.
+	// The header is written only if the file is empty.
+	Append bool
+	// RotateMonthly indicates to write the uploaded entries to a file per
+	// month, like "worklog-2021-10.csv".
+	RotateMonthly bool
+	// Stdout is written when uploading to PathStdout, defaults to os.Stdout.
+	Stdout io.Writer
+}
+
+type csvClient struct {
+	*client.BaseClientOpts
+	*client.DefaultUploader
+	fileTarget    *client.FileTargetOpts
+	path          string
+	columns       ColumnMapping
+	delimiter     rune
+	dateFormat    string
+	timeFormat    string
+	tagsSeparator string
+	dayStart      time.Duration
+	stdin         io.Reader
+
+	// The file is read once, as the standard input cannot be read again when
+	// the fetching is split into windows
+	readOnce sync.Once
+	entries  worklog.Entries
+	readErr  error
+}
+
+// columnIndexes returns the index of the mapped columns in the header.
+func (c *csvClient) columnIndexes(header []string) (map[string]int, error) {
+	indexes := map[string]int{}
+
+	for i, name := range header {
+		indexes[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+
+	mapped := map[string]int{}
+	for _, column := range []string{
+		c.columns.Date, c.columns.Start, c.columns.End, c.columns.Duration,
+		c.columns.Client, c.columns.Project, c.columns.Task, c.columns.Summary,
+		c.columns.Notes, c.columns.Billable, c.columns.Tags,
+	} {
+		if column == "" {
+			continue
+		}
+
+		if index, ok := indexes[strings.ToLower(column)]; ok {
+			mapped[column] = index
+		}
+	}
+
+	if _, ok := mapped[c.columns.Start]; !ok {
+		if _, ok = mapped[c.columns.Date]; !ok {
+			return nil, fmt.Errorf("%v: %s or %s", ErrMissingColumn, c.columns.Date, c.columns.Start)
+		}
+	}
+
+	if _, ok := mapped[c.columns.End]; !ok {
+		if _, ok = mapped[c.columns.Duration]; !ok {
+			return nil, fmt.Errorf("%v: %s or %s", ErrMissingColumn, c.columns.End, c.columns.Duration)
+		}
+	}
+
+	return mapped, nil
+}
+
+// parseTime parses the start or end of the entry. If the date is set, the
+// value is treated as a time of that date.
+func (c *csvClient) parseTime(date string, value string) (time.Time, error) {
+	if date != "" {
+		return time.ParseInLocation(c.dateFormat+" "+c.timeFormat, date+" "+value, c.Location())
+	}
+
+	return time.ParseInLocation(c.timeFormat, value, c.Location())
+}
+
+// parseBillable parses the billable flag. Similarly to checkboxes, the empty
+// flag means the entry is unbillable.
+func parseBillable(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "x", "1", "billable":
+		return true, nil
+	case "false", "no", "n", "0", "unbillable", "":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%v: %s", ErrInvalidBillable, value)
+	}
+}
+
+// parseRecord returns the entry of the row. If the row has a date, but no start
+// time, the entry starts at midnight and the returned hasStart is false.
+func (c *csvClient) parseRecord(record []string, indexes map[string]int) (entry worklog.Entry, hasStart bool, err error) {
+
+	get := func(column string) string {
+		index, ok := indexes[column]
+		if column == "" || !ok || index >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[index])
+	}
+
+	date := get(c.columns.Date)
+	start := get(c.columns.Start)
+
+	if hasStart = start != ""; hasStart {
+		if entry.Start, err = c.parseTime(date, start); err != nil {
+			return entry, hasStart, err
+		}
+	} else if entry.Start, err = time.ParseInLocation(c.dateFormat, date, c.Location()); err != nil {
+		return entry, hasStart, err
+	}
+
+	var duration time.Duration
+
+	if end := get(c.columns.End); end != "" {
+		if entry.End, err = c.parseTime(date, end); err != nil {
+			return entry, hasStart, err
+		}
+
+		// The entry was tracked over midnight
+		if date != "" && entry.End.Before(entry.Start) {
+			entry.End = entry.End.AddDate(0, 0, 1)
+		}
+
+		duration = entry.End.Sub(entry.Start)
+	}
+
+	if rawDuration := get(c.columns.Duration); rawDuration != "" {
+		if duration, err = utils.ParseDuration(rawDuration); err != nil {
+			return entry, hasStart, err
+		}
+	} else if entry.End.IsZero() {
+		return entry, hasStart, ErrNoDuration
+	}
+
+	// Without a billable column every entry is billable
+	billable := true
+	if _, ok := indexes[c.columns.Billable]; ok {
+		if billable, err = parseBillable(get(c.columns.Billable)); err != nil {
+			return entry, hasStart, err
+		}
+	}
+
+	if billable {
+		entry.BillableDuration = duration
+	} else {
+		entry.UnbillableDuration = duration
+	}
+
+	for _, field := range []struct {
+		target *worklog.IDNameField
+		column string
+	}{
+		{&entry.Client, c.columns.Client},
+		{&entry.Project, c.columns.Project},
+		{&entry.Task, c.columns.Task},
+	} {
+		value := get(field.column)
+		*field.target = worklog.IDNameField{ID: value, Name: value}
+	}
+
+	entry.Summary = get(c.columns.Summary)
+	entry.Notes = get(c.columns.Notes)
+
+	// Fall back to each other if only one of them is set
+	if entry.Summary == "" {
+		entry.Summary = entry.Notes
+	} else if entry.Notes == "" {
+		entry.Notes = entry.Summary
+	}
+
+	if rawTags := get(c.columns.Tags); rawTags != "" {
+		for _, tag := range strings.Split(rawTags, c.tagsSeparator) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				entry.Tags = append(entry.Tags, worklog.IDNameField{ID: tag, Name: tag})
+			}
+		}
+	}
+
+	return entry, hasStart, nil
+}
+
+// scheduleEntries sets the start and end of the entries having no start time.
+// The entries of a day are placed one after the other from the start of the
+// day, in the order of the rows.
+func (c *csvClient) scheduleEntries(entries []*worklog.Entry) {
+	next := make(map[time.Time]time.Time)
+
+	for _, entry := range entries {
+		day := entry.Start
+		if _, ok := next[day]; !ok {
+			next[day] = day.Add(c.dayStart)
+		}
+
+		entry.Start = next[day]
+		entry.End = entry.Start.Add(entry.BillableDuration + entry.UnbillableDuration)
+		next[day] = entry.End
+	}
+}
+
+// readEntries reads and parses the rows of the CSV file. The line number of the
+// row is used as the source ID of the entry.
+func (c *csvClient) readEntries() (worklog.Entries, error) {
+	reader := c.stdin
+
+	if c.path != PathStdin {
+		file, err := os.Open(filepath.Clean(c.path))
+		if err != nil {
+			return nil, err
+		}
+
+		defer file.Close()
+		reader = file
+	}
+
+	csvReader := encodingCSV.NewReader(reader)
+	csvReader.Comma = c.delimiter
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := c.columnIndexes(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries worklog.Entries
+	var unscheduled []int
+
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		line, _ := csvReader.FieldPos(0)
+
+		// Skip the empty rows spreadsheets tend to leave at the end
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		entry, hasStart, err := c.parseRecord(record, indexes)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		entry.Source = SourceName
+		entry.SourceID = strconv.Itoa(line)
+
+		if !hasStart {
+			unscheduled = append(unscheduled, len(entries))
+		}
+
+		entries = append(entries, entry)
+	}
+
+	unscheduledEntries := make([]*worklog.Entry, len(unscheduled))
+	for i, index := range unscheduled {
+		unscheduledEntries[i] = &entries[index]
+	}
+
+	c.scheduleEntries(unscheduledEntries)
+
+	return entries, nil
+}
+
+func (c *csvClient) FetchEntries(_ context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	c.readOnce.Do(func() {
+		c.entries, c.readErr = c.readEntries()
+	})
+
+	if c.readErr != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, c.readErr)
+	}
+
+	var entries worklog.Entries
+
+	for _, entry := range c.entries {
+		if entry.Start.Before(opts.Start) || !entry.Start.Before(opts.End) {
+			continue
+		}
+
+		if utils.IsRegexSet(opts.TagsAsTasksRegex) && len(entry.Tags) > 0 {
+			entries = append(entries, entry.SplitByTagsAsTasks(entry.Summary, opts.TagsAsTasksRegex, entry.Tags)...)
+		} else {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// exportRecord returns the row of the entry written to the file.
+func (c *csvClient) exportRecord(entry client.ExportEntry) []string {
+	return []string{
+		entry.Start,
+		entry.End,
+		entry.ClientID,
+		entry.Client,
+		entry.ProjectID,
+		entry.Project,
+		entry.TaskID,
+		entry.Task,
+		entry.Summary,
+		entry.Notes,
+		entry.Comment,
+		strings.Join(entry.Tags, c.tagsSeparator),
+		strconv.Itoa(entry.BillableSeconds),
+		strconv.Itoa(entry.UnbillableSeconds),
+		strconv.Itoa(entry.TotalSeconds),
+		entry.Source,
+		entry.SourceID,
+		entry.User,
+	}
+}
+
+// writeFile writes the entries to the file. The header is omitted when the
+// entries are appended to a non-empty file.
+func (c *csvClient) writeFile(path string, entries []client.ExportEntry) error {
+	writeHeader := true
+	if c.fileTarget.Append && path != PathStdout {
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			writeHeader = false
+		}
+	}
+
+	file, err := c.fileTarget.OpenFile(path)
+	if err != nil {
+		return err
+	}
+
+	csvWriter := encodingCSV.NewWriter(file)
+	csvWriter.Comma = c.delimiter
+
+	if writeHeader {
+		_ = csvWriter.Write(exportHeader)
+	}
+
+	for _, entry := range entries {
+		_ = csvWriter.Write(c.exportRecord(entry))
+	}
+
+	// The write errors are returned by Error after flushing
+	csvWriter.Flush()
+	if err = csvWriter.Error(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (c *csvClient) UploadEntries(_ context.Context, entries worklog.Entries, errChan chan error, opts *client.UploadOpts) {
+	c.UploadFiles(entries, errChan, opts, c.fileTarget, c.Location(), c.writeFile)
+}
+
+func newClient(opts *ClientOpts) *csvClient {
+	delimiter := opts.Delimiter
+	if delimiter == 0 {
+		delimiter = ','
+	}
+
+	dateFormat := opts.DateFormat
+	if dateFormat == "" {
+		dateFormat = DefaultDateFormat
+	}
+
+	timeFormat := opts.TimeFormat
+	if timeFormat == "" {
+		timeFormat = DefaultTimeFormat
+	}
+
+	tagsSeparator := opts.TagsSeparator
+	if tagsSeparator == "" {
+		tagsSeparator = DefaultTagsSeparator
+	}
+
+	stdin := opts.Stdin
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+
+	return &csvClient{
+		BaseClientOpts:  &opts.BaseClientOpts,
+		DefaultUploader: &client.DefaultUploader{},
+		fileTarget: &client.FileTargetOpts{
+			Path:          opts.Path,
+			Append:        opts.Append,
+			RotateMonthly: opts.RotateMonthly,
+			Stdout:        opts.Stdout,
+		},
+		path:          opts.Path,
+		columns:       opts.Columns,
+		delimiter:     delimiter,
+		dateFormat:    dateFormat,
+		timeFormat:    timeFormat,
+		tagsSeparator: tagsSeparator,
+		dayStart:      opts.DayStart,
+		stdin:         stdin,
+	}
+}
+
+// NewFetcher returns a new CSV client for fetching entries.
+func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("%v: %s", client.ErrFetchEntries, "no path set")
+	}
+
+	return newClient(opts), nil
+}
+
+// NewUploader returns a new CSV client for writing entries to a CSV file.
+func NewUploader(opts *ClientOpts) (client.Uploader, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("%v: %s", client.ErrUploadEntries, "no path set")
+	}
+
+	return newClient(opts), nil
+}
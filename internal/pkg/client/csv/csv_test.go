@@ -0,0 +1,384 @@
+package csv_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/csv"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCSVFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "entries.csv")
+
+	err := os.WriteFile(path, []byte(content), 0600)
+	require.Nil(t, err)
+
+	return path
+}
+
+func newFetchOpts(start time.Time, end time.Time) *client.FetchOpts {
+	return &client.FetchOpts{
+		Start: start,
+		End:   end,
+	}
+}
+
+func TestCSVClient_FetchEntries(t *testing.T) {
+	path := writeCSVFile(t, strings.Join([]string{
+		"Date,Start,End,Client,Project,Task,Summary,Notes,Billable",
+		"2021-10-12,09:00,10:30,My Awesome Company,Internal,TASK-123,Meeting,,yes",
+		"2021-10-12,23:00,01:00,My Awesome Company,Internal,TASK-456,Deployment,Late deploy,no",
+		",,,,,,,,",
+		"2021-10-20,09:00,10:00,My Awesome Company,Internal,TASK-789,Out of range,,yes",
+	}, "\n"))
+
+	csvClient, err := csv.NewFetcher(&csv.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:    path,
+		Columns: csv.DefaultColumnMapping(),
+	})
+	require.Nil(t, err)
+
+	entries, err := csvClient.FetchEntries(context.Background(), newFetchOpts(
+		time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+	))
+
+	require.Nil(t, err)
+	require.Equal(t, worklog.Entries{
+		{
+			Client:           worklog.IDNameField{ID: "My Awesome Company", Name: "My Awesome Company"},
+			Project:          worklog.IDNameField{ID: "Internal", Name: "Internal"},
+			Task:             worklog.IDNameField{ID: "TASK-123", Name: "TASK-123"},
+			Summary:          "Meeting",
+			Notes:            "Meeting",
+			Start:            time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 10, 30, 0, 0, time.UTC),
+			BillableDuration: 90 * time.Minute,
+			Source:           csv.SourceName,
+			SourceID:         "2",
+		},
+		{
+			Client:             worklog.IDNameField{ID: "My Awesome Company", Name: "My Awesome Company"},
+			Project:            worklog.IDNameField{ID: "Internal", Name: "Internal"},
+			Task:               worklog.IDNameField{ID: "TASK-456", Name: "TASK-456"},
+			Summary:            "Deployment",
+			Notes:              "Late deploy",
+			Start:              time.Date(2021, 10, 12, 23, 0, 0, 0, time.UTC),
+			End:                time.Date(2021, 10, 13, 1, 0, 0, 0, time.UTC),
+			UnbillableDuration: 2 * time.Hour,
+			Source:             csv.SourceName,
+			SourceID:           "3",
+		},
+	}, entries)
+}
+
+func TestCSVClient_FetchEntries_CustomColumns(t *testing.T) {
+	path := writeCSVFile(t, strings.Join([]string{
+		"Day;Hours;Ticket;Description;Labels",
+		"12.10.2021;1,5;TASK-123;Meeting;meeting|TASK-123",
+		"12.10.2021;0:45;;Code review;",
+		"12.10.2021;1h15m;;Planning;",
+	}, "\n"))
+
+	csvClient, err := csv.NewFetcher(&csv.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path: path,
+		Columns: csv.ColumnMapping{
+			Date:     "day",
+			Duration: "hours",
+			Task:     "ticket",
+			Summary:  "description",
+			Tags:     "labels",
+		},
+		Delimiter:     ';',
+		DateFormat:    "02.01.2006",
+		TagsSeparator: "|",
+		DayStart:      csv.DefaultDayStart,
+	})
+	require.Nil(t, err)
+
+	entries, err := csvClient.FetchEntries(context.Background(), newFetchOpts(
+		time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+	))
+
+	// The rows without start are placed one after the other from the day start
+	start := time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC)
+
+	require.Nil(t, err)
+	require.Equal(t, worklog.Entries{
+		{
+			Task:    worklog.IDNameField{ID: "TASK-123", Name: "TASK-123"},
+			Summary: "Meeting",
+			Notes:   "Meeting",
+			Tags: []worklog.IDNameField{
+				{ID: "meeting", Name: "meeting"},
+				{ID: "TASK-123", Name: "TASK-123"},
+			},
+			Start:            start,
+			End:              start.Add(90 * time.Minute),
+			BillableDuration: 90 * time.Minute,
+			Source:           csv.SourceName,
+			SourceID:         "2",
+		},
+		{
+			Summary:          "Code review",
+			Notes:            "Code review",
+			Start:            start.Add(90 * time.Minute),
+			End:              start.Add(135 * time.Minute),
+			BillableDuration: 45 * time.Minute,
+			Source:           csv.SourceName,
+			SourceID:         "3",
+		},
+		{
+			Summary:          "Planning",
+			Notes:            "Planning",
+			Start:            start.Add(135 * time.Minute),
+			End:              start.Add(210 * time.Minute),
+			BillableDuration: 75 * time.Minute,
+			Source:           csv.SourceName,
+			SourceID:         "4",
+		},
+	}, entries)
+}
+
+func TestCSVClient_FetchEntries_TagsAsTasks(t *testing.T) {
+	path := writeCSVFile(t, strings.Join([]string{
+		"start,end,summary,tags",
+		`2021-10-12 09:00,2021-10-12 10:00,Meeting,"TASK-123, TASK-456"`,
+	}, "\n"))
+
+	csvClient, err := csv.NewFetcher(&csv.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:       path,
+		Columns:    csv.DefaultColumnMapping(),
+		TimeFormat: "2006-01-02 15:04",
+	})
+	require.Nil(t, err)
+
+	entries, err := csvClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start:            time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:              time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+		TagsAsTasksRegex: regexp.MustCompile(`^TASK-\d+$`),
+	})
+
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "TASK-123", entries[0].Task.Name)
+	require.Equal(t, 30*time.Minute, entries[0].BillableDuration)
+	require.Equal(t, "TASK-456", entries[1].Task.Name)
+	require.Equal(t, 30*time.Minute, entries[1].BillableDuration)
+}
+
+func TestCSVClient_FetchEntries_Stdin(t *testing.T) {
+	stdin := strings.NewReader(strings.Join([]string{
+		"date,duration,summary",
+		"2021-10-12,1.5,Meeting",
+		"2021-10-13,2,Planning",
+	}, "\n"))
+
+	csvClient, err := csv.NewFetcher(&csv.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:    csv.PathStdin,
+		Columns: csv.DefaultColumnMapping(),
+		Stdin:   stdin,
+	})
+	require.Nil(t, err)
+
+	// The input is read once, though fetched window by window
+	for _, day := range []int{12, 13} {
+		entries, err := csvClient.FetchEntries(context.Background(), newFetchOpts(
+			time.Date(2021, 10, day, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 10, day+1, 0, 0, 0, 0, time.UTC),
+		))
+
+		require.Nil(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, time.Date(2021, 10, day, 0, 0, 0, 0, time.UTC), entries[0].Start)
+	}
+}
+
+func TestCSVClient_FetchEntries_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		err     string
+	}{
+		"missing start": {
+			content: "end,summary\n10:00,Meeting",
+			err:     csv.ErrMissingColumn.Error(),
+		},
+		"missing duration": {
+			content: "date,start,summary\n2021-10-12,09:00,Meeting",
+			err:     csv.ErrMissingColumn.Error(),
+		},
+		"empty duration": {
+			content: "date,start,duration\n2021-10-12,09:00,",
+			err:     csv.ErrNoDuration.Error(),
+		},
+		"invalid billable": {
+			content: "date,duration,billable\n2021-10-12,1h,maybe",
+			err:     csv.ErrInvalidBillable.Error(),
+		},
+		"invalid date": {
+			content: "date,duration\n12/10/2021,1h",
+			err:     "line 2",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			csvClient, err := csv.NewFetcher(&csv.ClientOpts{
+				BaseClientOpts: client.BaseClientOpts{
+					Timezone: time.UTC,
+				},
+				Path:    writeCSVFile(t, test.content),
+				Columns: csv.DefaultColumnMapping(),
+			})
+			require.Nil(t, err)
+
+			_, err = csvClient.FetchEntries(context.Background(), newFetchOpts(
+				time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+			))
+
+			require.ErrorContains(t, err, client.ErrFetchEntries.Error())
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestNewFetcher_NoPath(t *testing.T) {
+	_, err := csv.NewFetcher(&csv.ClientOpts{})
+	require.ErrorContains(t, err, client.ErrFetchEntries.Error())
+}
+
+func getUploadEntries() worklog.Entries {
+	return worklog.Entries{
+		{
+			Client:             worklog.IDNameField{ID: "client-id", Name: "My Awesome Company"},
+			Project:            worklog.IDNameField{ID: "project-id", Name: "Internal"},
+			Task:               worklog.IDNameField{ID: "task-id", Name: "TASK-123"},
+			Summary:            "Meeting, planning",
+			Notes:              "Meeting, planning",
+			Tags:               []worklog.IDNameField{{ID: "meeting", Name: "meeting"}, {ID: "planning", Name: "planning"}},
+			Start:              time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC),
+			End:                time.Date(2021, 10, 12, 10, 30, 0, 0, time.UTC),
+			BillableDuration:   time.Hour,
+			UnbillableDuration: 30 * time.Minute,
+			Source:             "clockify",
+			SourceID:           "entry-1",
+		},
+		{
+			Task:             worklog.IDNameField{ID: "task-id", Name: "TASK-456"},
+			Summary:          "Review",
+			Notes:            "Review",
+			Start:            time.Date(2021, 11, 2, 9, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC),
+			BillableDuration: time.Hour,
+			Source:           "clockify",
+			SourceID:         "entry-2",
+		},
+	}
+}
+
+func uploadEntries(t *testing.T, uploader client.Uploader, entries worklog.Entries) {
+	errChan := make(chan error, len(entries))
+	uploader.UploadEntries(context.Background(), entries, errChan, &client.UploadOpts{User: "steve-rogers"})
+
+	for range entries {
+		require.Nil(t, <-errChan)
+	}
+}
+
+func TestCSVClient_UploadEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "worklog.csv")
+	entries := getUploadEntries()
+
+	csvClient, err := csv.NewUploader(&csv.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:   path,
+		Append: true,
+	})
+	require.Nil(t, err)
+
+	uploadEntries(t, csvClient, entries[:1])
+	uploadEntries(t, csvClient, entries[1:])
+
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, strings.Join([]string{
+		"start,end,client_id,client,project_id,project,task_id,task,summary,notes,comment,tags,billable_seconds,unbillable_seconds,total_seconds,source,source_id,user",
+		`2021-10-12T09:00:00Z,2021-10-12T10:30:00Z,client-id,My Awesome Company,project-id,Internal,task-id,TASK-123,"Meeting, planning","Meeting, planning","Meeting, planning","meeting,planning",3600,1800,5400,clockify,entry-1,steve-rogers`,
+		"2021-11-02T09:00:00Z,2021-11-02T10:00:00Z,,,,,task-id,TASK-456,Review,Review,Review,,3600,0,3600,clockify,entry-2,steve-rogers",
+		"",
+	}, "\n"), string(content))
+}
+
+func TestCSVClient_UploadEntries_RotateMonthly(t *testing.T) {
+	dir := t.TempDir()
+
+	csvClient, err := csv.NewUploader(&csv.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:          filepath.Join(dir, "worklog.csv"),
+		Delimiter:     ';',
+		RotateMonthly: true,
+	})
+	require.Nil(t, err)
+
+	uploadEntries(t, csvClient, getUploadEntries())
+
+	for _, name := range []string{"worklog-2021-10.csv", "worklog-2021-11.csv"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		require.Nil(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		require.Len(t, lines, 2)
+		require.True(t, strings.HasPrefix(lines[0], "start;end;"))
+	}
+}
+
+func TestCSVClient_UploadEntries_Stdout(t *testing.T) {
+	var stdout strings.Builder
+
+	csvClient, err := csv.NewUploader(&csv.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:   csv.PathStdout,
+		Stdout: &stdout,
+	})
+	require.Nil(t, err)
+
+	uploadEntries(t, csvClient, getUploadEntries())
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	require.True(t, strings.HasPrefix(lines[1], "2021-10-12T09:00:00Z"))
+	require.True(t, strings.HasPrefix(lines[2], "2021-11-02T09:00:00Z"))
+}
+
+func TestNewUploader_NoPath(t *testing.T) {
+	_, err := csv.NewUploader(&csv.ClientOpts{})
+	require.ErrorContains(t, err, client.ErrUploadEntries.Error())
+}
@@ -0,0 +1,81 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// Since the documents are read from the disk, the HTTP related options are not
+// used by the client.
+type ClientOpts struct {
+	client.BaseClientOpts
+	// Document is the saved document holding the entries.
+	Document *worklog.Document
+}
+
+type documentClient struct {
+	*client.BaseClientOpts
+	document *worklog.Document
+}
+
+// ReadFile reads the saved document from the given path.
+func ReadFile(path string) (*worklog.Document, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return worklog.DecodeDocument(file)
+}
+
+// WriteFile writes the document to the given path, overwriting the existing
+// file.
+func WriteFile(path string, document *worklog.Document) error {
+	file, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err = document.Encode(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// FetchEntries returns the entries of the document starting within the date
+// range. The entries keep the source they were fetched from originally.
+func (c *documentClient) FetchEntries(_ context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	var entries worklog.Entries
+
+	for _, entry := range c.document.Entries {
+		if entry.Start.Before(opts.Start) || !entry.Start.Before(opts.End) {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// NewFetcher returns a new document client for fetching the saved entries.
+func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
+	if opts.Document == nil {
+		return nil, fmt.Errorf("%v: %s", client.ErrFetchEntries, "no document set")
+	}
+
+	return &documentClient{
+		BaseClientOpts: &opts.BaseClientOpts,
+		document:       opts.Document,
+	}, nil
+}
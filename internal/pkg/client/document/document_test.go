@@ -0,0 +1,77 @@
+package document_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/document"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestEntry(start time.Time) worklog.Entry {
+	return worklog.Entry{
+		Client:           worklog.IDNameField{ID: "client-id", Name: "My Awesome Company"},
+		Project:          worklog.IDNameField{ID: "project-id", Name: "Internal"},
+		Task:             worklog.IDNameField{ID: "task-id", Name: "TASK-123"},
+		Summary:          "Meeting",
+		Start:            start,
+		End:              start.Add(time.Hour),
+		BillableDuration: time.Hour,
+		Source:           "clockify",
+		SourceID:         "entry-id",
+	}
+}
+
+func TestDocumentClient_FetchEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "week41.json")
+	start := time.Date(2021, 10, 11, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	err := document.WriteFile(path, worklog.NewDocument(worklog.Entries{
+		getTestEntry(time.Date(2021, 10, 10, 9, 0, 0, 0, time.UTC)),
+		getTestEntry(time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC)),
+	}, &worklog.DocumentOpts{
+		Source: "clockify",
+		Start:  start,
+		End:    end,
+	}))
+	require.Nil(t, err)
+
+	savedDocument, err := document.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, start, savedDocument.Start)
+	require.Equal(t, end, savedDocument.End)
+
+	documentClient, err := document.NewFetcher(&document.ClientOpts{
+		Document: savedDocument,
+	})
+	require.Nil(t, err)
+
+	entries, err := documentClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: savedDocument.Start,
+		End:   savedDocument.End,
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, worklog.Entries{
+		getTestEntry(time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC)),
+	}, entries)
+}
+
+func TestReadFile_UnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "week41.json")
+	require.Nil(t, os.WriteFile(path, []byte(`{"version": 99}`), 0600))
+
+	_, err := document.ReadFile(path)
+	require.ErrorContains(t, err, worklog.ErrUnsupportedDocumentVersion.Error())
+}
+
+func TestNewFetcher_NoDocument(t *testing.T) {
+	_, err := document.NewFetcher(&document.ClientOpts{})
+	require.ErrorContains(t, err, client.ErrFetchEntries.Error())
+}
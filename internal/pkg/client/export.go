@@ -0,0 +1,202 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/jedib0t/go-pretty/v6/progress"
+)
+
+const (
+	// PathStdout is the path used by file based uploaders to write the
+	// entries to the standard output.
+	PathStdout string = "-"
+)
+
+// ExportEntry is the schema of the entries written by file based uploaders.
+// The schema is stable, so the exported files can be archived and processed
+// by other tools. The durations are in seconds, after applying the upload
+// options, and the dates are RFC3339 formatted.
+type ExportEntry struct {
+	Start             string   `json:"start"`
+	End               string   `json:"end"`
+	ClientID          string   `json:"client_id"`
+	Client            string   `json:"client"`
+	ProjectID         string   `json:"project_id"`
+	Project           string   `json:"project"`
+	TaskID            string   `json:"task_id"`
+	Task              string   `json:"task"`
+	Summary           string   `json:"summary"`
+	Notes             string   `json:"notes"`
+	Comment           string   `json:"comment"`
+	Tags              []string `json:"tags"`
+	BillableSeconds   int      `json:"billable_seconds"`
+	UnbillableSeconds int      `json:"unbillable_seconds"`
+	TotalSeconds      int      `json:"total_seconds"`
+	Source            string   `json:"source"`
+	SourceID          string   `json:"source_id"`
+	User              string   `json:"user"`
+}
+
+// FileTargetOpts specifies where the file based uploaders write the entries.
+type FileTargetOpts struct {
+	// Path is the path of the file. If set to PathStdout, the entries are
+	// written to the standard output.
+	Path string
+	// Append indicates to append the entries to the existing file, instead of
+	// overwriting it.
+	Append bool
+	// RotateMonthly indicates to write the entries to a file per month, by
+	// suffixing the file name with the year and month of the entry start,
+	// like "worklog-2021-10.csv".
+	RotateMonthly bool
+	// Stdout is written when the path is PathStdout, defaults to os.Stdout.
+	Stdout io.Writer
+}
+
+// FilePaths groups the entries by the path of the file they must be written
+// to, ordered by their start. If the files are rotated monthly, the month of
+// the entry start is determined in the given location.
+func (o *FileTargetOpts) FilePaths(entries worklog.Entries, loc *time.Location) map[string]worklog.Entries {
+	paths := map[string]worklog.Entries{}
+
+	for _, entry := range entries {
+		path := o.Path
+
+		if o.RotateMonthly && path != PathStdout {
+			extension := filepath.Ext(path)
+			path = fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, extension), entry.Start.In(loc).Format("2006-01"), extension)
+		}
+
+		paths[path] = append(paths[path], entry)
+	}
+
+	for _, pathEntries := range paths {
+		sort.SliceStable(pathEntries, func(i, j int) bool {
+			return pathEntries[i].Start.Before(pathEntries[j].Start)
+		})
+	}
+
+	return paths
+}
+
+// OpenFile opens the file for writing, creating it if needed. The file is
+// truncated unless the entries are appended. If the path is PathStdout, the
+// standard output returns, and closing it is a no-op.
+func (o *FileTargetOpts) OpenFile(path string) (io.WriteCloser, error) {
+	if path == PathStdout {
+		stdout := o.Stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+
+		return nopWriteCloser{stdout}, nil
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if o.Append {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return nil, err
+		}
+	}
+
+	return os.OpenFile(filepath.Clean(path), flags, 0600)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// NewExportEntry returns the entry in the export schema. The durations are
+// calculated the same way as the other uploaders do, and the comment is
+// rendered using the template of the upload options.
+func (u *DefaultUploader) NewExportEntry(entry worklog.Entry, loc *time.Location, opts *UploadOpts) (ExportEntry, error) {
+	comment, err := u.RenderComment(entry, opts.CommentTemplate)
+	if err != nil {
+		return ExportEntry{}, err
+	}
+
+	billableDuration, unbillableDuration := u.Durations(entry, opts)
+
+	tags := make([]string, 0, len(entry.Tags))
+	for _, tag := range entry.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	return ExportEntry{
+		Start:             entry.Start.In(loc).Format(time.RFC3339),
+		End:               entry.EndTime().In(loc).Format(time.RFC3339),
+		ClientID:          entry.Client.ID,
+		Client:            entry.Client.Name,
+		ProjectID:         entry.Project.ID,
+		Project:           entry.Project.Name,
+		TaskID:            entry.Task.ID,
+		Task:              entry.Task.Name,
+		Summary:           entry.Summary,
+		Notes:             entry.Notes,
+		Comment:           comment,
+		Tags:              tags,
+		BillableSeconds:   int(billableDuration.Seconds()),
+		UnbillableSeconds: int(unbillableDuration.Seconds()),
+		TotalSeconds:      int((billableDuration + unbillableDuration).Seconds()),
+		Source:            entry.Source,
+		SourceID:          entry.SourceID,
+		User:              opts.User,
+	}, nil
+}
+
+// UploadFiles converts the entries to the export schema and writes them file by
+// file using the write function. Since a file is written at once, the result
+// of writing the file is reported for every entry written to it. The progress
+// is not tracked when writing to the standard output, as the progress would be
+// mixed with the written entries.
+func (u *DefaultUploader) UploadFiles(entries worklog.Entries, errChan chan error, opts *UploadOpts, target *FileTargetOpts, loc *time.Location, write func(path string, entries []ExportEntry) error) {
+	for path, pathEntries := range target.FilePaths(entries, loc) {
+		progressWriter := opts.ProgressWriter
+		if path == PathStdout {
+			progressWriter = nil
+		}
+
+		var err error
+		trackers := make([]*progress.Tracker, 0, len(pathEntries))
+		exportEntries := make([]ExportEntry, 0, len(pathEntries))
+
+		for _, entry := range pathEntries {
+			trackers = append(trackers, u.StartTracking(entry, progressWriter))
+
+			exportEntry, exportErr := u.NewExportEntry(entry, loc, opts)
+			if exportErr != nil && err == nil {
+				err = exportErr
+			}
+
+			exportEntries = append(exportEntries, exportEntry)
+		}
+
+		if err == nil {
+			err = write(path, exportEntries)
+		}
+
+		if err != nil {
+			err = fmt.Errorf("%v: %s: %v", ErrUploadEntries, path, err)
+		}
+
+		for _, tracker := range trackers {
+			u.StopTracking(tracker, err)
+			errChan <- err
+		}
+	}
+}
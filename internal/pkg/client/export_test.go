@@ -0,0 +1,158 @@
+package client_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileTargetOpts_FilePaths(t *testing.T) {
+	october := getTestEntry()
+	september := getTestEntry()
+	september.Start = time.Date(2021, 9, 30, 23, 30, 0, 0, time.UTC)
+
+	opts := &client.FileTargetOpts{Path: "archive/worklog.csv"}
+	require.Equal(t, map[string]worklog.Entries{
+		"archive/worklog.csv": {september, october},
+	}, opts.FilePaths(worklog.Entries{october, september}, time.UTC))
+
+	opts.RotateMonthly = true
+	require.Equal(t, map[string]worklog.Entries{
+		"archive/worklog-2021-09.csv": {september},
+		"archive/worklog-2021-10.csv": {october},
+	}, opts.FilePaths(worklog.Entries{october, september}, time.UTC))
+
+	// The month is determined in the given location
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+	require.Equal(t, map[string]worklog.Entries{
+		"archive/worklog-2021-10.csv": {september, october},
+	}, opts.FilePaths(worklog.Entries{october, september}, berlin))
+
+	opts.Path = client.PathStdout
+	require.Equal(t, map[string]worklog.Entries{
+		client.PathStdout: {september, october},
+	}, opts.FilePaths(worklog.Entries{october, september}, time.UTC))
+}
+
+func TestFileTargetOpts_OpenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive", "worklog.txt")
+
+	for _, test := range []struct {
+		append   bool
+		content  string
+		expected string
+	}{
+		{append: false, content: "first\n", expected: "first\n"},
+		{append: true, content: "second\n", expected: "first\nsecond\n"},
+		{append: false, content: "third\n", expected: "third\n"},
+	} {
+		opts := &client.FileTargetOpts{Path: path, Append: test.append}
+
+		file, err := opts.OpenFile(path)
+		require.Nil(t, err)
+
+		_, err = file.Write([]byte(test.content))
+		require.Nil(t, err)
+		require.Nil(t, file.Close())
+
+		content, err := os.ReadFile(path)
+		require.Nil(t, err)
+		require.Equal(t, test.expected, string(content))
+	}
+}
+
+func TestFileTargetOpts_OpenFile_Stdout(t *testing.T) {
+	var stdout bytes.Buffer
+	opts := &client.FileTargetOpts{Path: client.PathStdout, Stdout: &stdout}
+
+	file, err := opts.OpenFile(client.PathStdout)
+	require.Nil(t, err)
+
+	_, err = file.Write([]byte("entries"))
+	require.Nil(t, err)
+	require.Nil(t, file.Close())
+	require.Equal(t, "entries", stdout.String())
+}
+
+func TestDefaultUploader_NewExportEntry(t *testing.T) {
+	entry := getTestEntry()
+	entry.End = entry.Start.Add(150 * time.Minute)
+	entry.BillableDuration = 2 * time.Hour
+	entry.UnbillableDuration = 30*time.Minute + 20*time.Second
+	entry.Tags = []worklog.IDNameField{{ID: "tag-id", Name: "meeting"}}
+	entry.Source = "clockify"
+	entry.SourceID = "entry-id"
+
+	commentTemplate, err := client.NewCommentTemplate("{{.Task.Name}}: {{.Summary}}")
+	require.Nil(t, err)
+
+	uploader := client.DefaultUploader{}
+	exportEntry, err := uploader.NewExportEntry(entry, time.UTC, &client.UploadOpts{
+		RoundToClosestMinute: true,
+		User:                 "steve-rogers",
+		CommentTemplate:      commentTemplate,
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, client.ExportEntry{
+		Start:             "2021-10-02T05:00:00Z",
+		End:               "2021-10-02T07:30:00Z",
+		ClientID:          "client-id",
+		Client:            "My Awesome Company",
+		ProjectID:         "project-id",
+		Project:           "Internal projects",
+		TaskID:            "task-id",
+		Task:              "TASK-0123",
+		Summary:           "Write worklog transfer CLI tool",
+		Notes:             "It is a lot easier than expected",
+		Comment:           "TASK-0123: Write worklog transfer CLI tool",
+		Tags:              []string{"meeting"},
+		BillableSeconds:   7200,
+		UnbillableSeconds: 1800,
+		TotalSeconds:      9000,
+		Source:            "clockify",
+		SourceID:          "entry-id",
+		User:              "steve-rogers",
+	}, exportEntry)
+}
+
+func TestDefaultUploader_NewExportEntry_NoEnd(t *testing.T) {
+	entry := getTestEntry()
+	entry.End = time.Time{}
+	entry.BillableDuration = time.Hour
+	entry.UnbillableDuration = 30 * time.Minute
+
+	uploader := client.DefaultUploader{}
+	exportEntry, err := uploader.NewExportEntry(entry, time.UTC, &client.UploadOpts{})
+
+	// The end is calculated from the duration
+	require.Nil(t, err)
+	require.Equal(t, "2021-10-02T06:30:00Z", exportEntry.End)
+}
+
+func TestDefaultUploader_UploadFiles(t *testing.T) {
+	entries := worklog.Entries{getTestEntry(), getTestEntry()}
+	errChan := make(chan error, len(entries))
+
+	var written []client.ExportEntry
+
+	uploader := client.DefaultUploader{}
+	uploader.UploadFiles(entries, errChan, &client.UploadOpts{}, &client.FileTargetOpts{Path: "worklog.csv"}, time.UTC, func(path string, exportEntries []client.ExportEntry) error {
+		require.Equal(t, "worklog.csv", path)
+		written = exportEntries
+		return errors.New("disk full")
+	})
+
+	require.Len(t, written, 2)
+	for range entries {
+		require.ErrorContains(t, <-errChan, "disk full")
+	}
+}
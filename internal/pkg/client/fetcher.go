@@ -3,6 +3,8 @@ package client
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"regexp"
 	"time"
 
@@ -18,11 +20,28 @@ const (
 	DefaultPageSizeParam string = "per_page"
 	// DefaultPageParam used by paginated fetchers setting the page parameter.
 	DefaultPageParam string = "page"
+	// RunningEntriesSkip sets to leave out the running entries.
+	RunningEntriesSkip string = "skip"
+	// RunningEntriesUntilNow sets to treat the running entries as they would
+	// end at the time of fetching.
+	RunningEntriesUntilNow string = "until-now"
+	// RunningEntriesError sets to fail the fetching if a running entry found.
+	RunningEntriesError string = "error"
 )
 
 var (
 	// ErrFetchEntries wraps the error when fetch failed.
 	ErrFetchEntries = errors.New("failed to fetch entries")
+	// ErrRunningEntry returns when a running entry found, but the running
+	// entries are not allowed.
+	ErrRunningEntry = errors.New("running entry found")
+
+	// RunningEntriesPolicies lists the policies of handling running entries.
+	RunningEntriesPolicies = []string{
+		RunningEntriesSkip,
+		RunningEntriesUntilNow,
+		RunningEntriesError,
+	}
 )
 
 // FetchOpts specifies the only options for Fetchers.
@@ -36,6 +55,36 @@ type FetchOpts struct {
 	// TagsAsTasksRegex sets the regular expression used for extracting tasks
 	// from the list of tags.
 	TagsAsTasksRegex *regexp.Regexp
+
+	// RunningEntries sets how the entries, which are still running, are
+	// handled. It must be one of the RunningEntriesPolicies, and defaults to
+	// RunningEntriesSkip.
+	RunningEntries string
+}
+
+// SkipsRunningEntries returns true if the running entries are left out, so the
+// fetchers can exclude them when querying the entries.
+func (o *FetchOpts) SkipsRunningEntries() bool {
+	return o.RunningEntries == "" || o.RunningEntries == RunningEntriesSkip
+}
+
+// RunningEntryEnd returns the end of a running entry started at the given time,
+// according to the RunningEntries policy. If the entry must be left out, false
+// returns. If the running entries are not allowed, ErrRunningEntry returns.
+func (o *FetchOpts) RunningEntryEnd(start time.Time) (time.Time, bool, error) {
+	switch o.RunningEntries {
+	case RunningEntriesUntilNow:
+		now := time.Now().In(start.Location())
+		if now.Before(start) {
+			now = start
+		}
+
+		return now, true, nil
+	case RunningEntriesError:
+		return time.Time{}, false, fmt.Errorf("%v: started at %s", ErrRunningEntry, start.Format(time.RFC3339))
+	default:
+		return time.Time{}, false, nil
+	}
 }
 
 // Fetcher specifies the functions used to fetch worklog entries.
@@ -46,21 +95,88 @@ type Fetcher interface {
 	FetchEntries(ctx context.Context, opts *FetchOpts) (worklog.Entries, error)
 }
 
+// FetchPage represents a page of entries sent by a StreamFetcher.
+type FetchPage struct {
+	// Page is the number of the page, starting from 1.
+	Page int
+	// TotalPages is the number of pages if known, otherwise 0 (zero).
+	TotalPages int
+	// Entries are the parsed entries of the page.
+	Entries worklog.Entries
+	// Err is set if the fetching failed. The page with the error is the last
+	// page sent.
+	Err error
+}
+
+// StreamFetcher specifies the functions used to fetch worklog entries page by
+// page, so the entries can be processed before all pages are fetched.
+type StreamFetcher interface {
+	// FetchEntriesStream fetches the entries from a given source in the
+	// background and sends the pages on the returned channel. The channel is
+	// closed when all pages are sent, the fetching failed, or the context is
+	// done. To stop the fetching early, cancel the context.
+	FetchEntriesStream(ctx context.Context, opts *FetchOpts) <-chan FetchPage
+}
+
+// FailedFetchStream returns a closed stream that has only one page holding the
+// error, used when the fetching fails before it could start.
+func FailedFetchStream(err error) <-chan FetchPage {
+	pages := make(chan FetchPage, 1)
+	pages <- FetchPage{Err: err}
+	close(pages)
+
+	return pages
+}
+
+// CollectFetchPages receives all pages of the stream and returns their entries.
+// If a page has an error or the context is done before all pages received, the
+// entries are dropped and an error returns.
+func CollectFetchPages(ctx context.Context, pages <-chan FetchPage) (worklog.Entries, error) {
+	var entries worklog.Entries
+
+	for page := range pages {
+		if page.Err != nil {
+			return nil, page.Err
+		}
+
+		entries = append(entries, page.Entries...)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrFetchEntries, err)
+	}
+
+	return entries, nil
+}
+
+// PaginatedFetchResponse represents the pagination details of a fetched page.
+// PageEntries must be set to the number of entries on the page, before parsing
+// them, as the fetching stops on the first empty page. The rest of the fields
+// are set depending on the Pagination used.
 type PaginatedFetchResponse struct {
+	PageEntries    int
 	EntriesPerPage int
 	TotalEntries   int
+	// NextCursor is the cursor of the next page, used by CursorPagination.
+	NextCursor string
+	// NextURL is the URL of the next page, used by NextURLPagination.
+	NextURL string
+	// Header is the header of the HTTP response, used by LinkHeaderPagination.
+	Header http.Header
 }
 
 type PaginatedFetchFunc = func(context.Context, string) (interface{}, *PaginatedFetchResponse, error)
 type PaginatedParseFunc = func(interface{}, *FetchOpts) (worklog.Entries, error)
 
+// PaginatedFetchOpts specifies how the entries are fetched from a paginated
+// API. If the Pagination is not set, PageNumberPagination is used with its
+// default params.
 type PaginatedFetchOpts struct {
 	BaseFetchOpts *FetchOpts
 
-	URL           string
-	PageSize      int
-	PageSizeParam string
-	PageParam     string
+	URL        string
+	PageSize   int
+	Pagination Pagination
 
 	FetchFunc PaginatedFetchFunc
 	ParseFunc PaginatedParseFunc
@@ -0,0 +1,328 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "git"
+	// DefaultSessionGap is the default maximum gap between the commits of a
+	// session.
+	DefaultSessionGap time.Duration = 2 * time.Hour
+	// DefaultLeadIn is the default time spent before the first commit of a
+	// session.
+	DefaultLeadIn time.Duration = 30 * time.Minute
+	// DefaultTaskRegex is the default regex of the task keys, matching Jira
+	// issue keys like "PROJ-123".
+	DefaultTaskRegex string = `[A-Z][A-Z0-9]+-\d+`
+
+	// logFormat is the format of the log, separating the hash, author date,
+	// ref, and message of the commits by unit separators, and the commits by
+	// record separators.
+	logFormat       string = "%H%x1f%aI%x1f%S%x1f%B%x1e"
+	fieldSeparator  string = "\x1f"
+	recordSeparator string = "\x1e"
+)
+
+// Commit represents a commit read from the log of a repository.
+type Commit struct {
+	Repository string
+	Hash       string
+	Date       time.Time
+	Ref        string
+	Message    string
+}
+
+// Subject returns the first line of the commit message.
+func (c *Commit) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	return strings.TrimSpace(subject)
+}
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// Since Git is a CLI tool, the commits are read by running the executable
+// (Command) with the log arguments, extended by CommandArguments. The
+// commits of every repository are grouped into sessions: commits closer than
+// the SessionGap belong to the same session, and the session starts LeadIn
+// before its first commit.
+type ClientOpts struct {
+	client.BaseClientOpts
+	client.CLIClient
+	// Repositories are the paths of the local repositories scanned.
+	Repositories []string
+	// Client is the name of the client set on every entry.
+	Client     string
+	SessionGap time.Duration
+	LeadIn     time.Duration
+	// TaskRegex matches the task keys in branch names and commit messages.
+	TaskRegex string
+}
+
+type gitClient struct {
+	*client.BaseClientOpts
+	*client.CLIClient
+	repositories []string
+	clientName   string
+	sessionGap   time.Duration
+	leadIn       time.Duration
+	taskRegex    *regexp.Regexp
+}
+
+// parseLog parses the output of the log command formatted by logFormat.
+func parseLog(out []byte) ([]Commit, error) {
+	var commits []Commit
+
+	for _, record := range bytes.Split(out, []byte(recordSeparator)) {
+		if len(bytes.TrimSpace(record)) == 0 {
+			continue
+		}
+
+		fields := strings.SplitN(strings.TrimLeft(string(record), "\n"), fieldSeparator, 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid log record: %q", record)
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Date:    date,
+			Ref:     fields[2],
+			Message: fields[3],
+		})
+	}
+
+	return commits, nil
+}
+
+// readCommits returns the commits of the repository authored within the date
+// range by the user, ordered by their author date. If the user is not set, the
+// commits of every author return.
+func (c *gitClient) readCommits(ctx context.Context, repository string, opts *client.FetchOpts) ([]Commit, error) {
+	// Commits are filtered by the commit date, which cannot be earlier than
+	// the author date, so the end of the range is checked after reading
+	arguments := []string{
+		"-C", repository, "log", "--all", "--source", "--no-merges", "--no-color",
+		"--format=" + logFormat,
+		"--since=" + opts.Start.Format(time.RFC3339),
+	}
+
+	if opts.User != "" {
+		arguments = append(arguments, "--author="+opts.User)
+	}
+
+	arguments = append(arguments, c.CommandArguments...)
+
+	out, err := c.Execute(ctx, arguments, &client.CLIExecuteOpts{
+		Timeout: c.Timeout,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	logCommits, err := parseLog(out)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	seen := map[string]bool{}
+
+	for _, commit := range logCommits {
+		if seen[commit.Hash] || commit.Date.Before(opts.Start) || !commit.Date.Before(opts.End) {
+			continue
+		}
+
+		seen[commit.Hash] = true
+		commit.Repository = repository
+		commit.Date = commit.Date.In(c.Location())
+		commits = append(commits, commit)
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Date.Before(commits[j].Date)
+	})
+
+	return commits, nil
+}
+
+// sessions groups the ordered commits of every repository into sessions. A
+// commit belongs to the session of the previous commit if it was authored
+// within the session gap, regardless of its repository.
+func (c *gitClient) sessions(commits []Commit) [][]Commit {
+	var sessions [][]Commit
+
+	for i, commit := range commits {
+		if i == 0 || commit.Date.Sub(commits[i-1].Date) > c.sessionGap {
+			sessions = append(sessions, []Commit{})
+		}
+
+		sessions[len(sessions)-1] = append(sessions[len(sessions)-1], commit)
+	}
+
+	return sessions
+}
+
+// parseSession returns the entries of the session. The session is split into
+// runs of consecutive commits of the same repository. The first run starts
+// with the lead-in, and the others start with the last commit of the previous
+// run, hence the entries of a session do not overlap.
+func (c *gitClient) parseSession(session []Commit) worklog.Entries {
+	var entries worklog.Entries
+
+	startDate := session[0].Date.Add(-c.leadIn)
+	runStart := 0
+
+	for i := range session {
+		if i+1 < len(session) && session[i+1].Repository == session[i].Repository {
+			continue
+		}
+
+		entries = append(entries, c.parseRun(session[runStart:i+1], startDate)...)
+		startDate = session[i].Date
+		runStart = i + 1
+	}
+
+	return entries
+}
+
+// parseRun returns the entries of consecutive commits of a repository, worked
+// on from the start date. The task keys found in the branch names and commit
+// messages are set as tags, and the run is split evenly between the tasks.
+// Without task keys, the task is left empty.
+func (c *gitClient) parseRun(run []Commit, startDate time.Time) worklog.Entries {
+	endDate := run[len(run)-1].Date
+
+	var tags []worklog.IDNameField
+	var subjects []string
+	seenKeys := map[string]bool{}
+
+	for _, commit := range run {
+		if subject := commit.Subject(); subject != "" {
+			subjects = append(subjects, subject)
+		}
+
+		if !utils.IsRegexSet(c.taskRegex) {
+			continue
+		}
+
+		for _, key := range c.taskRegex.FindAllString(commit.Ref+"\n"+commit.Message, -1) {
+			if !seenKeys[key] {
+				seenKeys[key] = true
+				tags = append(tags, worklog.IDNameField{ID: key, Name: key})
+			}
+		}
+	}
+
+	summary := strings.Join(subjects, "; ")
+	project := filepath.Base(run[0].Repository)
+
+	entry := worklog.Entry{
+		Client:             worklog.IDNameField{ID: c.clientName, Name: c.clientName},
+		Project:            worklog.IDNameField{ID: project, Name: project},
+		Summary:            summary,
+		Notes:              summary,
+		Tags:               tags,
+		Start:              startDate,
+		End:                endDate,
+		BillableDuration:   endDate.Sub(startDate),
+		UnbillableDuration: 0,
+		Source:             SourceName,
+		SourceID:           run[0].Hash,
+	}
+
+	if len(tags) == 0 {
+		return worklog.Entries{entry}
+	}
+
+	return entry.SplitByTagsAsTasks(summary, c.taskRegex, tags)
+}
+
+// FetchEntries returns the entries of the sessions. The sessions are built from
+// the commits of every repository, so working on multiple repositories at the
+// same time does not result in overlapping entries.
+func (c *gitClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	var commits []Commit
+
+	for _, repository := range c.repositories {
+		repositoryCommits, err := c.readCommits(ctx, repository, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s: %v", client.ErrFetchEntries, repository, err)
+		}
+
+		commits = append(commits, repositoryCommits...)
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Date.Before(commits[j].Date)
+	})
+
+	var entries worklog.Entries
+
+	for _, session := range c.sessions(commits) {
+		for _, entry := range c.parseSession(session) {
+			// A single commit without lead-in, or a commit authored at the
+			// same time as the previous run has no duration
+			if entry.BillableDuration == 0 {
+				continue
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// NewFetcher returns a new Git client for fetching entries.
+func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
+	if len(opts.Repositories) == 0 {
+		return nil, fmt.Errorf("%v: %s", client.ErrFetchEntries, "no repositories set")
+	}
+
+	taskRegex, err := regexp.Compile(opts.TaskRegex)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	repositories := make([]string, 0, len(opts.Repositories))
+	for _, repository := range opts.Repositories {
+		absPath, err := filepath.Abs(repository)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+
+		repositories = append(repositories, absPath)
+	}
+
+	sessionGap := opts.SessionGap
+	if sessionGap == 0 {
+		sessionGap = DefaultSessionGap
+	}
+
+	return &gitClient{
+		BaseClientOpts: &opts.BaseClientOpts,
+		CLIClient:      &opts.CLIClient,
+		repositories:   repositories,
+		clientName:     opts.Client,
+		sessionGap:     sessionGap,
+		leadIn:         opts.LeadIn,
+		taskRegex:      taskRegex,
+	}, nil
+}
@@ -0,0 +1,254 @@
+package git_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/git"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	mockedExitCode  int
+	mockedStdout    string
+	mockedArguments []string
+	// mockedStdouts are the outputs by repository, used instead of the
+	// mockedStdout if set
+	mockedStdouts map[string]string
+)
+
+func mockedExecCommand(_ context.Context, command string, args ...string) *exec.Cmd {
+	mockedArguments = args
+
+	stdout := mockedStdout
+	if repositoryStdout, ok := mockedStdouts[args[1]]; ok {
+		stdout = repositoryStdout
+	}
+
+	arguments := []string{"-test.run=TestExecCommandHelper", "--", command}
+	arguments = append(arguments, args...)
+	cmd := exec.Command(os.Args[0], arguments...)
+
+	cmd.Env = []string{"GO_TEST_HELPER_PROCESS=1",
+		"STDOUT=" + stdout,
+		"EXIT_CODE=" + strconv.Itoa(mockedExitCode),
+	}
+
+	return cmd
+}
+
+// TestExecCommandHelper is a helper test case that will be called by `mockedExecCommand`.
+// This workaround is needed to be able to "mock" system calls.
+func TestExecCommandHelper(t *testing.T) {
+	// Not executed by the mocked command function, so return
+	if os.Getenv("GO_TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	_, _ = fmt.Fprint(os.Stdout, os.Getenv("STDOUT"))
+	exitCode, err := strconv.Atoi(os.Getenv("EXIT_CODE"))
+	require.NoError(t, err)
+
+	os.Exit(exitCode)
+}
+
+// logRecord returns a commit formatted as the log command of the client does.
+func logRecord(hash string, date string, ref string, message string) string {
+	return strings.Join([]string{hash, date, ref, message}, "\x1f") + "\x1e\n"
+}
+
+func newFetcher(t *testing.T, repositories ...string) client.Fetcher {
+	if len(repositories) == 0 {
+		repositories = []string{"/src/minutes"}
+	}
+
+	fetcher, err := git.NewFetcher(&git.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
+		},
+		CLIClient: client.CLIClient{
+			Command:            "git",
+			CommandArguments:   []string{"--first-parent"},
+			CommandCtxExecutor: mockedExecCommand,
+		},
+		Repositories: repositories,
+		Client:       "My Awesome Company",
+		SessionGap:   time.Hour,
+		LeadIn:       30 * time.Minute,
+		TaskRegex:    git.DefaultTaskRegex,
+	})
+	require.Nil(t, err)
+
+	return fetcher
+}
+
+func TestGitClient_FetchEntries(t *testing.T) {
+	mockedExitCode = 0
+	mockedStdout = strings.Join([]string{
+		logRecord("c4", "2021-10-12T15:00:00+02:00", "refs/heads/main", "Fix typo\n"),
+		logRecord("c3", "2021-10-12T11:00:00Z", "refs/heads/feature/MIN-2-ical", "Add target\n\nAlso fixes MIN-3.\n"),
+		logRecord("c2", "2021-10-12T09:45:00Z", "refs/heads/feature/MIN-1-git", "Parse log\n"),
+		logRecord("c1", "2021-10-12T09:00:00Z", "refs/heads/feature/MIN-1-git", "Add source\n"),
+		logRecord("c0", "2021-10-11T09:00:00Z", "refs/heads/main", "Out of range\n"),
+	}, "")
+
+	fetcher := newFetcher(t)
+	entries, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+		User:  "steve@example.com",
+	})
+	require.Nil(t, err)
+
+	require.Equal(t, []string{
+		"-C", "/src/minutes", "log", "--all", "--source", "--no-merges", "--no-color",
+		"--format=%H%x1f%aI%x1f%S%x1f%B%x1e",
+		"--since=2021-10-12T00:00:00Z",
+		"--author=steve@example.com",
+		"--first-parent",
+	}, mockedArguments)
+
+	companyClient := worklog.IDNameField{ID: "My Awesome Company", Name: "My Awesome Company"}
+	project := worklog.IDNameField{ID: "minutes", Name: "minutes"}
+
+	require.Equal(t, worklog.Entries{
+		{
+			Client:           companyClient,
+			Project:          project,
+			Task:             worklog.IDNameField{ID: "MIN-1", Name: "MIN-1"},
+			Summary:          "Add source; Parse log",
+			Notes:            "Add source; Parse log",
+			Tags:             []worklog.IDNameField{{ID: "MIN-1", Name: "MIN-1"}},
+			Start:            time.Date(2021, 10, 12, 8, 30, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 9, 45, 0, 0, time.UTC),
+			BillableDuration: 75 * time.Minute,
+			Source:           git.SourceName,
+			SourceID:         "c1",
+		},
+		{
+			Client:           companyClient,
+			Project:          project,
+			Task:             worklog.IDNameField{ID: "MIN-2", Name: "MIN-2"},
+			Summary:          "Add target",
+			Notes:            "Add target",
+			Tags:             []worklog.IDNameField{{ID: "MIN-2", Name: "MIN-2"}, {ID: "MIN-3", Name: "MIN-3"}},
+			Start:            time.Date(2021, 10, 12, 10, 30, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 11, 0, 0, 0, time.UTC),
+			BillableDuration: 15 * time.Minute,
+			Source:           git.SourceName,
+			SourceID:         "c3",
+		},
+		{
+			Client:           companyClient,
+			Project:          project,
+			Task:             worklog.IDNameField{ID: "MIN-3", Name: "MIN-3"},
+			Summary:          "Add target",
+			Notes:            "Add target",
+			Tags:             []worklog.IDNameField{{ID: "MIN-2", Name: "MIN-2"}, {ID: "MIN-3", Name: "MIN-3"}},
+			Start:            time.Date(2021, 10, 12, 10, 30, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 11, 0, 0, 0, time.UTC),
+			BillableDuration: 15 * time.Minute,
+			Source:           git.SourceName,
+			SourceID:         "c3",
+		},
+		{
+			Client:           companyClient,
+			Project:          project,
+			Summary:          "Fix typo",
+			Notes:            "Fix typo",
+			Start:            time.Date(2021, 10, 12, 12, 30, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 13, 0, 0, 0, time.UTC),
+			BillableDuration: 30 * time.Minute,
+			Source:           git.SourceName,
+			SourceID:         "c4",
+		},
+	}, entries)
+}
+
+func TestGitClient_FetchEntries_MultipleRepositories(t *testing.T) {
+	mockedExitCode = 0
+	mockedStdouts = map[string]string{
+		"/src/minutes": strings.Join([]string{
+			logRecord("m2", "2021-10-12T10:30:00Z", "refs/heads/main", "Use the new API\n"),
+			logRecord("m1", "2021-10-12T09:00:00Z", "refs/heads/main", "Add source\n"),
+		}, ""),
+		"/src/minutes-api": strings.Join([]string{
+			logRecord("a1", "2021-10-12T10:00:00Z", "refs/heads/main", "Add API\n"),
+		}, ""),
+	}
+	defer func() { mockedStdouts = nil }()
+
+	fetcher := newFetcher(t, "/src/minutes", "/src/minutes-api")
+	entries, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+	})
+	require.Nil(t, err)
+
+	companyClient := worklog.IDNameField{ID: "My Awesome Company", Name: "My Awesome Company"}
+	minutes := worklog.IDNameField{ID: "minutes", Name: "minutes"}
+	minutesAPI := worklog.IDNameField{ID: "minutes-api", Name: "minutes-api"}
+
+	// The session is split between the repositories instead of overlapping
+	require.Equal(t, worklog.Entries{
+		{
+			Client:           companyClient,
+			Project:          minutes,
+			Summary:          "Add source",
+			Notes:            "Add source",
+			Start:            time.Date(2021, 10, 12, 8, 30, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC),
+			BillableDuration: 30 * time.Minute,
+			Source:           git.SourceName,
+			SourceID:         "m1",
+		},
+		{
+			Client:           companyClient,
+			Project:          minutesAPI,
+			Summary:          "Add API",
+			Notes:            "Add API",
+			Start:            time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 10, 0, 0, 0, time.UTC),
+			BillableDuration: time.Hour,
+			Source:           git.SourceName,
+			SourceID:         "a1",
+		},
+		{
+			Client:           companyClient,
+			Project:          minutes,
+			Summary:          "Use the new API",
+			Notes:            "Use the new API",
+			Start:            time.Date(2021, 10, 12, 10, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 10, 30, 0, 0, time.UTC),
+			BillableDuration: 30 * time.Minute,
+			Source:           git.SourceName,
+			SourceID:         "m2",
+		},
+	}, entries)
+}
+
+func TestGitClient_FetchEntries_Error(t *testing.T) {
+	mockedExitCode = 128
+	mockedStdout = ""
+
+	fetcher := newFetcher(t)
+	_, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorContains(t, err, client.ErrFetchEntries.Error())
+}
+
+func TestNewFetcher_NoRepositories(t *testing.T) {
+	_, err := git.NewFetcher(&git.ClientOpts{})
+	require.ErrorContains(t, err, client.ErrFetchEntries.Error())
+}
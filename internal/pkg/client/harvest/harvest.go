@@ -3,9 +3,11 @@ package harvest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 	"time"
 
@@ -15,56 +17,162 @@ import (
 )
 
 const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "harvest"
 	// PathWorklog is the endpoint used to search existing worklogs.
 	PathWorklog string = "/v2/time_entries"
 )
 
+var (
+	// ErrInvalidTimeOfDay returns when the started or ended time of an entry
+	// cannot be parsed.
+	ErrInvalidTimeOfDay = errors.New("invalid time of day")
+)
+
+// ExternalReference represents the item of an integrated service, like a Jira
+// issue, the entry is linked to.
+type ExternalReference struct {
+	ID        string `json:"id"`
+	GroupID   string `json:"group_id"`
+	Permalink string `json:"permalink"`
+	Service   string `json:"service"`
+}
+
+// Task returns the referenced item as a task. The name of the task is the last
+// part of the permalink, e.g. the issue key of a Jira issue, or the ID if the
+// reference has no permalink.
+func (r *ExternalReference) Task() worklog.IDNameField {
+	name := r.ID
+
+	if permalink, err := url.Parse(r.Permalink); err == nil {
+		if key := path.Base(permalink.Path); key != "." && key != "/" {
+			name = key
+		}
+	}
+
+	return worklog.IDNameField{
+		ID:   r.ID,
+		Name: name,
+	}
+}
+
 // FetchEntry represents the entry fetched from Harvest.
 type FetchEntry struct {
-	Client    worklog.IntIDNameField `json:"client"`
-	Project   worklog.IntIDNameField `json:"project"`
-	Task      worklog.IntIDNameField `json:"task"`
-	Notes     string                 `json:"notes"`
-	SpentDate string                 `json:"spent_date"`
-	Hours     float32                `json:"hours"`
-	CreatedAt time.Time              `json:"created_at"`
-	Billable  bool                   `json:"billable"`
-	IsRunning bool                   `json:"is_running"`
-}
-
-// Start returns the start date created from the spent date and created at.
-// The spent date represents the date the user wants the entry to be logged,
-// e.g: 2021-10-01. The creation date represents the actual creation of the
-// entry, e.g: 2021-10-02T10:26:20Z. Since Harvest is not precise with the
-// spent date, we have to create a start date from these two entries. This is
-// needed, because if the user is manually creating an entry, and creates on
-// a wrong date accidentally, after editing the entry, the spent date will be
-// updated, though the creation date not.
-func (e *FetchEntry) Start() (time.Time, error) {
-	spentDate, err := utils.DateFormatISO8601.Parse(e.SpentDate)
+	ID                int                    `json:"id"`
+	Client            worklog.IntIDNameField `json:"client"`
+	Project           worklog.IntIDNameField `json:"project"`
+	Task              worklog.IntIDNameField `json:"task"`
+	Notes             string                 `json:"notes"`
+	SpentDate         string                 `json:"spent_date"`
+	StartedTime       string                 `json:"started_time,omitempty"`
+	EndedTime         string                 `json:"ended_time,omitempty"`
+	TimerStartedAt    *time.Time             `json:"timer_started_at,omitempty"`
+	Hours             float32                `json:"hours"`
+	RoundedHours      float32                `json:"rounded_hours"`
+	ExternalReference *ExternalReference     `json:"external_reference,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"`
+	Billable          bool                   `json:"billable"`
+	IsRunning         bool                   `json:"is_running"`
+}
+
+// parseTimeOfDay returns the time on the spent date. Harvest returns the time
+// of day in the time format set for the account, like "8:00am" or "08:00".
+func (e *FetchEntry) parseTimeOfDay(timeOfDay string, loc *time.Location) (time.Time, error) {
+	spentDate, err := utils.DateFormatISO8601.ParseInLocation(e.SpentDate, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range []string{"3:04pm", "15:04"} {
+		if t, err := time.Parse(layout, timeOfDay); err == nil {
+			return time.Date(spentDate.Year(), spentDate.Month(), spentDate.Day(), t.Hour(), t.Minute(), 0, 0, loc), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%v: %s", ErrInvalidTimeOfDay, timeOfDay)
+}
+
+// Start returns the start date of the entry. If the account tracks time via
+// start and end times, the started time on the spent date is used. Otherwise,
+// the time when the timer was started is used if the entry was tracked by a
+// timer. If none of them are set, the start date is created from the spent
+// date and the time of day of the creation date. Since Harvest is not precise
+// with the spent date, the creation date cannot be used as is: if the user
+// creates an entry manually on a wrong date accidentally, after editing the
+// entry, the spent date will be updated, though the creation date not.
+func (e *FetchEntry) Start(loc *time.Location) (time.Time, error) {
+	if e.StartedTime != "" {
+		return e.parseTimeOfDay(e.StartedTime, loc)
+	}
+
+	if e.TimerStartedAt != nil {
+		return e.TimerStartedAt.In(loc), nil
+	}
+
+	spentDate, err := utils.DateFormatISO8601.ParseInLocation(e.SpentDate, loc)
 	if err != nil {
 		return time.Time{}, err
 	}
 
+	createdAt := e.CreatedAt.In(loc)
+
 	return time.Date(
 		spentDate.Year(),
 		spentDate.Month(),
 		spentDate.Day(),
-		e.CreatedAt.Hour(),
-		e.CreatedAt.Minute(),
-		e.CreatedAt.Second(),
-		e.CreatedAt.Nanosecond(),
-		e.CreatedAt.Location(),
+		createdAt.Hour(),
+		createdAt.Minute(),
+		createdAt.Second(),
+		createdAt.Nanosecond(),
+		loc,
 	), nil
 }
 
+// End returns the end date of the entry. If the account tracks time via start
+// and end times, the ended time on the spent date is used, otherwise the end
+// is calculated from the start and the duration of the entry.
+func (e *FetchEntry) End(start time.Time, duration time.Duration) (time.Time, error) {
+	if e.EndedTime == "" {
+		return start.Add(duration), nil
+	}
+
+	end, err := e.parseTimeOfDay(e.EndedTime, start.Location())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// The entry was tracked over midnight
+	if end.Before(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return end, nil
+}
+
+// Duration returns the tracked duration of the entry. If rounded is set, the
+// hours rounded by the rounding settings of Harvest are used.
+func (e *FetchEntry) Duration(rounded bool) (time.Duration, error) {
+	hours := e.Hours
+	if rounded {
+		hours = e.RoundedHours
+	}
+
+	return time.ParseDuration(fmt.Sprintf("%fh", hours))
+}
+
+// Links represents the pagination links of the response.
+type Links struct {
+	Next string `json:"next,omitempty"`
+}
+
 // FetchResponse represents the relevant response data.
-// Although the response contains a lot more information about pagination, it
-// cannot be used with the current structure.
+// Although the response contains a lot more information about pagination, we
+// only need the link of the next page to navigate between pages.
 type FetchResponse struct {
 	TimeEntries  []FetchEntry `json:"time_entries"`
 	PerPage      int          `json:"per_page"`
 	TotalEntries int          `json:"total_entries"`
+	Links        Links        `json:"links"`
 }
 
 // ClientOpts is the client specific options, extending client.BaseClientOpts.
@@ -73,6 +181,9 @@ type ClientOpts struct {
 	client.TokenAuth
 	BaseURL string
 	Account int
+	// RoundedHours sets to use the hours rounded by Harvest instead of the
+	// tracked hours.
+	RoundedHours bool
 }
 
 type harvestClient struct {
@@ -80,9 +191,10 @@ type harvestClient struct {
 	*client.HTTPClient
 	authenticator client.Authenticator
 	account       int
+	roundedHours  bool
 }
 
-func (c *harvestClient) parseEntries(rawEntries interface{}, _ *client.FetchOpts) (worklog.Entries, error) {
+func (c *harvestClient) parseEntries(rawEntries interface{}, opts *client.FetchOpts) (worklog.Entries, error) {
 	var entries worklog.Entries
 
 	fetchedEntries, ok := rawEntries.([]FetchEntry)
@@ -91,16 +203,32 @@ func (c *harvestClient) parseEntries(rawEntries interface{}, _ *client.FetchOpts
 	}
 
 	for _, fetchedEntry := range fetchedEntries {
-		startDate, err := fetchedEntry.Start()
+		startDate, err := fetchedEntry.Start(c.Location())
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+
+		billableDuration, err := fetchedEntry.Duration(c.roundedHours)
 		if err != nil {
 			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
 		}
 
-		billableDuration, err := time.ParseDuration(fmt.Sprintf("%fh", fetchedEntry.Hours))
+		endDate, err := fetchedEntry.End(startDate, billableDuration)
 		if err != nil {
 			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
 		}
 
+		// The hours of running entries are updated by Harvest until the time
+		// of fetching, hence only the end must be set
+		if fetchedEntry.IsRunning {
+			var keep bool
+			if endDate, keep, err = opts.RunningEntryEnd(startDate); err != nil {
+				return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+			} else if !keep {
+				continue
+			}
+		}
+
 		unbillableDuration := time.Duration(0)
 
 		if !fetchedEntry.Billable {
@@ -108,15 +236,26 @@ func (c *harvestClient) parseEntries(rawEntries interface{}, _ *client.FetchOpts
 			billableDuration = 0
 		}
 
+		// The referenced item, like a Jira issue, is more specific than the
+		// task of the project
+		task := fetchedEntry.Task.ConvertToIDNameField()
+		if fetchedEntry.ExternalReference != nil && fetchedEntry.ExternalReference.ID != "" {
+			task = fetchedEntry.ExternalReference.Task()
+		}
+
 		entries = append(entries, worklog.Entry{
 			Client:             fetchedEntry.Client.ConvertToIDNameField(),
 			Project:            fetchedEntry.Project.ConvertToIDNameField(),
-			Task:               fetchedEntry.Task.ConvertToIDNameField(),
+			Task:               task,
 			Summary:            fetchedEntry.Notes,
 			Notes:              fetchedEntry.Notes,
 			Start:              startDate,
+			End:                endDate,
 			BillableDuration:   billableDuration,
 			UnbillableDuration: unbillableDuration,
+			Source:             SourceName,
+			SourceID:           strconv.Itoa(fetchedEntry.ID),
+			Running:            fetchedEntry.IsRunning,
 		})
 	}
 
@@ -144,33 +283,47 @@ func (c *harvestClient) fetchEntries(ctx context.Context, reqURL string) (interf
 	}
 
 	paginatedResponse := &client.PaginatedFetchResponse{
+		PageEntries:    len(fetchResponse.TimeEntries),
 		EntriesPerPage: fetchResponse.PerPage,
 		TotalEntries:   fetchResponse.TotalEntries,
+		NextURL:        fetchResponse.Links.Next,
 	}
 
 	return fetchResponse.TimeEntries, paginatedResponse, err
 }
 
-func (c *harvestClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
-	fetchURL, err := c.URL(PathWorklog, map[string]string{
-		"from":       utils.DateFormatRFC3339UTC.Format(opts.Start),
-		"to":         utils.DateFormatRFC3339UTC.Format(opts.End),
+func (c *harvestClient) FetchEntriesStream(ctx context.Context, opts *client.FetchOpts) <-chan client.FetchPage {
+	params := map[string]string{
+		"from":       utils.DateFormatISO8601.FormatInLocation(opts.Start, c.Location()),
+		"to":         utils.DateFormatISO8601.FormatInLocation(opts.End, c.Location()),
 		"user_id":    opts.User,
-		"is_running": strconv.FormatBool(false),
 		"user_agent": "github.com/gabor-boros/minutes",
-	})
+	}
+
+	// The running entries are needed unless they are skipped anyway
+	if opts.SkipsRunningEntries() {
+		params["is_running"] = strconv.FormatBool(false)
+	}
+
+	fetchURL, err := c.URL(PathWorklog, params)
 
 	if err != nil {
-		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		return client.FailedFetchStream(fmt.Errorf("%v: %v", client.ErrFetchEntries, err))
 	}
 
-	return c.PaginatedFetch(ctx, &client.PaginatedFetchOpts{
-		URL:       fetchURL,
-		FetchFunc: c.fetchEntries,
-		ParseFunc: c.parseEntries,
+	return c.PaginatedFetchStream(ctx, &client.PaginatedFetchOpts{
+		BaseFetchOpts: opts,
+		URL:           fetchURL,
+		Pagination:    &client.NextURLPagination{},
+		FetchFunc:     c.fetchEntries,
+		ParseFunc:     c.parseEntries,
 	})
 }
 
+func (c *harvestClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	return client.CollectFetchPages(ctx, c.FetchEntriesStream(ctx, opts))
+}
+
 // NewFetcher returns a new Clockify client for fetching entries.
 func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
 	baseURL, err := url.Parse(opts.BaseURL)
@@ -190,5 +343,6 @@ func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
 		},
 		authenticator: authenticator,
 		account:       opts.Account,
+		roundedHours:  opts.RoundedHours,
 	}, nil
 }
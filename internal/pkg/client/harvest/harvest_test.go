@@ -54,17 +54,125 @@ func newMockServer(t *testing.T, opts *mockServerOpts) *httptest.Server {
 }
 
 func TestFetchEntry_Start(t *testing.T) {
-	expectedStart := time.Date(2021, 9, 30, 23, 59, 59, 0, time.UTC)
-
 	entry := harvest.FetchEntry{
 		SpentDate: "2021-09-30",
 		CreatedAt: time.Date(2021, 10, 1, 23, 59, 59, 0, time.UTC),
 	}
 
-	startDate, err := entry.Start()
+	startDate, err := entry.Start(time.UTC)
+
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 9, 30, 23, 59, 59, 0, time.UTC), startDate)
+
+	// The time of day is taken in the location of the user
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	startDate, err = entry.Start(loc)
+
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 9, 30, 1, 59, 59, 0, loc), startDate)
+}
+
+func TestFetchEntry_Start_StartedTime(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+
+	for _, startedTime := range []string{"2:30pm", "14:30"} {
+		entry := harvest.FetchEntry{
+			SpentDate:   "2021-09-30",
+			StartedTime: startedTime,
+			CreatedAt:   time.Date(2021, 10, 1, 23, 59, 59, 0, time.UTC),
+		}
+
+		startDate, err := entry.Start(loc)
+
+		require.Nil(t, err)
+		require.Equal(t, time.Date(2021, 9, 30, 14, 30, 0, 0, loc), startDate)
+	}
+}
+
+func TestFetchEntry_Start_InvalidStartedTime(t *testing.T) {
+	entry := harvest.FetchEntry{
+		SpentDate:   "2021-09-30",
+		StartedTime: "half past two",
+	}
+
+	_, err := entry.Start(time.UTC)
+	require.ErrorContains(t, err, harvest.ErrInvalidTimeOfDay.Error())
+}
+
+func TestFetchEntry_Start_TimerStartedAt(t *testing.T) {
+	timerStartedAt := time.Date(2021, 9, 30, 8, 15, 0, 0, time.UTC)
+
+	entry := harvest.FetchEntry{
+		SpentDate:      "2021-09-30",
+		TimerStartedAt: &timerStartedAt,
+		CreatedAt:      time.Date(2021, 10, 1, 23, 59, 59, 0, time.UTC),
+	}
+
+	startDate, err := entry.Start(time.UTC)
+
+	require.Nil(t, err)
+	require.Equal(t, timerStartedAt, startDate)
+}
+
+func TestFetchEntry_End(t *testing.T) {
+	start := time.Date(2021, 9, 30, 14, 30, 0, 0, time.UTC)
+
+	entry := harvest.FetchEntry{
+		SpentDate: "2021-09-30",
+	}
+
+	endDate, err := entry.End(start, time.Hour)
+	require.Nil(t, err)
+	require.Equal(t, start.Add(time.Hour), endDate)
+
+	entry.EndedTime = "4:00pm"
+	endDate, err = entry.End(start, time.Hour)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 9, 30, 16, 0, 0, 0, time.UTC), endDate)
+}
+
+func TestFetchEntry_End_OverMidnight(t *testing.T) {
+	entry := harvest.FetchEntry{
+		SpentDate:   "2021-09-30",
+		StartedTime: "23:00",
+		EndedTime:   "01:30",
+	}
+
+	startDate, err := entry.Start(time.UTC)
+	require.Nil(t, err)
+
+	endDate, err := entry.End(startDate, time.Hour*2+time.Minute*30)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 1, 1, 30, 0, 0, time.UTC), endDate)
+}
+
+func TestFetchEntry_Duration(t *testing.T) {
+	entry := harvest.FetchEntry{
+		Hours:        1.2,
+		RoundedHours: 1.25,
+	}
+
+	duration, err := entry.Duration(false)
+	require.Nil(t, err)
+	require.Equal(t, time.Hour+time.Minute*12, duration)
 
+	duration, err = entry.Duration(true)
 	require.Nil(t, err)
-	require.Equal(t, expectedStart, startDate)
+	require.Equal(t, time.Hour+time.Minute*15, duration)
+}
+
+func TestExternalReference_Task(t *testing.T) {
+	reference := harvest.ExternalReference{
+		ID:        "10010",
+		GroupID:   "10000",
+		Permalink: "https://example.atlassian.net/browse/CPT-2014",
+		Service:   "example.atlassian.net",
+	}
+
+	require.Equal(t, worklog.IDNameField{ID: "10010", Name: "CPT-2014"}, reference.Task())
+
+	reference.Permalink = ""
+	require.Equal(t, worklog.IDNameField{ID: "10010", Name: "10010"}, reference.Task())
 }
 
 func TestHarvestClient_FetchEntries(t *testing.T) {
@@ -88,8 +196,11 @@ func TestHarvestClient_FetchEntries(t *testing.T) {
 			Summary:            "I met with The Winter Soldier",
 			Notes:              "I met with The Winter Soldier",
 			Start:              start,
+			End:                start.Add(time.Hour * 2),
 			BillableDuration:   time.Hour * 2,
 			UnbillableDuration: 0,
+			Source:             harvest.SourceName,
+			SourceID:           "1",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -107,18 +218,20 @@ func TestHarvestClient_FetchEntries(t *testing.T) {
 			Summary:            "I helped him to get back on track",
 			Notes:              "I helped him to get back on track",
 			Start:              start,
+			End:                start.Add(time.Hour * 3),
 			BillableDuration:   0,
 			UnbillableDuration: time.Hour * 3,
+			Source:             harvest.SourceName,
+			SourceID:           "2",
 		},
 	}
 
 	mockServer := newMockServer(t, &mockServerOpts{
 		Path: harvest.PathWorklog,
 		QueryParams: url.Values{
-			"page":       {"1"},
 			"per_page":   {"50"},
-			"from":       {utils.DateFormatRFC3339UTC.Format(start)},
-			"to":         {utils.DateFormatRFC3339UTC.Format(end)},
+			"from":       {"2021-10-02"},
+			"to":         {"2021-10-02"},
 			"user_id":    {"987654321"},
 			"is_running": {"false"},
 			"user_agent": {"github.com/gabor-boros/minutes"},
@@ -130,6 +243,7 @@ func TestHarvestClient_FetchEntries(t *testing.T) {
 		ResponseData: &harvest.FetchResponse{
 			TimeEntries: []harvest.FetchEntry{
 				{
+					ID: 1,
 					Client: worklog.IntIDNameField{
 						ID:   1,
 						Name: "My Awesome Company",
@@ -150,6 +264,7 @@ func TestHarvestClient_FetchEntries(t *testing.T) {
 					IsRunning: false,
 				},
 				{
+					ID: 2,
 					Client: worklog.IntIDNameField{
 						ID:   1,
 						Name: "My Awesome Company",
@@ -178,7 +293,8 @@ func TestHarvestClient_FetchEntries(t *testing.T) {
 
 	harvestClient, err := harvest.NewFetcher(&harvest.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
 		},
 		TokenAuth: client.TokenAuth{
 			Header:    "Authorization",
@@ -199,3 +315,165 @@ func TestHarvestClient_FetchEntries(t *testing.T) {
 	require.Nil(t, err, "cannot fetch entries")
 	require.ElementsMatch(t, expectedEntries, entries, "fetched entries are not matching")
 }
+
+func TestHarvestClient_FetchEntries_Timestamps(t *testing.T) {
+	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 2, 23, 59, 59, 0, time.UTC)
+
+	expectedEntries := worklog.Entries{
+		{
+			Client: worklog.IDNameField{
+				ID:   "1",
+				Name: "My Awesome Company",
+			},
+			Project: worklog.IDNameField{
+				ID:   "11",
+				Name: "MARVEL",
+			},
+			Task: worklog.IDNameField{
+				ID:   "10010",
+				Name: "CPT-2014",
+			},
+			Summary:            "I met with The Winter Soldier",
+			Notes:              "I met with The Winter Soldier",
+			Start:              time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC),
+			End:                time.Date(2021, 10, 2, 10, 10, 0, 0, time.UTC),
+			BillableDuration:   time.Hour + time.Minute*15,
+			UnbillableDuration: 0,
+			Source:             harvest.SourceName,
+			SourceID:           "1",
+		},
+	}
+
+	mockServer := newMockServer(t, &mockServerOpts{
+		Path: harvest.PathWorklog,
+		QueryParams: url.Values{
+			"per_page":   {"50"},
+			"from":       {"2021-10-02"},
+			"to":         {"2021-10-02"},
+			"user_id":    {"987654321"},
+			"is_running": {"false"},
+			"user_agent": {"github.com/gabor-boros/minutes"},
+		},
+		Method:      http.MethodGet,
+		StatusCode:  http.StatusOK,
+		Token:       "Bearer t-o-k-e-n",
+		TokenHeader: "Authorization",
+		ResponseData: &harvest.FetchResponse{
+			TimeEntries: []harvest.FetchEntry{
+				{
+					ID: 1,
+					Client: worklog.IntIDNameField{
+						ID:   1,
+						Name: "My Awesome Company",
+					},
+					Project: worklog.IntIDNameField{
+						ID:   11,
+						Name: "MARVEL",
+					},
+					Task: worklog.IntIDNameField{
+						ID:   111,
+						Name: "Development",
+					},
+					Notes:        "I met with The Winter Soldier",
+					SpentDate:    utils.DateFormatISO8601.Format(start),
+					StartedTime:  "9:00am",
+					EndedTime:    "10:10am",
+					Hours:        1.17,
+					RoundedHours: 1.25,
+					ExternalReference: &harvest.ExternalReference{
+						ID:        "10010",
+						GroupID:   "10000",
+						Permalink: "https://example.atlassian.net/browse/CPT-2014",
+						Service:   "example.atlassian.net",
+					},
+					CreatedAt: start.Add(time.Hour * 12),
+					Billable:  true,
+					IsRunning: false,
+				},
+			},
+			PerPage:      50,
+			TotalEntries: 1,
+		},
+	})
+	defer mockServer.Close()
+
+	harvestClient, err := harvest.NewFetcher(&harvest.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
+		},
+		TokenAuth: client.TokenAuth{
+			Header:    "Authorization",
+			TokenName: "Bearer",
+			Token:     "t-o-k-e-n",
+		},
+		BaseURL:      mockServer.URL,
+		Account:      123456789,
+		RoundedHours: true,
+	})
+	require.Nil(t, err)
+
+	entries, err := harvestClient.FetchEntries(context.Background(), &client.FetchOpts{
+		User:  "987654321",
+		Start: start,
+		End:   end,
+	})
+
+	require.Nil(t, err, "cannot fetch entries")
+	require.ElementsMatch(t, expectedEntries, entries, "fetched entries are not matching")
+}
+
+func TestHarvestClient_FetchEntries_Timezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.Nil(t, err)
+
+	// The days in Tokyo are starting on the previous day in UTC
+	start := time.Date(2021, 10, 2, 0, 0, 0, 0, tokyo)
+	end := time.Date(2021, 10, 3, 0, 0, 0, 0, tokyo)
+
+	mockServer := newMockServer(t, &mockServerOpts{
+		Path: harvest.PathWorklog,
+		QueryParams: url.Values{
+			"per_page":   {"50"},
+			"from":       {"2021-10-02"},
+			"to":         {"2021-10-03"},
+			"user_id":    {"987654321"},
+			"is_running": {"false"},
+			"user_agent": {"github.com/gabor-boros/minutes"},
+		},
+		Method:      http.MethodGet,
+		StatusCode:  http.StatusOK,
+		Token:       "Bearer t-o-k-e-n",
+		TokenHeader: "Authorization",
+		ResponseData: &harvest.FetchResponse{
+			TimeEntries: []harvest.FetchEntry{},
+			PerPage:     50,
+		},
+	})
+	defer mockServer.Close()
+
+	harvestClient, err := harvest.NewFetcher(&harvest.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: tokyo,
+		},
+		TokenAuth: client.TokenAuth{
+			Header:    "Authorization",
+			TokenName: "Bearer",
+			Token:     "t-o-k-e-n",
+		},
+		BaseURL: mockServer.URL,
+		Account: 123456789,
+	})
+	require.Nil(t, err)
+
+	entries, err := harvestClient.FetchEntries(context.Background(), &client.FetchOpts{
+		User:  "987654321",
+		Start: start,
+		End:   end,
+	})
+
+	require.Nil(t, err, "cannot fetch entries")
+	require.Empty(t, entries)
+}
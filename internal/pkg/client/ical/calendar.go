@@ -0,0 +1,427 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// StatusCancelled is the status of the cancelled events.
+	StatusCancelled string = "CANCELLED"
+	// PartStatDeclined is the participation status of the declined attendees.
+	PartStatDeclined string = "DECLINED"
+
+	dateLayout     string = "20060102"
+	dateTimeLayout string = "20060102T150405"
+)
+
+var (
+	// ErrInvalidCalendar returns when the calendar cannot be parsed.
+	ErrInvalidCalendar = errors.New("invalid calendar")
+	// ErrInvalidDuration returns when a duration value cannot be parsed.
+	ErrInvalidDuration = errors.New("invalid duration")
+)
+
+// Attendee represents an attendee of the event.
+type Attendee struct {
+	Email    string
+	PartStat string
+}
+
+// Event represents a VEVENT of the calendar. The dates are parsed in the
+// timezone set by their TZID parameter, or in the location of the client if
+// they have no timezone information.
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Categories   []string
+	Status       string
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	Attendees    []Attendee
+	Recurrence   *RecurrenceRule
+	ExDates      []time.Time
+	RecurrenceID time.Time
+}
+
+// IsDeclinedBy returns true if the attendee with the given email declined the
+// event. The emails are compared case-insensitively.
+func (e *Event) IsDeclinedBy(email string) bool {
+	for _, attendee := range e.Attendees {
+		if strings.EqualFold(attendee.Email, email) {
+			return strings.EqualFold(attendee.PartStat, PartStatDeclined)
+		}
+	}
+
+	return false
+}
+
+// property represents a content line of the calendar, like
+// "DTSTART;TZID=Europe/Berlin:20211012T090000".
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// unfoldLines reads the content lines of the calendar, joining the folded
+// lines starting with a whitespace to the previous line.
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseProperty parses the content line. The separators within quoted
+// parameter values are not treated as separators.
+func parseProperty(line string) (property, error) {
+	prop := property{params: map[string]string{}}
+
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+
+	for i, char := range line {
+		switch {
+		case char == '"':
+			inQuotes = !inQuotes
+		case char == ';' && !inQuotes:
+			parts = append(parts, current.String())
+			current.Reset()
+		case char == ':' && !inQuotes:
+			parts = append(parts, current.String())
+			prop.name = strings.ToUpper(parts[0])
+
+			for _, param := range parts[1:] {
+				key, value, _ := strings.Cut(param, "=")
+				prop.params[strings.ToUpper(key)] = value
+			}
+
+			prop.value = line[i+1:]
+			return prop, nil
+		default:
+			current.WriteRune(char)
+		}
+	}
+
+	return prop, fmt.Errorf("%v: %s", ErrInvalidCalendar, line)
+}
+
+// unescapeText unescapes the TEXT value as defined by RFC 5545.
+func unescapeText(value string) string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n")
+	return replacer.Replace(value)
+}
+
+// splitText splits the list of TEXT values by the not escaped commas, and
+// unescapes the values.
+func splitText(value string) []string {
+	var values []string
+	var current strings.Builder
+	escaped := false
+
+	for _, char := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == ',':
+			values = append(values, unescapeText(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(char)
+		}
+	}
+
+	return append(values, unescapeText(current.String()))
+}
+
+// parseLocation returns the location of the TZID parameter. The TZID must be
+// an IANA timezone name, otherwise the fallback location returns.
+func parseLocation(params map[string]string, fallback *time.Location) *time.Location {
+	tzid := strings.TrimPrefix(strings.Trim(params["TZID"], `"`), "/")
+	if tzid == "" {
+		return fallback
+	}
+
+	if loc, err := time.LoadLocation(tzid); err == nil {
+		return loc
+	}
+
+	return fallback
+}
+
+// parseDateTime parses the DATE or DATE-TIME value. For DATE values, true
+// returns as the value represents a whole day.
+func parseDateTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.ParseInLocation(dateTimeLayout, strings.TrimSuffix(value, "Z"), time.UTC)
+		return t, false, err
+	}
+
+	t, err := time.ParseInLocation(dateTimeLayout, value, parseLocation(params, loc))
+	return t, false, err
+}
+
+// parseDuration parses the DURATION value, like "PT1H30M" or "P1D".
+func parseDuration(value string) (time.Duration, error) {
+	var duration time.Duration
+
+	rest := strings.TrimPrefix(value, "+")
+	negative := strings.HasPrefix(rest, "-")
+	rest = strings.TrimPrefix(rest, "-")
+
+	if !strings.HasPrefix(rest, "P") || len(rest) == 1 {
+		return 0, fmt.Errorf("%v: %s", ErrInvalidDuration, value)
+	}
+
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+
+	number := ""
+	for _, char := range []byte(rest[1:]) {
+		switch {
+		case char >= '0' && char <= '9':
+			number += string(char)
+		case char == 'T':
+			continue
+		default:
+			unit, ok := units[char]
+			if !ok || number == "" {
+				return 0, fmt.Errorf("%v: %s", ErrInvalidDuration, value)
+			}
+
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("%v: %s", ErrInvalidDuration, value)
+			}
+
+			duration += time.Duration(n) * unit
+			number = ""
+		}
+	}
+
+	if number != "" {
+		return 0, fmt.Errorf("%v: %s", ErrInvalidDuration, value)
+	}
+
+	if negative {
+		duration = -duration
+	}
+
+	return duration, nil
+}
+
+// setEventProperty sets the field of the event represented by the property.
+func setEventProperty(event *Event, prop property, duration *time.Duration, loc *time.Location) error {
+	var err error
+
+	switch prop.name {
+	case "UID":
+		event.UID = prop.value
+	case "SUMMARY":
+		event.Summary = unescapeText(prop.value)
+	case "DESCRIPTION":
+		event.Description = unescapeText(prop.value)
+	case "STATUS":
+		event.Status = strings.ToUpper(prop.value)
+	case "CATEGORIES":
+		for _, category := range splitText(prop.value) {
+			if category = strings.TrimSpace(category); category != "" {
+				event.Categories = append(event.Categories, category)
+			}
+		}
+	case "DTSTART":
+		event.Start, event.AllDay, err = parseDateTime(prop.value, prop.params, loc)
+	case "DTEND":
+		event.End, _, err = parseDateTime(prop.value, prop.params, loc)
+	case "DURATION":
+		*duration, err = parseDuration(prop.value)
+	case "ATTENDEE":
+		email := prop.value
+		if len(email) > len("mailto:") && strings.EqualFold(email[:len("mailto:")], "mailto:") {
+			email = email[len("mailto:"):]
+		}
+
+		event.Attendees = append(event.Attendees, Attendee{
+			Email:    email,
+			PartStat: strings.ToUpper(prop.params["PARTSTAT"]),
+		})
+	case "RRULE":
+		event.Recurrence, err = ParseRecurrenceRule(prop.value, loc)
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			exDate, _, err := parseDateTime(value, prop.params, loc)
+			if err != nil {
+				return err
+			}
+
+			event.ExDates = append(event.ExDates, exDate)
+		}
+	case "RECURRENCE-ID":
+		event.RecurrenceID, _, err = parseDateTime(prop.value, prop.params, loc)
+	}
+
+	return err
+}
+
+// ReadCalendar reads the events of the calendar. The components other than
+// VEVENT, like VTODO or VALARM, are skipped. If an event has no end, its end is
+// calculated from its duration, or it ends when it starts. The events repeating
+// by an unsupported recurrence rule are skipped, since their occurrences cannot
+// be expanded, but the rest of the calendar can be read. The UIDs of the
+// skipped events return, so they can be reported.
+func ReadCalendar(r io.Reader, loc *time.Location) ([]Event, []string, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var events []Event
+	var skippedUIDs []string
+	var event *Event
+	var duration time.Duration
+	var unsupported bool
+
+	// The components nested in the events, like alarms, are skipped
+	nestedDepth := 0
+
+	for _, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && event == nil:
+			event = &Event{}
+			duration = 0
+			unsupported = false
+		case prop.name == "BEGIN" && event != nil:
+			nestedDepth++
+		case prop.name == "END" && event != nil && nestedDepth > 0:
+			nestedDepth--
+		case prop.name == "END" && event != nil:
+			if event.Start.IsZero() {
+				return nil, nil, fmt.Errorf("%v: event %s has no start", ErrInvalidCalendar, event.UID)
+			}
+
+			if event.End.IsZero() {
+				event.End = event.Start.Add(duration)
+			}
+
+			if unsupported {
+				skippedUIDs = append(skippedUIDs, event.UID)
+			} else {
+				events = append(events, *event)
+			}
+
+			event = nil
+		case event != nil && nestedDepth == 0:
+			err = setEventProperty(event, prop, &duration, loc)
+			if errors.Is(err, ErrUnsupportedRecurrence) {
+				unsupported = true
+				continue
+			}
+
+			if err != nil {
+				return nil, nil, fmt.Errorf("%v: event %s: %v", ErrInvalidCalendar, event.UID, err)
+			}
+		}
+	}
+
+	return events, skippedUIDs, nil
+}
+
+// ReadPath reads the events of the calendar file, or the events of every
+// ".ics" file in the directory if the path is a directory. The UIDs of the
+// events skipped by ReadCalendar return too.
+func ReadPath(path string, loc *time.Location) ([]Event, []string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paths := []string{path}
+
+	if info.IsDir() {
+		dirEntries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		paths = []string{}
+		for _, dirEntry := range dirEntries {
+			if !dirEntry.IsDir() && strings.EqualFold(filepath.Ext(dirEntry.Name()), ".ics") {
+				paths = append(paths, filepath.Join(path, dirEntry.Name()))
+			}
+		}
+
+		sort.Strings(paths)
+	}
+
+	var events []Event
+	var skippedUIDs []string
+
+	for _, calendarPath := range paths {
+		calendarEvents, calendarSkippedUIDs, err := readFile(calendarPath, loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", calendarPath, err)
+		}
+
+		events = append(events, calendarEvents...)
+		skippedUIDs = append(skippedUIDs, calendarSkippedUIDs...)
+	}
+
+	return events, skippedUIDs, nil
+}
+
+func readFile(path string, loc *time.Location) ([]Event, []string, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer file.Close()
+
+	return ReadCalendar(file, loc)
+}
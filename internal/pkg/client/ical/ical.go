@@ -0,0 +1,320 @@
+package ical
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "ical"
+	// PathStdout is the path used to write the entries to the standard output.
+	PathStdout string = client.PathStdout
+)
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// Since the calendars are read from and written to the disk, the HTTP related
+// options are not used by the client. The timezone of the client is used for
+// the dates having no timezone information.
+type ClientOpts struct {
+	client.BaseClientOpts
+	// Path is the path of the calendar file, or a directory of calendar files
+	// having ".ics" extension. When used as a target, it must be a file, or
+	// PathStdout to write the calendar to the standard output.
+	Path                 string
+	UnbillableCategory   string
+	ClientCategoryRegex  string
+	ProjectCategoryRegex string
+	// CalendarName is the name of the written calendar, shown by calendar
+	// applications subscribing to the file.
+	CalendarName string
+	// Append indicates to merge the uploaded entries with the events of the
+	// existing file. The events of the entries uploaded again are replaced.
+	Append bool
+	// RotateMonthly indicates to write the uploaded entries to a file per
+	// month, like "worklog-2021-10.ics".
+	RotateMonthly bool
+	// Stdout is written when uploading to PathStdout, defaults to os.Stdout.
+	Stdout io.Writer
+	// Warnings is written with the UIDs of the events skipped when fetching,
+	// defaults to os.Stderr.
+	Warnings io.Writer
+}
+
+type icalClient struct {
+	*client.BaseClientOpts
+	*client.DefaultUploader
+	fileTarget           *client.FileTargetOpts
+	path                 string
+	calendarName         string
+	unbillableCategory   string
+	clientCategoryRegex  *regexp.Regexp
+	projectCategoryRegex *regexp.Regexp
+	warnings             io.Writer
+
+	// The calendars are read once, as the fetching may be split into windows
+	readOnce sync.Once
+	events   []Event
+	readErr  error
+}
+
+// occurrence is a single occurrence of the event.
+type occurrence struct {
+	event    *Event
+	start    time.Time
+	end      time.Time
+	sourceID string
+}
+
+// occurrences returns the occurrences of the events started within the date
+// range. The recurring events are expanded, while the excluded dates and the
+// occurrences modified by another event are skipped.
+func (c *icalClient) occurrences(start time.Time, end time.Time) []occurrence {
+	// The modified occurrences are stored as separate events having the UID of
+	// the recurring event, and the start of the replaced occurrence
+	modified := map[string][]time.Time{}
+	for _, event := range c.events {
+		if !event.RecurrenceID.IsZero() {
+			modified[event.UID] = append(modified[event.UID], event.RecurrenceID)
+		}
+	}
+
+	isExcluded := func(t time.Time, excluded []time.Time) bool {
+		for _, exclusion := range excluded {
+			if t.Equal(exclusion) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var occurrences []occurrence
+
+	for i := range c.events {
+		event := &c.events[i]
+		duration := event.End.Sub(event.Start)
+
+		if event.Recurrence == nil || !event.RecurrenceID.IsZero() {
+			sourceID := event.UID
+			if !event.RecurrenceID.IsZero() {
+				sourceID = fmt.Sprintf("%s/%s", event.UID, utils.DateFormatRFC3339Compact.Format(event.RecurrenceID))
+			}
+
+			if !event.Start.Before(start) && event.Start.Before(end) {
+				occurrences = append(occurrences, occurrence{event, event.Start, event.End, sourceID})
+			}
+
+			continue
+		}
+
+		for _, occurrenceStart := range event.Recurrence.Occurrences(event.Start, end) {
+			if occurrenceStart.Before(start) || isExcluded(occurrenceStart, event.ExDates) || isExcluded(occurrenceStart, modified[event.UID]) {
+				continue
+			}
+
+			occurrences = append(occurrences, occurrence{
+				event:    event,
+				start:    occurrenceStart,
+				end:      occurrenceStart.Add(duration),
+				sourceID: fmt.Sprintf("%s/%s", event.UID, utils.DateFormatRFC3339Compact.Format(occurrenceStart)),
+			})
+		}
+	}
+
+	return occurrences
+}
+
+func (c *icalClient) parseOccurrence(o occurrence, opts *client.FetchOpts) (worklog.Entries, error) {
+	endDate := o.end
+
+	// The meeting is in progress, so it is treated as a running entry
+	running := time.Now().Before(o.end)
+
+	if running {
+		var err error
+		var keep bool
+		if endDate, keep, err = opts.RunningEntryEnd(o.start); err != nil || !keep {
+			return nil, err
+		}
+	}
+
+	var tags []worklog.IDNameField
+	for _, category := range o.event.Categories {
+		tags = append(tags, worklog.IDNameField{
+			ID:   category,
+			Name: category,
+		})
+	}
+
+	notes := o.event.Description
+	if notes == "" {
+		notes = o.event.Summary
+	}
+
+	worklogEntry := worklog.Entry{
+		Summary:            o.event.Summary,
+		Notes:              notes,
+		Tags:               tags,
+		Start:              o.start,
+		End:                endDate,
+		BillableDuration:   endDate.Sub(o.start),
+		UnbillableDuration: 0,
+		Source:             SourceName,
+		SourceID:           o.sourceID,
+		Running:            running,
+	}
+
+	for _, category := range o.event.Categories {
+		if category == c.unbillableCategory {
+			worklogEntry.UnbillableDuration = worklogEntry.BillableDuration
+			worklogEntry.BillableDuration = 0
+		} else if utils.IsRegexSet(c.clientCategoryRegex) && c.clientCategoryRegex.MatchString(category) {
+			worklogEntry.Client = worklog.IDNameField{
+				ID:   category,
+				Name: category,
+			}
+		} else if utils.IsRegexSet(c.projectCategoryRegex) && c.projectCategoryRegex.MatchString(category) {
+			worklogEntry.Project = worklog.IDNameField{
+				ID:   category,
+				Name: category,
+			}
+		} else if utils.IsRegexSet(opts.TagsAsTasksRegex) && opts.TagsAsTasksRegex.MatchString(category) {
+			worklogEntry.Task = worklog.IDNameField{
+				ID:   category,
+				Name: category,
+			}
+		}
+	}
+
+	// If the task was not found in categories, make sure to set it to summary
+	if !worklogEntry.Task.IsComplete() {
+		worklogEntry.Task = worklog.IDNameField{
+			ID:   o.event.Summary,
+			Name: o.event.Summary,
+		}
+	}
+
+	if utils.IsRegexSet(opts.TagsAsTasksRegex) && len(tags) > 0 {
+		return worklogEntry.SplitByTagsAsTasks(worklogEntry.Summary, opts.TagsAsTasksRegex, tags), nil
+	}
+
+	return worklog.Entries{worklogEntry}, nil
+}
+
+// FetchEntries returns the entries of the meetings started within the date
+// range. The all-day events, the cancelled events, the events declined by the
+// user set in the options, and the events not started yet are skipped.
+func (c *icalClient) FetchEntries(_ context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	c.readOnce.Do(func() {
+		var skippedUIDs []string
+		c.events, skippedUIDs, c.readErr = ReadPath(c.path, c.Location())
+
+		if len(skippedUIDs) > 0 {
+			_, _ = fmt.Fprintf(
+				c.warnings,
+				"Skipped events having unsupported recurrence rules: %s\n",
+				strings.Join(skippedUIDs, ", "),
+			)
+		}
+	})
+
+	if c.readErr != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, c.readErr)
+	}
+
+	var entries worklog.Entries
+	now := time.Now()
+
+	for _, o := range c.occurrences(opts.Start, opts.End) {
+		if o.event.AllDay || o.event.Status == StatusCancelled || o.start.After(now) {
+			continue
+		}
+
+		if opts.User != "" && o.event.IsDeclinedBy(opts.User) {
+			continue
+		}
+
+		parsedEntries, err := c.parseOccurrence(o, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+
+		entries = append(entries, parsedEntries...)
+	}
+
+	return entries, nil
+}
+
+func (c *icalClient) UploadEntries(_ context.Context, entries worklog.Entries, errChan chan error, opts *client.UploadOpts) {
+	c.UploadFiles(entries, errChan, opts, c.fileTarget, c.Location(), c.writeFile)
+}
+
+func newClient(opts *ClientOpts) (*icalClient, error) {
+	clientCategoryRegex, err := regexp.Compile(opts.ClientCategoryRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	projectCategoryRegex, err := regexp.Compile(opts.ProjectCategoryRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := opts.Warnings
+	if warnings == nil {
+		warnings = os.Stderr
+	}
+
+	return &icalClient{
+		BaseClientOpts:  &opts.BaseClientOpts,
+		DefaultUploader: &client.DefaultUploader{},
+		fileTarget: &client.FileTargetOpts{
+			Path:          opts.Path,
+			Append:        opts.Append,
+			RotateMonthly: opts.RotateMonthly,
+			Stdout:        opts.Stdout,
+		},
+		path:                 opts.Path,
+		calendarName:         opts.CalendarName,
+		unbillableCategory:   opts.UnbillableCategory,
+		clientCategoryRegex:  clientCategoryRegex,
+		projectCategoryRegex: projectCategoryRegex,
+		warnings:             warnings,
+	}, nil
+}
+
+// NewFetcher returns a new iCalendar client for fetching entries.
+func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
+	c, err := newClient(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	return c, nil
+}
+
+// NewUploader returns a new iCalendar client for writing entries to a calendar
+// file.
+func NewUploader(opts *ClientOpts) (client.Uploader, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("%v: %s", client.ErrUploadEntries, "no path set")
+	}
+
+	c, err := newClient(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrUploadEntries, err)
+	}
+
+	return c, nil
+}
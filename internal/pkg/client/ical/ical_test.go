@@ -0,0 +1,442 @@
+package ical_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/ical"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+const testCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Minutes//Test//EN
+BEGIN:VEVENT
+UID:standup@example.com
+SUMMARY:Daily standup
+CATEGORIES:client,project
+DTSTART;TZID=Europe/Berlin:20211011T093000
+DTEND;TZID=Europe/Berlin:20211011T094500
+RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR
+EXDATE;TZID=Europe/Berlin:20211013T093000
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Reminder
+TRIGGER:-PT10M
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:standup@example.com
+RECURRENCE-ID;TZID=Europe/Berlin:20211014T093000
+SUMMARY:Daily standup
+CATEGORIES:client,project
+DTSTART;TZID=Europe/Berlin:20211014T110000
+DURATION:PT30M
+END:VEVENT
+BEGIN:VEVENT
+UID:planning@example.com
+SUMMARY:Sprint planning\, part 1
+DESCRIPTION:Planning the\nnext sprint
+CATEGORIES:client,project,TASK-123,TASK-456
+DTSTART:20211012T120000Z
+DTEND:20211012T140000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:lunch@example.com
+SUMMARY:Team lunch
+CATEGORIES:client,unbillable
+DTSTART:20211012T100000Z
+DTEND:20211012T110000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:declined@example.com
+SUMMARY:Declined meeting
+DTSTART:20211012T150000Z
+DTEND:20211012T160000Z
+ATTENDEE;CN=Steve Rogers;PARTSTAT=DECLINED:mailto:Steve@example.com
+ATTENDEE;PARTSTAT=ACCEPTED:mailto:tony@example.com
+END:VEVENT
+BEGIN:VEVENT
+UID:cancelled@example.com
+SUMMARY:Cancelled meeting
+STATUS:CANCELLED
+DTSTART:20211012T150000Z
+DTEND:20211012T160000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:holiday@example.com
+SUMMARY:Holiday
+DTSTART;VALUE=DATE:20211015
+DTEND;VALUE=DATE:20211016
+END:VEVENT
+END:VCALENDAR
+`
+
+func writeCalendar(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	require.Nil(t, os.WriteFile(path, []byte(strings.ReplaceAll(content, "\n", "\r\n")), 0600))
+	return path
+}
+
+// inUTC converts the dates of the entries to UTC, as the locations loaded by
+// the client and the tests are not the same instances.
+func inUTC(entries worklog.Entries) worklog.Entries {
+	for i := range entries {
+		entries[i].Start = entries[i].Start.UTC()
+		entries[i].End = entries[i].End.UTC()
+	}
+
+	return entries
+}
+
+func TestReadCalendar(t *testing.T) {
+	events, _, err := ical.ReadCalendar(strings.NewReader(testCalendar), time.UTC)
+	require.Nil(t, err)
+	require.Len(t, events, 7)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+
+	standup := events[0]
+	require.Equal(t, "standup@example.com", standup.UID)
+	require.Equal(t, []string{"client", "project"}, standup.Categories)
+	require.Equal(t, time.Date(2021, 10, 11, 9, 30, 0, 0, berlin), standup.Start)
+	require.Equal(t, []time.Time{time.Date(2021, 10, 13, 9, 30, 0, 0, berlin)}, standup.ExDates)
+	require.NotNil(t, standup.Recurrence)
+
+	// The end is calculated from the duration
+	require.Equal(t, time.Date(2021, 10, 14, 11, 30, 0, 0, berlin), events[1].End)
+	require.Equal(t, time.Date(2021, 10, 14, 9, 30, 0, 0, berlin), events[1].RecurrenceID)
+
+	require.Equal(t, "Sprint planning, part 1", events[2].Summary)
+	require.Equal(t, "Planning the\nnext sprint", events[2].Description)
+
+	require.True(t, events[4].IsDeclinedBy("steve@example.com"))
+	require.False(t, events[4].IsDeclinedBy("tony@example.com"))
+	require.True(t, events[6].AllDay)
+}
+
+func TestReadCalendar_Folded(t *testing.T) {
+	events, _, err := ical.ReadCalendar(strings.NewReader("BEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Very long\r\n  summary\r\nDTSTART:20211012T120000Z\r\nEND:VEVENT\r\n"), time.UTC)
+	require.Nil(t, err)
+	require.Equal(t, "Very long summary", events[0].Summary)
+	require.Equal(t, events[0].Start, events[0].End)
+}
+
+func TestReadCalendar_UnsupportedRecurrence(t *testing.T) {
+	content := strings.Join([]string{
+		"BEGIN:VEVENT", "UID:1", "DTSTART:20211012T120000Z", "RRULE:FREQ=MONTHLY;BYDAY=MO,TU;BYSETPOS=-1", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:2", "DTSTART:20211012T140000Z", "RRULE:FREQ=SECONDLY", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:3", "DTSTART:20211012T160000Z", "END:VEVENT",
+	}, "\r\n")
+
+	// Only the events with unsupported recurrence rules are skipped
+	events, skippedUIDs, err := ical.ReadCalendar(strings.NewReader(content), time.UTC)
+	require.Nil(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "3", events[0].UID)
+	require.Equal(t, []string{"1", "2"}, skippedUIDs)
+}
+
+func TestReadCalendar_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing start":  "BEGIN:VEVENT\nUID:1\nEND:VEVENT\n",
+		"invalid line":   "BEGIN:VEVENT\nUID\nEND:VEVENT\n",
+		"invalid date":   "BEGIN:VEVENT\nDTSTART:2021-10-12\nEND:VEVENT\n",
+		"invalid rule":   "BEGIN:VEVENT\nDTSTART:20211012T120000Z\nRRULE:FREQ=DAILY;COUNT=0\nEND:VEVENT\n",
+		"invalid period": "BEGIN:VEVENT\nDTSTART:20211012T120000Z\nDURATION:1H\nEND:VEVENT\n",
+	} {
+		_, _, err := ical.ReadCalendar(strings.NewReader(content), time.UTC)
+		require.ErrorContains(t, err, ical.ErrInvalidCalendar.Error(), name)
+	}
+}
+
+func TestICalClient_FetchEntries(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+
+	dir := t.TempDir()
+	writeCalendar(t, dir, "work.ics", testCalendar)
+	writeCalendar(t, dir, "notes.txt", "not a calendar")
+
+	expectedEntries := worklog.Entries{
+		{
+			Client:           worklog.IDNameField{ID: "client", Name: "client"},
+			Project:          worklog.IDNameField{ID: "project", Name: "project"},
+			Task:             worklog.IDNameField{ID: "Daily standup", Name: "Daily standup"},
+			Summary:          "Daily standup",
+			Notes:            "Daily standup",
+			Tags:             []worklog.IDNameField{{ID: "client", Name: "client"}, {ID: "project", Name: "project"}},
+			Start:            time.Date(2021, 10, 12, 9, 30, 0, 0, berlin),
+			End:              time.Date(2021, 10, 12, 9, 45, 0, 0, berlin),
+			BillableDuration: 15 * time.Minute,
+			Source:           ical.SourceName,
+			SourceID:         "standup@example.com/20211012T073000Z",
+		},
+		{
+			Client:           worklog.IDNameField{ID: "client", Name: "client"},
+			Project:          worklog.IDNameField{ID: "project", Name: "project"},
+			Task:             worklog.IDNameField{ID: "Daily standup", Name: "Daily standup"},
+			Summary:          "Daily standup",
+			Notes:            "Daily standup",
+			Tags:             []worklog.IDNameField{{ID: "client", Name: "client"}, {ID: "project", Name: "project"}},
+			Start:            time.Date(2021, 10, 14, 11, 0, 0, 0, berlin),
+			End:              time.Date(2021, 10, 14, 11, 30, 0, 0, berlin),
+			BillableDuration: 30 * time.Minute,
+			Source:           ical.SourceName,
+			SourceID:         "standup@example.com/20211014T073000Z",
+		},
+		{
+			Client:           worklog.IDNameField{ID: "client", Name: "client"},
+			Project:          worklog.IDNameField{ID: "project", Name: "project"},
+			Task:             worklog.IDNameField{ID: "Sprint planning, part 1", Name: "Sprint planning, part 1"},
+			Summary:          "Sprint planning, part 1",
+			Notes:            "Planning the\nnext sprint",
+			Tags:             []worklog.IDNameField{{ID: "client", Name: "client"}, {ID: "project", Name: "project"}, {ID: "TASK-123", Name: "TASK-123"}, {ID: "TASK-456", Name: "TASK-456"}},
+			Start:            time.Date(2021, 10, 12, 12, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 14, 0, 0, 0, time.UTC),
+			BillableDuration: 2 * time.Hour,
+			Source:           ical.SourceName,
+			SourceID:         "planning@example.com",
+		},
+		{
+			Client:             worklog.IDNameField{ID: "client", Name: "client"},
+			Task:               worklog.IDNameField{ID: "Team lunch", Name: "Team lunch"},
+			Summary:            "Team lunch",
+			Notes:              "Team lunch",
+			Tags:               []worklog.IDNameField{{ID: "client", Name: "client"}, {ID: "unbillable", Name: "unbillable"}},
+			Start:              time.Date(2021, 10, 12, 10, 0, 0, 0, time.UTC),
+			End:                time.Date(2021, 10, 12, 11, 0, 0, 0, time.UTC),
+			UnbillableDuration: time.Hour,
+			Source:             ical.SourceName,
+			SourceID:           "lunch@example.com",
+		},
+	}
+
+	fetcher, err := ical.NewFetcher(&ical.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:                 dir,
+		UnbillableCategory:   "unbillable",
+		ClientCategoryRegex:  "^client$",
+		ProjectCategoryRegex: "^project$",
+	})
+	require.Nil(t, err)
+
+	opts := &client.FetchOpts{
+		Start: time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 15, 0, 0, 0, 0, time.UTC),
+		User:  "steve@example.com",
+	}
+
+	entries, err := fetcher.FetchEntries(context.Background(), opts)
+	require.Nil(t, err)
+	require.ElementsMatch(t, inUTC(expectedEntries), inUTC(entries))
+
+	// Similarly to tags, the categories are split into tasks
+	opts.TagsAsTasksRegex = regexp.MustCompile(`^TASK-\d+$`)
+
+	entries, err = fetcher.FetchEntries(context.Background(), opts)
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "TASK-123", entries[0].Task.Name)
+	require.Equal(t, "TASK-456", entries[1].Task.Name)
+	require.Equal(t, time.Hour, entries[0].BillableDuration)
+}
+
+func TestICalClient_FetchEntries_UnsupportedRecurrence(t *testing.T) {
+	var warnings strings.Builder
+
+	path := writeCalendar(t, t.TempDir(), "work.ics", strings.Join([]string{
+		"BEGIN:VEVENT", "UID:hourly@example.com", "DTSTART:20211012T120000Z", "RRULE:FREQ=HOURLY", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:review@example.com", "DTSTART:20211012T140000Z", "END:VEVENT",
+	}, "\n"))
+
+	fetcher, err := ical.NewFetcher(&ical.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:     path,
+		Warnings: &warnings,
+	})
+	require.Nil(t, err)
+
+	entries, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+	})
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "Skipped events having unsupported recurrence rules: hourly@example.com\n", warnings.String())
+}
+
+func TestICalClient_FetchEntries_MissingPath(t *testing.T) {
+	fetcher, err := ical.NewFetcher(&ical.ClientOpts{
+		Path: filepath.Join(t.TempDir(), "missing.ics"),
+	})
+	require.Nil(t, err)
+
+	_, err = fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 15, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorContains(t, err, client.ErrFetchEntries.Error())
+}
+
+func getUploadEntries() worklog.Entries {
+	return worklog.Entries{
+		{
+			Client:             worklog.IDNameField{ID: "client-id", Name: "My Awesome Company"},
+			Project:            worklog.IDNameField{ID: "project-id", Name: "Internal"},
+			Task:               worklog.IDNameField{ID: "task-id", Name: "TASK-123"},
+			Summary:            "Meeting, planning",
+			Notes:              "Meeting, planning",
+			Tags:               []worklog.IDNameField{{ID: "meeting", Name: "meeting"}},
+			Start:              time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC),
+			End:                time.Date(2021, 10, 12, 10, 30, 0, 0, time.UTC),
+			BillableDuration:   time.Hour,
+			UnbillableDuration: 30 * time.Minute,
+			Source:             "clockify",
+			SourceID:           "entry-1",
+		},
+		{
+			Task:             worklog.IDNameField{ID: "task-id", Name: "TASK-456"},
+			Summary:          "Review of the very long pull request implementing the iCalendar export",
+			Notes:            "Review",
+			Start:            time.Date(2021, 11, 2, 9, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC),
+			BillableDuration: time.Hour,
+			Source:           "clockify",
+			SourceID:         "entry-2",
+		},
+	}
+}
+
+func uploadEntries(t *testing.T, uploader client.Uploader, entries worklog.Entries) {
+	errChan := make(chan error, len(entries))
+	uploader.UploadEntries(context.Background(), entries, errChan, &client.UploadOpts{User: "steve-rogers"})
+
+	for range entries {
+		require.Nil(t, <-errChan)
+	}
+}
+
+func TestEventUID(t *testing.T) {
+	entry := client.ExportEntry{
+		Start:   "2021-10-12T09:00:00Z",
+		Client:  "My Awesome Company",
+		Task:    "TASK-123",
+		Summary: "Meeting",
+	}
+
+	uid := ical.EventUID(entry)
+	require.True(t, strings.HasSuffix(uid, "@"+ical.UIDDomain))
+
+	// The UID does not change when the entry is updated later that day
+	entry.Start = "2021-10-12T15:00:00Z"
+	entry.BillableSeconds = 3600
+	require.Equal(t, uid, ical.EventUID(entry))
+
+	entry.Start = "2021-10-13T09:00:00Z"
+	require.NotEqual(t, uid, ical.EventUID(entry))
+}
+
+func TestICalClient_UploadEntries(t *testing.T) {
+	var stdout strings.Builder
+
+	icalClient, err := ical.NewUploader(&ical.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:               ical.PathStdout,
+		UnbillableCategory: "unbillable",
+		CalendarName:       "Steve's worklog",
+		Stdout:             &stdout,
+	})
+	require.Nil(t, err)
+
+	entries := getUploadEntries()
+	uploadEntries(t, icalClient, entries)
+
+	content := stdout.String()
+	require.True(t, strings.HasPrefix(content, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	require.Contains(t, content, "X-WR-CALNAME:Steve's worklog\r\n")
+	require.Contains(t, content, "SUMMARY:My Awesome Company / Internal / TASK-123: Meeting\\, planning\r\n")
+	require.Contains(t, content, "CATEGORIES:billable,unbillable,meeting\r\n")
+	require.Contains(t, content, "DTSTART:20211012T090000Z\r\nDTEND:20211012T103000Z\r\n")
+
+	for _, line := range strings.Split(content, "\r\n") {
+		require.LessOrEqual(t, len(line), 75)
+	}
+
+	// The written calendar can be read again
+	events, _, err := ical.ReadCalendar(strings.NewReader(content), time.UTC)
+	require.Nil(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, "TASK-456: Review of the very long pull request implementing the iCalendar export", events[1].Summary)
+	require.Equal(t, []string{"billable"}, events[1].Categories)
+	require.Equal(t, time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC), events[1].End)
+}
+
+func TestICalClient_UploadEntries_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "worklog.ics")
+
+	icalClient, err := ical.NewUploader(&ical.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:               path,
+		UnbillableCategory: "unbillable",
+		Append:             true,
+	})
+	require.Nil(t, err)
+
+	entries := getUploadEntries()
+	uploadEntries(t, icalClient, entries)
+
+	// Exporting the updated entry again replaces its event
+	entries[0].End = entries[0].End.Add(time.Hour)
+	entries[0].BillableDuration += time.Hour
+	uploadEntries(t, icalClient, entries[:1])
+
+	events, _, err := ical.ReadPath(path, time.UTC)
+	require.Nil(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, time.Date(2021, 10, 12, 11, 30, 0, 0, time.UTC), events[0].End)
+	require.Equal(t, time.Date(2021, 11, 2, 9, 0, 0, 0, time.UTC), events[1].Start)
+}
+
+func TestICalClient_UploadEntries_MergedEntry(t *testing.T) {
+	var stdout strings.Builder
+
+	icalClient, err := ical.NewUploader(&ical.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Path:   ical.PathStdout,
+		Stdout: &stdout,
+	})
+	require.Nil(t, err)
+
+	// The entry merged from the 9:00-10:00 and 14:00-15:00 sessions
+	entries := getUploadEntries()[1:]
+	entries[0].Start = time.Date(2021, 11, 2, 9, 0, 0, 0, time.UTC)
+	entries[0].End = time.Date(2021, 11, 2, 15, 0, 0, 0, time.UTC)
+	entries[0].BillableDuration = 2 * time.Hour
+	uploadEntries(t, icalClient, entries)
+
+	require.Contains(t, stdout.String(), "DTSTART:20211102T090000Z\r\nDTEND:20211102T110000Z\r\n")
+}
+
+func TestICalClient_UploadEntries_NoPath(t *testing.T) {
+	_, err := ical.NewUploader(&ical.ClientOpts{})
+	require.ErrorContains(t, err, client.ErrUploadEntries.Error())
+}
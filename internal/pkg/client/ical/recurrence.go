@@ -0,0 +1,351 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// FrequencyDaily repeats the event every day.
+	FrequencyDaily string = "DAILY"
+	// FrequencyWeekly repeats the event every week.
+	FrequencyWeekly string = "WEEKLY"
+	// FrequencyMonthly repeats the event every month.
+	FrequencyMonthly string = "MONTHLY"
+	// FrequencyYearly repeats the event every year.
+	FrequencyYearly string = "YEARLY"
+)
+
+var (
+	// ErrUnsupportedRecurrence returns when the recurrence rule uses a
+	// frequency or rule part that cannot be expanded.
+	ErrUnsupportedRecurrence = errors.New("unsupported recurrence")
+	// ErrInvalidRecurrence returns when the recurrence rule cannot be parsed.
+	ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+	weekdays = map[string]time.Weekday{
+		"SU": time.Sunday,
+		"MO": time.Monday,
+		"TU": time.Tuesday,
+		"WE": time.Wednesday,
+		"TH": time.Thursday,
+		"FR": time.Friday,
+		"SA": time.Saturday,
+	}
+)
+
+// WeekdayNum represents a weekday of the BYDAY rule part. If the Ordinal is
+// set, only the nth weekday of the month or year matches, counted from the end
+// when negative.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	Ordinal int
+}
+
+// RecurrenceRule represents the supported subset of the RRULE property. The
+// rule parts limiting the occurrences to hours, minutes, weeks, or days of the
+// year are not supported.
+type RecurrenceRule struct {
+	Frequency  string
+	Interval   int
+	Count      int
+	Until      time.Time
+	WeekStart  time.Weekday
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+func parseIntList(value string, min int, max int) ([]int, error) {
+	var numbers []int
+
+	for _, rawNumber := range strings.Split(value, ",") {
+		number, err := strconv.Atoi(rawNumber)
+		if err != nil || number == 0 || number < min || number > max {
+			return nil, fmt.Errorf("%v: %s", ErrInvalidRecurrence, value)
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	return numbers, nil
+}
+
+// ParseRecurrenceRule parses the value of the RRULE property, like
+// "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10". The date-time of UNTIL is parsed in the
+// given location if it has no timezone information.
+func ParseRecurrenceRule(value string, loc *time.Location) (*RecurrenceRule, error) {
+	rule := &RecurrenceRule{
+		Interval:  1,
+		WeekStart: time.Monday,
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		name, partValue, _ := strings.Cut(part, "=")
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Frequency = strings.ToUpper(partValue)
+			switch rule.Frequency {
+			case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+			default:
+				return nil, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedRecurrence, partValue)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(partValue)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("%v: %s", ErrInvalidRecurrence, part)
+			}
+
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(partValue)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("%v: %s", ErrInvalidRecurrence, part)
+			}
+
+			rule.Count = count
+		case "UNTIL":
+			until, allDay, err := parseDateTime(partValue, map[string]string{}, loc)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %s", ErrInvalidRecurrence, part)
+			}
+
+			// The date is inclusive, so every occurrence on that day is kept
+			if allDay {
+				until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+
+			rule.Until = until
+		case "WKST":
+			weekday, ok := weekdays[strings.ToUpper(partValue)]
+			if !ok {
+				return nil, fmt.Errorf("%v: %s", ErrInvalidRecurrence, part)
+			}
+
+			rule.WeekStart = weekday
+		case "BYDAY":
+			for _, rawDay := range strings.Split(strings.ToUpper(partValue), ",") {
+				if len(rawDay) < 2 {
+					return nil, fmt.Errorf("%v: %s", ErrInvalidRecurrence, part)
+				}
+
+				weekday, ok := weekdays[rawDay[len(rawDay)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("%v: %s", ErrInvalidRecurrence, part)
+				}
+
+				ordinal := 0
+				if rawOrdinal := rawDay[:len(rawDay)-2]; rawOrdinal != "" {
+					var err error
+					if ordinal, err = strconv.Atoi(rawOrdinal); err != nil || ordinal == 0 {
+						return nil, fmt.Errorf("%v: %s", ErrInvalidRecurrence, part)
+					}
+				}
+
+				rule.ByDay = append(rule.ByDay, WeekdayNum{Weekday: weekday, Ordinal: ordinal})
+			}
+		case "BYMONTHDAY":
+			monthDays, err := parseIntList(partValue, -31, 31)
+			if err != nil {
+				return nil, err
+			}
+
+			rule.ByMonthDay = monthDays
+		case "BYMONTH":
+			months, err := parseIntList(partValue, 1, 12)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, month := range months {
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRecurrence, name)
+		}
+	}
+
+	if rule.Frequency == "" {
+		return nil, fmt.Errorf("%v: missing FREQ", ErrInvalidRecurrence)
+	}
+
+	return rule, nil
+}
+
+// daysOfPeriod returns the days of the nth period after the start, the days
+// of the rule are selected from.
+func (r *RecurrenceRule) daysOfPeriod(start time.Time, n int) []time.Time {
+	var first time.Time
+	var last time.Time
+
+	date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch r.Frequency {
+	case FrequencyDaily:
+		first = date.AddDate(0, 0, n*r.Interval)
+		last = first
+	case FrequencyWeekly:
+		weekStart := date.AddDate(0, 0, -((int(date.Weekday()) - int(r.WeekStart) + 7) % 7))
+		first = weekStart.AddDate(0, 0, 7*n*r.Interval)
+		last = first.AddDate(0, 0, 6)
+	case FrequencyMonthly:
+		first = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, n*r.Interval, 0)
+		last = first.AddDate(0, 1, -1)
+	case FrequencyYearly:
+		first = time.Date(date.Year()+n*r.Interval, time.January, 1, 0, 0, 0, 0, time.UTC)
+		last = first.AddDate(1, 0, -1)
+	}
+
+	var days []time.Time
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+
+	return days
+}
+
+// isOrdinalMatching returns true if the day is the nth weekday of its month or
+// year, depending on the frequency of the rule.
+func (r *RecurrenceRule) isOrdinalMatching(day time.Time, ordinal int) bool {
+	first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+
+	if r.Frequency == FrequencyYearly && len(r.ByMonth) == 0 {
+		first = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		last = first.AddDate(1, 0, -1)
+	}
+
+	if ordinal > 0 {
+		return int(day.Sub(first).Hours()/24)/7+1 == ordinal
+	}
+
+	return int(last.Sub(day).Hours()/24)/7+1 == -ordinal
+}
+
+// isMatching returns true if the day is selected by the rule parts.
+func (r *RecurrenceRule) isMatching(day time.Time, byDay []WeekdayNum, byMonthDay []int, byMonth []time.Month) bool {
+	if len(byMonth) > 0 {
+		matching := false
+		for _, month := range byMonth {
+			matching = matching || day.Month() == month
+		}
+
+		if !matching {
+			return false
+		}
+	}
+
+	if len(byMonthDay) > 0 {
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+		matching := false
+		for _, monthDay := range byMonthDay {
+			matching = matching || day.Day() == monthDay || day.Day() == daysInMonth+monthDay+1
+		}
+
+		if !matching {
+			return false
+		}
+	}
+
+	if len(byDay) > 0 {
+		// Ordinals are meaningful for monthly and yearly rules only
+		ordinalsAllowed := r.Frequency == FrequencyMonthly || r.Frequency == FrequencyYearly
+
+		matching := false
+		for _, weekdayNum := range byDay {
+			if day.Weekday() != weekdayNum.Weekday {
+				continue
+			}
+
+			matching = matching || !ordinalsAllowed || weekdayNum.Ordinal == 0 || r.isOrdinalMatching(day, weekdayNum.Ordinal)
+		}
+
+		if !matching {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Occurrences returns the start of the occurrences of the event started at
+// the given time, until the end (exclusive). The first occurrence is always
+// the start of the event. The occurrences keep the wall clock time of the start
+// in its location, even when the daylight saving time changes.
+func (r *RecurrenceRule) Occurrences(start time.Time, end time.Time) []time.Time {
+	byDay := r.ByDay
+	byMonthDay := r.ByMonthDay
+	byMonth := r.ByMonth
+
+	// Without day selection, the day of the start is repeated
+	if len(byDay) == 0 && len(byMonthDay) == 0 {
+		switch r.Frequency {
+		case FrequencyWeekly:
+			byDay = []WeekdayNum{{Weekday: start.Weekday()}}
+		case FrequencyMonthly:
+			byMonthDay = []int{start.Day()}
+		case FrequencyYearly:
+			byMonthDay = []int{start.Day()}
+			if len(byMonth) == 0 {
+				byMonth = []time.Month{start.Month()}
+			}
+		}
+	}
+
+	occurrences := []time.Time{start}
+
+	isDone := func(occurrence time.Time) bool {
+		return !occurrence.Before(end) ||
+			(!r.Until.IsZero() && occurrence.After(r.Until)) ||
+			(r.Count > 0 && len(occurrences) >= r.Count)
+	}
+
+	if isDone(start) {
+		if start.Before(end) {
+			return occurrences
+		}
+
+		return nil
+	}
+
+	for n := 0; ; n++ {
+		days := r.daysOfPeriod(start, n)
+
+		// The periods are consecutive, so no occurrence can follow
+		if days[0].After(time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)) {
+			break
+		}
+
+		var periodOccurrences []time.Time
+		for _, day := range days {
+			if !r.isMatching(day, byDay, byMonthDay, byMonth) {
+				continue
+			}
+
+			occurrence := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+			if occurrence.After(start) {
+				periodOccurrences = append(periodOccurrences, occurrence)
+			}
+		}
+
+		sort.Slice(periodOccurrences, func(i, j int) bool {
+			return periodOccurrences[i].Before(periodOccurrences[j])
+		})
+
+		for _, occurrence := range periodOccurrences {
+			if isDone(occurrence) {
+				return occurrences
+			}
+
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	return occurrences
+}
@@ -0,0 +1,127 @@
+package ical_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client/ical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	rule, err := ical.ParseRecurrenceRule("FREQ=MONTHLY;INTERVAL=2;BYDAY=1MO,-1FR;UNTIL=20211231;WKST=SU", time.UTC)
+	require.Nil(t, err)
+	require.Equal(t, &ical.RecurrenceRule{
+		Frequency: ical.FrequencyMonthly,
+		Interval:  2,
+		Until:     time.Date(2021, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		WeekStart: time.Sunday,
+		ByDay: []ical.WeekdayNum{
+			{Weekday: time.Monday, Ordinal: 1},
+			{Weekday: time.Friday, Ordinal: -1},
+		},
+	}, rule)
+
+	for value, expectedErr := range map[string]error{
+		"FREQ=HOURLY":              ical.ErrUnsupportedRecurrence,
+		"FREQ=DAILY;BYHOUR=9":      ical.ErrUnsupportedRecurrence,
+		"INTERVAL=2":               ical.ErrInvalidRecurrence,
+		"FREQ=WEEKLY;BYDAY=XY":     ical.ErrInvalidRecurrence,
+		"FREQ=MONTHLY;BYMONTH=13":  ical.ErrInvalidRecurrence,
+		"FREQ=DAILY;COUNT=0":       ical.ErrInvalidRecurrence,
+		"FREQ=YEARLY;UNTIL=202112": ical.ErrInvalidRecurrence,
+	} {
+		_, err = ical.ParseRecurrenceRule(value, time.UTC)
+		require.ErrorContains(t, err, expectedErr.Error(), value)
+	}
+}
+
+func TestRecurrenceRule_Occurrences(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+
+	// Tuesday
+	start := time.Date(2021, 10, 12, 9, 30, 0, 0, berlin)
+	end := time.Date(2022, 1, 1, 0, 0, 0, 0, berlin)
+
+	tests := map[string]struct {
+		rule     string
+		start    time.Time
+		expected []time.Time
+	}{
+		"daily with count": {
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: start,
+			expected: []time.Time{
+				start,
+				time.Date(2021, 10, 13, 9, 30, 0, 0, berlin),
+				time.Date(2021, 10, 14, 9, 30, 0, 0, berlin),
+			},
+		},
+		"weekly keeps the wall clock over daylight saving time change": {
+			rule:  "FREQ=WEEKLY;UNTIL=20211102T083000Z",
+			start: start,
+			expected: []time.Time{
+				start,
+				time.Date(2021, 10, 19, 9, 30, 0, 0, berlin),
+				time.Date(2021, 10, 26, 9, 30, 0, 0, berlin),
+				time.Date(2021, 11, 2, 9, 30, 0, 0, berlin),
+			},
+		},
+		"biweekly on multiple days": {
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=5",
+			start: start,
+			expected: []time.Time{
+				start,
+				time.Date(2021, 10, 14, 9, 30, 0, 0, berlin),
+				time.Date(2021, 10, 26, 9, 30, 0, 0, berlin),
+				time.Date(2021, 10, 28, 9, 30, 0, 0, berlin),
+				time.Date(2021, 11, 9, 9, 30, 0, 0, berlin),
+			},
+		},
+		"monthly on the last friday": {
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			start: time.Date(2021, 10, 29, 16, 0, 0, 0, berlin),
+			expected: []time.Time{
+				time.Date(2021, 10, 29, 16, 0, 0, 0, berlin),
+				time.Date(2021, 11, 26, 16, 0, 0, 0, berlin),
+				time.Date(2021, 12, 31, 16, 0, 0, 0, berlin),
+			},
+		},
+		"monthly skips the months without the day": {
+			rule:  "FREQ=MONTHLY;COUNT=2",
+			start: time.Date(2021, 10, 31, 10, 0, 0, 0, berlin),
+			expected: []time.Time{
+				time.Date(2021, 10, 31, 10, 0, 0, 0, berlin),
+				time.Date(2021, 12, 31, 10, 0, 0, 0, berlin),
+			},
+		},
+		"yearly on the start day": {
+			rule:     "FREQ=YEARLY",
+			start:    time.Date(2020, 11, 2, 10, 0, 0, 0, berlin),
+			expected: []time.Time{time.Date(2020, 11, 2, 10, 0, 0, 0, berlin), time.Date(2021, 11, 2, 10, 0, 0, 0, berlin)},
+		},
+		"until the end of the range": {
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1,15",
+			start: time.Date(2021, 11, 15, 8, 0, 0, 0, berlin),
+			expected: []time.Time{
+				time.Date(2021, 11, 15, 8, 0, 0, 0, berlin),
+				time.Date(2021, 12, 1, 8, 0, 0, 0, berlin),
+				time.Date(2021, 12, 15, 8, 0, 0, 0, berlin),
+			},
+		},
+		"starts after the range": {
+			rule:     "FREQ=DAILY",
+			start:    end,
+			expected: nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rule, err := ical.ParseRecurrenceRule(test.rule, berlin)
+			require.Nil(t, err)
+			require.Equal(t, test.expected, rule.Occurrences(test.start, end))
+		})
+	}
+}
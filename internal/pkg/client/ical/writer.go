@@ -0,0 +1,272 @@
+package ical
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+)
+
+const (
+	// ProductID is the identifier of the product written to the calendars.
+	ProductID string = "-//gabor-boros//minutes//EN"
+	// UIDDomain is the domain part of the event UIDs written to the calendars.
+	UIDDomain string = "minutes"
+	// BillableCategory is the category of the events having billable time.
+	BillableCategory string = "billable"
+
+	// maxLineLength is the maximum length of a content line in octets,
+	// excluding the line break.
+	maxLineLength int = 75
+)
+
+// EventUID returns the UID of the event written for the entry. The UID is
+// derived from the fields identifying an entry of the worklog, so the event of
+// an entry exported again has the same UID, and replaces the existing event in
+// calendars.
+func EventUID(entry client.ExportEntry) string {
+	date := entry.Start
+	if len(date) > len("2006-01-02") {
+		date = date[:len("2006-01-02")]
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		entry.Client, entry.Project, entry.Task, entry.Summary, date,
+	}, "\x00")))
+
+	return fmt.Sprintf("%s@%s", hex.EncodeToString(hash[:16]), UIDDomain)
+}
+
+// escapeText escapes the TEXT value as defined by RFC 5545.
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// foldLine splits the content line into lines of at most 75 octets, without
+// splitting multi-byte characters. The continuation lines start with a space.
+func foldLine(line string) string {
+	var folded strings.Builder
+	length := 0
+
+	for _, char := range line {
+		if size := utf8.RuneLen(char); length+size > maxLineLength {
+			folded.WriteString("\r\n ")
+			length = 1
+		}
+
+		folded.WriteRune(char)
+		length += utf8.RuneLen(char)
+	}
+
+	return folded.String() + "\r\n"
+}
+
+// eventSummary returns the summary of the event, prefixed with the client,
+// project, and task of the entry, like "ACME / Website / TASK-1: Fix login".
+func eventSummary(entry client.ExportEntry) string {
+	var parts []string
+	for _, part := range []string{entry.Client, entry.Project, entry.Task} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	prefix := strings.Join(parts, " / ")
+
+	switch {
+	case prefix == "":
+		return entry.Summary
+	case entry.Summary == "" || entry.Summary == entry.Task:
+		return prefix
+	default:
+		return prefix + ": " + entry.Summary
+	}
+}
+
+// eventLines returns the unfolded content lines of the event written for the
+// entry. The events are transparent, so the logged time is not shown as busy.
+// The event lasts for the logged duration instead of ending with the entry,
+// since merged entries end with their last session, and the event would cover
+// the gaps between the sessions.
+func (c *icalClient) eventLines(entry client.ExportEntry, stamp time.Time) ([]string, error) {
+	start, err := time.Parse(time.RFC3339, entry.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	end := start.Add(time.Duration(entry.TotalSeconds) * time.Second)
+
+	var categories []string
+	if entry.BillableSeconds > 0 {
+		categories = append(categories, escapeText(BillableCategory))
+	}
+
+	if entry.UnbillableSeconds > 0 {
+		categories = append(categories, escapeText(c.unbillableCategory))
+	}
+
+	for _, tag := range entry.Tags {
+		categories = append(categories, escapeText(tag))
+	}
+
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + EventUID(entry),
+		"DTSTAMP:" + stamp.UTC().Format(dateTimeLayout+"Z"),
+		"DTSTART:" + start.UTC().Format(dateTimeLayout+"Z"),
+		"DTEND:" + end.UTC().Format(dateTimeLayout+"Z"),
+		"SUMMARY:" + escapeText(eventSummary(entry)),
+	}
+
+	if entry.Comment != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeText(entry.Comment))
+	}
+
+	if len(categories) > 0 {
+		lines = append(lines, "CATEGORIES:"+strings.Join(categories, ","))
+	}
+
+	return append(lines, "TRANSP:TRANSPARENT", "END:VEVENT"), nil
+}
+
+// readEventLines returns the unfolded content lines of the events of an
+// existing calendar file by their UID. If the file does not exist, no events
+// and no error returns.
+func readEventLines(path string) (map[string][]string, []string, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string][]string{}, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	defer file.Close()
+
+	lines, err := unfoldLines(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := map[string][]string{}
+	var uids []string
+	var event []string
+	var uid string
+
+	for _, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT"):
+			event = []string{line}
+			uid = ""
+		case event == nil:
+			continue
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT"):
+			if _, ok := events[uid]; !ok {
+				uids = append(uids, uid)
+			}
+
+			events[uid] = append(event, line)
+			event = nil
+		default:
+			if prop.name == "UID" {
+				uid = prop.value
+			}
+
+			event = append(event, line)
+		}
+	}
+
+	return events, uids, nil
+}
+
+// writeCalendar writes the events to the writer as a calendar.
+func (c *icalClient) writeCalendar(w io.Writer, events [][]string) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + ProductID,
+		"CALSCALE:GREGORIAN",
+	}
+
+	if c.calendarName != "" {
+		lines = append(lines, "X-WR-CALNAME:"+escapeText(c.calendarName))
+	}
+
+	for _, event := range events {
+		lines = append(lines, event...)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldLine(line)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes the entries to the file as a calendar. When appending, the
+// events of the existing file are kept, except the events having the same UID
+// as a written event, so exporting the entries again replaces their events.
+func (c *icalClient) writeFile(path string, entries []client.ExportEntry) error {
+	fileTarget := *c.fileTarget
+	stamp := time.Now()
+
+	var uids []string
+	events := map[string][]string{}
+
+	if fileTarget.Append && path != PathStdout {
+		var err error
+		if events, uids, err = readEventLines(path); err != nil {
+			return err
+		}
+
+		fileTarget.Append = false
+	}
+
+	for _, entry := range entries {
+		lines, err := c.eventLines(entry, stamp)
+		if err != nil {
+			return err
+		}
+
+		uid := EventUID(entry)
+		if _, ok := events[uid]; !ok {
+			uids = append(uids, uid)
+		}
+
+		events[uid] = lines
+	}
+
+	orderedEvents := make([][]string, 0, len(uids))
+	for _, uid := range uids {
+		orderedEvents = append(orderedEvents, events[uid])
+	}
+
+	file, err := fileTarget.OpenFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err = c.writeCalendar(file, orderedEvents); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
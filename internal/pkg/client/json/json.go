@@ -0,0 +1,99 @@
+package json
+
+import (
+	"context"
+	encodingJSON "encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// PathStdout is the path used to write the entries to the standard output.
+	PathStdout string = client.PathStdout
+)
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// Since the JSON files are written to the disk, the HTTP related options are
+// not used by the client.
+type ClientOpts struct {
+	client.BaseClientOpts
+	client.FileTargetOpts
+}
+
+type jsonClient struct {
+	*client.BaseClientOpts
+	*client.DefaultUploader
+	fileTarget *client.FileTargetOpts
+}
+
+// readFile returns the entries of an existing file. If the file does not
+// exist or empty, no entries and no error returns.
+func (c *jsonClient) readFile(path string) ([]client.ExportEntry, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entries []client.ExportEntry
+	if err = encodingJSON.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// writeFile writes the entries to the file as a JSON array. Since the array
+// cannot be extended in place, the appended entries are merged with the entries
+// of the existing file, and the file is written again.
+func (c *jsonClient) writeFile(path string, entries []client.ExportEntry) error {
+	fileTarget := *c.fileTarget
+
+	if fileTarget.Append && path != PathStdout {
+		existingEntries, err := c.readFile(path)
+		if err != nil {
+			return err
+		}
+
+		entries = append(existingEntries, entries...)
+		fileTarget.Append = false
+	}
+
+	file, err := fileTarget.OpenFile(path)
+	if err != nil {
+		return err
+	}
+
+	encoder := encodingJSON.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err = encoder.Encode(entries); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (c *jsonClient) UploadEntries(_ context.Context, entries worklog.Entries, errChan chan error, opts *client.UploadOpts) {
+	c.UploadFiles(entries, errChan, opts, c.fileTarget, c.Location(), c.writeFile)
+}
+
+// NewUploader returns a new JSON client for writing entries to a JSON file.
+func NewUploader(opts *ClientOpts) (client.Uploader, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("%v: %s", client.ErrUploadEntries, "no path set")
+	}
+
+	return &jsonClient{
+		BaseClientOpts:  &opts.BaseClientOpts,
+		DefaultUploader: &client.DefaultUploader{},
+		fileTarget:      &opts.FileTargetOpts,
+	}, nil
+}
@@ -0,0 +1,171 @@
+package json_test
+
+import (
+	"context"
+	encodingJSON "encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/json"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestEntry(start time.Time) worklog.Entry {
+	return worklog.Entry{
+		Client:             worklog.IDNameField{ID: "client-id", Name: "My Awesome Company"},
+		Project:            worklog.IDNameField{ID: "project-id", Name: "Internal"},
+		Task:               worklog.IDNameField{ID: "task-id", Name: "TASK-123"},
+		Summary:            "Meeting",
+		Notes:              "Meeting notes",
+		Start:              start,
+		End:                start.Add(90 * time.Minute),
+		BillableDuration:   time.Hour,
+		UnbillableDuration: 30 * time.Minute,
+		Source:             "clockify",
+		SourceID:           "entry-id",
+	}
+}
+
+func uploadEntries(t *testing.T, uploader client.Uploader, entries worklog.Entries) {
+	errChan := make(chan error, len(entries))
+	uploader.UploadEntries(context.Background(), entries, errChan, &client.UploadOpts{
+		TreatDurationAsBilled: true,
+		User:                  "steve-rogers",
+	})
+
+	for range entries {
+		require.Nil(t, <-errChan)
+	}
+}
+
+func readEntries(t *testing.T, path string) []client.ExportEntry {
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+
+	var entries []client.ExportEntry
+	require.Nil(t, encodingJSON.Unmarshal(content, &entries))
+
+	return entries
+}
+
+func TestJSONClient_UploadEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "worklog.json")
+
+	jsonClient, err := json.NewUploader(&json.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		FileTargetOpts: client.FileTargetOpts{
+			Path: path,
+		},
+	})
+	require.Nil(t, err)
+
+	uploadEntries(t, jsonClient, worklog.Entries{getTestEntry(time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC))})
+
+	require.Equal(t, []client.ExportEntry{
+		{
+			Start:             "2021-10-12T09:00:00Z",
+			End:               "2021-10-12T10:30:00Z",
+			ClientID:          "client-id",
+			Client:            "My Awesome Company",
+			ProjectID:         "project-id",
+			Project:           "Internal",
+			TaskID:            "task-id",
+			Task:              "TASK-123",
+			Summary:           "Meeting",
+			Notes:             "Meeting notes",
+			Comment:           "Meeting",
+			Tags:              []string{},
+			BillableSeconds:   5400,
+			UnbillableSeconds: 0,
+			TotalSeconds:      5400,
+			Source:            "clockify",
+			SourceID:          "entry-id",
+			User:              "steve-rogers",
+		},
+	}, readEntries(t, path))
+
+	// Without appending, the file is overwritten
+	uploadEntries(t, jsonClient, worklog.Entries{getTestEntry(time.Date(2021, 10, 13, 9, 0, 0, 0, time.UTC))})
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 1)
+	require.Equal(t, "2021-10-13T09:00:00Z", entries[0].Start)
+}
+
+func TestJSONClient_UploadEntries_Append(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonClient, err := json.NewUploader(&json.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		FileTargetOpts: client.FileTargetOpts{
+			Path:          filepath.Join(dir, "worklog.json"),
+			Append:        true,
+			RotateMonthly: true,
+		},
+	})
+	require.Nil(t, err)
+
+	uploadEntries(t, jsonClient, worklog.Entries{getTestEntry(time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC))})
+	uploadEntries(t, jsonClient, worklog.Entries{
+		getTestEntry(time.Date(2021, 10, 13, 9, 0, 0, 0, time.UTC)),
+		getTestEntry(time.Date(2021, 11, 1, 9, 0, 0, 0, time.UTC)),
+	})
+
+	october := readEntries(t, filepath.Join(dir, "worklog-2021-10.json"))
+	require.Len(t, october, 2)
+	require.Equal(t, "2021-10-12T09:00:00Z", october[0].Start)
+	require.Equal(t, "2021-10-13T09:00:00Z", october[1].Start)
+
+	november := readEntries(t, filepath.Join(dir, "worklog-2021-11.json"))
+	require.Len(t, november, 1)
+}
+
+func TestJSONClient_UploadEntries_InvalidExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "worklog.json")
+	require.Nil(t, os.WriteFile(path, []byte("{"), 0600))
+
+	jsonClient, err := json.NewUploader(&json.ClientOpts{
+		FileTargetOpts: client.FileTargetOpts{
+			Path:   path,
+			Append: true,
+		},
+	})
+	require.Nil(t, err)
+
+	errChan := make(chan error, 1)
+	jsonClient.UploadEntries(context.Background(), worklog.Entries{getTestEntry(time.Now())}, errChan, &client.UploadOpts{})
+
+	require.ErrorContains(t, <-errChan, client.ErrUploadEntries.Error())
+}
+
+func TestJSONClient_UploadEntries_Stdout(t *testing.T) {
+	var stdout strings.Builder
+
+	jsonClient, err := json.NewUploader(&json.ClientOpts{
+		FileTargetOpts: client.FileTargetOpts{
+			Path:   json.PathStdout,
+			Stdout: &stdout,
+		},
+	})
+	require.Nil(t, err)
+
+	uploadEntries(t, jsonClient, worklog.Entries{getTestEntry(time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC))})
+
+	var entries []client.ExportEntry
+	require.Nil(t, encodingJSON.Unmarshal([]byte(stdout.String()), &entries))
+	require.Len(t, entries, 1)
+}
+
+func TestNewUploader_NoPath(t *testing.T) {
+	_, err := json.NewUploader(&json.ClientOpts{})
+	require.ErrorContains(t, err, client.ErrUploadEntries.Error())
+}
@@ -0,0 +1,512 @@
+package kimai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "kimai"
+	// PathTimesheets is the API endpoint used to search and create timesheets.
+	PathTimesheets string = "/api/timesheets"
+	// PathProjects is the API endpoint used to list the projects.
+	PathProjects string = "/api/projects"
+	// PathActivities is the API endpoint used to list the activities.
+	PathActivities string = "/api/activities"
+	// HeaderAuthUser is the header of the username, used by the API token
+	// based authentication.
+	HeaderAuthUser string = "X-AUTH-USER"
+	// HeaderAuthToken is the header of the API token, used by the API token
+	// based authentication.
+	HeaderAuthToken string = "X-AUTH-TOKEN"
+	// HeaderTotalCount is the header of the total number of entries, set on
+	// paginated responses.
+	HeaderTotalCount string = "X-Total-Count"
+
+	// dateLayout is the layout of the dates returned by Kimai, having an
+	// offset without colon.
+	dateLayout string = "2006-01-02T15:04:05-0700"
+)
+
+var (
+	// ErrProjectNotFound returns when no project found for an entry.
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrActivityNotFound returns when no activity found for an entry.
+	ErrActivityNotFound = errors.New("activity not found")
+)
+
+// Customer represents the customer of a project.
+type Customer struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Project represents the project assigned to a timesheet.
+type Project struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Customer Customer `json:"customer"`
+}
+
+// Activity represents the activity assigned to a timesheet.
+type Activity struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// FetchEntry represents the timesheet fetched from Kimai. The running
+// timesheets have no end. If Billable is not set, the timesheet is billable.
+type FetchEntry struct {
+	ID          int      `json:"id"`
+	Begin       string   `json:"begin"`
+	End         *string  `json:"end"`
+	Duration    int      `json:"duration"`
+	Description string   `json:"description"`
+	Billable    *bool    `json:"billable"`
+	Tags        []string `json:"tags"`
+	Project     Project  `json:"project"`
+	Activity    Activity `json:"activity"`
+}
+
+// UploadEntry represents the payload to create a new timesheet in Kimai.
+// Begin and End must have no offset, since Kimai uses the timezone of the
+// user. Tags are separated by commas.
+type UploadEntry struct {
+	Begin       string `json:"begin"`
+	End         string `json:"end"`
+	Project     int    `json:"project"`
+	Activity    int    `json:"activity"`
+	Description string `json:"description,omitempty"`
+	Tags        string `json:"tags,omitempty"`
+	Billable    bool   `json:"billable"`
+	User        int    `json:"user,omitempty"`
+}
+
+// ProjectItem represents a project returned by the project list.
+type ProjectItem struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Customer    int    `json:"customer"`
+	ParentTitle string `json:"parentTitle"`
+}
+
+// ActivityItem represents an activity returned by the activity list. Global
+// activities have no project.
+type ActivityItem struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Project *int   `json:"project"`
+}
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// If the Username is set, the Token is sent as the API token of the user,
+// otherwise as a bearer token.
+type ClientOpts struct {
+	client.BaseClientOpts
+	client.TokenAuth
+	Username string
+	BaseURL  string
+}
+
+// apiTokenAuth sets the username header besides the API token.
+type apiTokenAuth struct {
+	client.Authenticator
+	username string
+}
+
+func (a *apiTokenAuth) SetAuthHeader(req *http.Request) {
+	req.Header.Set(HeaderAuthUser, a.username)
+	a.Authenticator.SetAuthHeader(req)
+}
+
+type kimaiClient struct {
+	*client.BaseClientOpts
+	*client.HTTPClient
+	*client.DefaultUploader
+	authenticator client.Authenticator
+
+	// The projects and activities are listed once, when the first entry is
+	// uploaded
+	resourcesOnce sync.Once
+	resourcesErr  error
+	projects      []ProjectItem
+	activities    []ActivityItem
+}
+
+// parseDate parses the dates returned by Kimai. Although Kimai returns the
+// offset without colon, RFC3339 dates are accepted as well.
+func parseDate(value string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Parse(time.RFC3339, value)
+	}
+
+	return date, nil
+}
+
+func (c *kimaiClient) parseEntries(rawEntries interface{}, opts *client.FetchOpts) (worklog.Entries, error) {
+	var entries worklog.Entries
+
+	fetchedEntries, ok := rawEntries.([]FetchEntry)
+	if !ok {
+		return nil, fmt.Errorf("%v: %s", client.ErrFetchEntries, "cannot parse returned entries")
+	}
+
+	for _, entry := range fetchedEntries {
+		start, err := parseDate(entry.Begin)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+
+		// The running entries have no end
+		var end time.Time
+		duration := time.Duration(entry.Duration) * time.Second
+		running := entry.End == nil
+
+		if running {
+			var keep bool
+			if end, keep, err = opts.RunningEntryEnd(start); err != nil {
+				return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+			} else if !keep {
+				continue
+			}
+
+			duration = end.Sub(start)
+		} else if end, err = parseDate(*entry.End); err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+
+		billableDuration := duration
+		unbillableDuration := time.Duration(0)
+
+		if entry.Billable != nil && !*entry.Billable {
+			unbillableDuration = billableDuration
+			billableDuration = 0
+		}
+
+		var tags []worklog.IDNameField
+		for _, tag := range entry.Tags {
+			tags = append(tags, worklog.IDNameField{
+				ID:   tag,
+				Name: tag,
+			})
+		}
+
+		worklogEntry := worklog.Entry{
+			Client: worklog.IDNameField{
+				ID:   strconv.Itoa(entry.Project.Customer.ID),
+				Name: entry.Project.Customer.Name,
+			},
+			Project: worklog.IDNameField{
+				ID:   strconv.Itoa(entry.Project.ID),
+				Name: entry.Project.Name,
+			},
+			Task: worklog.IDNameField{
+				ID:   strconv.Itoa(entry.Activity.ID),
+				Name: entry.Activity.Name,
+			},
+			Summary:            entry.Description,
+			Notes:              entry.Description,
+			Tags:               tags,
+			Start:              start.In(c.Location()),
+			End:                end.In(c.Location()),
+			BillableDuration:   billableDuration,
+			UnbillableDuration: unbillableDuration,
+			Source:             SourceName,
+			SourceID:           strconv.Itoa(entry.ID),
+			Running:            running,
+		}
+
+		// Timesheets without description are summarized by their activity
+		if worklogEntry.Summary == "" {
+			worklogEntry.Summary = entry.Activity.Name
+		}
+
+		if utils.IsRegexSet(opts.TagsAsTasksRegex) && len(tags) > 0 {
+			pageEntries := worklogEntry.SplitByTagsAsTasks(worklogEntry.Summary, opts.TagsAsTasksRegex, tags)
+			entries = append(entries, pageEntries...)
+		} else {
+			entries = append(entries, worklogEntry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (c *kimaiClient) fetchEntries(ctx context.Context, reqURL string) (interface{}, *client.PaginatedFetchResponse, error) {
+	resp, header, err := c.CallWithHeader(ctx, &client.HTTPRequestOpts{
+		Method:  http.MethodGet,
+		Url:     reqURL,
+		Auth:    c.authenticator,
+		Timeout: c.Timeout,
+	})
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	var fetchedEntries []FetchEntry
+	if err = json.Unmarshal(resp, &fetchedEntries); err != nil {
+		return nil, nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	// The total count is missing if the response is not paginated
+	totalEntries, _ := strconv.Atoi(header.Get(HeaderTotalCount))
+
+	paginatedResponse := &client.PaginatedFetchResponse{
+		PageEntries:  len(fetchedEntries),
+		TotalEntries: totalEntries,
+	}
+
+	return fetchedEntries, paginatedResponse, err
+}
+
+func (c *kimaiClient) FetchEntriesStream(ctx context.Context, opts *client.FetchOpts) <-chan client.FetchPage {
+	params := map[string]string{
+		"begin": utils.DateFormatRFC3339Local.FormatInLocation(opts.Start, c.Location()),
+		"end":   utils.DateFormatRFC3339Local.FormatInLocation(opts.End, c.Location()),
+		"full":  strconv.FormatBool(true),
+	}
+
+	if opts.User != "" {
+		params["user"] = opts.User
+	}
+
+	// The running entries are needed unless they are skipped anyway
+	if opts.SkipsRunningEntries() {
+		params["active"] = "0"
+	}
+
+	fetchURL, err := c.URL(PathTimesheets, params)
+	if err != nil {
+		return client.FailedFetchStream(fmt.Errorf("%v: %v", client.ErrFetchEntries, err))
+	}
+
+	return c.PaginatedFetchStream(ctx, &client.PaginatedFetchOpts{
+		BaseFetchOpts: opts,
+		URL:           fetchURL,
+		Pagination: &client.PageNumberPagination{
+			PageSizeParam: "size",
+		},
+		FetchFunc: c.fetchEntries,
+		ParseFunc: c.parseEntries,
+	})
+}
+
+func (c *kimaiClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	return client.CollectFetchPages(ctx, c.FetchEntriesStream(ctx, opts))
+}
+
+// list fetches the resources of the API endpoint into the target.
+func (c *kimaiClient) list(ctx context.Context, path string, target interface{}) error {
+	listURL, err := c.URL(path, map[string]string{})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Call(ctx, &client.HTTPRequestOpts{
+		Method:  http.MethodGet,
+		Url:     listURL,
+		Auth:    c.authenticator,
+		Timeout: c.Timeout,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(resp, target)
+}
+
+// loadResources lists the projects and activities once, so the entries can be
+// uploaded concurrently.
+func (c *kimaiClient) loadResources(ctx context.Context) error {
+	c.resourcesOnce.Do(func() {
+		if c.resourcesErr = c.list(ctx, PathProjects, &c.projects); c.resourcesErr != nil {
+			return
+		}
+
+		c.resourcesErr = c.list(ctx, PathActivities, &c.activities)
+	})
+
+	return c.resourcesErr
+}
+
+// findProject returns the ID of the project having the name of the entry's
+// project. If the entry has a client, the customer of the project must have the
+// same name.
+func (c *kimaiClient) findProject(entry worklog.Entry) (int, error) {
+	for _, project := range c.projects {
+		if project.Name != entry.Project.Name {
+			continue
+		}
+
+		if entry.Client.Name == "" || project.ParentTitle == entry.Client.Name {
+			return project.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%v: %s", ErrProjectNotFound, entry.Project.Name)
+}
+
+// findActivity returns the ID of the activity having the name of the entry's
+// task. The activities of the project take precedence over global activities.
+func (c *kimaiClient) findActivity(entry worklog.Entry, projectID int) (int, error) {
+	globalID := 0
+
+	for _, activity := range c.activities {
+		if activity.Name != entry.Task.Name {
+			continue
+		}
+
+		if activity.Project == nil {
+			globalID = activity.ID
+		} else if *activity.Project == projectID {
+			return activity.ID, nil
+		}
+	}
+
+	if globalID == 0 {
+		return 0, fmt.Errorf("%v: %s", ErrActivityNotFound, entry.Task.Name)
+	}
+
+	return globalID, nil
+}
+
+// uploadEntry returns the payload to create the timesheet of the entry. The
+// project and activity are looked up by their names, since the entries can be
+// fetched from other sources.
+func (c *kimaiClient) uploadEntry(entry worklog.Entry, opts *client.UploadOpts) (*UploadEntry, error) {
+	projectID, err := c.findProject(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	activityID, err := c.findActivity(entry, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := c.RenderComment(entry, opts.CommentTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, tag := range entry.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	billableDuration, unbillableDuration := c.Durations(entry, opts)
+	end := entry.Start.Add(billableDuration + unbillableDuration)
+
+	uploadEntry := &UploadEntry{
+		Begin:       utils.DateFormatRFC3339Local.FormatInLocation(entry.Start, c.Location()),
+		End:         utils.DateFormatRFC3339Local.FormatInLocation(end, c.Location()),
+		Project:     projectID,
+		Activity:    activityID,
+		Description: comment,
+		Tags:        strings.Join(tags, ","),
+		Billable:    billableDuration > 0,
+	}
+
+	if opts.User != "" {
+		if uploadEntry.User, err = strconv.Atoi(opts.User); err != nil {
+			return nil, err
+		}
+	}
+
+	return uploadEntry, nil
+}
+
+func (c *kimaiClient) UploadEntries(ctx context.Context, entries worklog.Entries, errChan chan error, opts *client.UploadOpts) {
+	createURL, err := c.URL(PathTimesheets, map[string]string{})
+	if err != nil {
+		errChan <- fmt.Errorf("%v: %v", client.ErrUploadEntries, err)
+		return
+	}
+
+	for _, groupEntries := range entries.GroupByTask() {
+		go func(ctx context.Context, entries worklog.Entries, errChan chan error, opts *client.UploadOpts) {
+			for _, entry := range entries {
+				if err := c.loadResources(ctx); err != nil {
+					errChan <- fmt.Errorf("%v: %v", client.ErrUploadEntries, err)
+					continue
+				}
+
+				uploadEntry, err := c.uploadEntry(entry, opts)
+				if err != nil {
+					errChan <- fmt.Errorf("%v: %v", client.ErrUploadEntries, err)
+					continue
+				}
+
+				tracker := c.StartTracking(entry, opts.ProgressWriter)
+
+				_, err = c.Call(ctx, &client.HTTPRequestOpts{
+					Method:  http.MethodPost,
+					Url:     createURL,
+					Auth:    c.authenticator,
+					Timeout: c.Timeout,
+					Data:    uploadEntry,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+				})
+
+				if err != nil {
+					err = fmt.Errorf("%v: %+v: %v", client.ErrUploadEntries, uploadEntry, err)
+				}
+
+				c.StopTracking(tracker, err)
+				errChan <- err
+			}
+		}(ctx, groupEntries, errChan, opts)
+	}
+}
+
+func newClient(opts *ClientOpts) (*kimaiClient, error) {
+	baseURL, err := url.Parse(opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var authenticator client.Authenticator
+	if opts.Username != "" {
+		if authenticator, err = client.NewTokenAuth(HeaderAuthToken, "", opts.Token); err != nil {
+			return nil, err
+		}
+
+		authenticator = &apiTokenAuth{Authenticator: authenticator, username: opts.Username}
+	} else if authenticator, err = client.NewTokenAuth(opts.Header, "Bearer", opts.Token); err != nil {
+		return nil, err
+	}
+
+	return &kimaiClient{
+		authenticator:  authenticator,
+		HTTPClient:     &client.HTTPClient{BaseURL: baseURL},
+		BaseClientOpts: &opts.BaseClientOpts,
+	}, nil
+}
+
+// NewFetcher returns a new Kimai client for fetching entries.
+func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
+	return newClient(opts)
+}
+
+// NewUploader returns a new Kimai client for uploading entries.
+func NewUploader(opts *ClientOpts) (client.Uploader, error) {
+	return newClient(opts)
+}
@@ -0,0 +1,331 @@
+package kimai_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/kimai"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func newClientOpts(baseURL string) *kimai.ClientOpts {
+	return &kimai.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
+		},
+		TokenAuth: client.TokenAuth{
+			Token: "t-o-k-e-n",
+		},
+		Username: "steve-rogers",
+		BaseURL:  baseURL,
+	}
+}
+
+func TestKimaiClient_FetchEntries(t *testing.T) {
+	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 3, 0, 0, 0, 0, time.UTC)
+
+	project := kimai.Project{
+		ID:       456,
+		Name:     "MARVEL",
+		Customer: kimai.Customer{ID: 123, Name: "My Awesome Company"},
+	}
+
+	var queries []url.Values
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, kimai.PathTimesheets, r.URL.Path)
+		require.Equal(t, "steve-rogers", r.Header.Get(kimai.HeaderAuthUser))
+		require.Equal(t, "t-o-k-e-n", r.Header.Get(kimai.HeaderAuthToken))
+
+		queries = append(queries, r.URL.Query())
+
+		w.Header().Set(kimai.HeaderTotalCount, "2")
+		err := json.NewEncoder(w).Encode([]kimai.FetchEntry{
+			{
+				ID:          1,
+				Begin:       "2021-10-02T09:00:00+0200",
+				End:         stringPtr("2021-10-02T10:30:00+0200"),
+				Duration:    5400,
+				Description: "Meet with The Winter Soldier",
+				Tags:        []string{"meeting"},
+				Project:     project,
+				Activity:    kimai.Activity{ID: 789, Name: "Meeting"},
+			},
+			{
+				ID:       2,
+				Begin:    "2021-10-02T11:00:00+0200",
+				End:      stringPtr("2021-10-02T12:00:00+0200"),
+				Duration: 3000,
+				Billable: boolPtr(false),
+				Project:  project,
+				Activity: kimai.Activity{ID: 790, Name: "Training"},
+			},
+		})
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	kimaiClient, err := kimai.NewFetcher(newClientOpts(mockServer.URL))
+	require.Nil(t, err)
+
+	entries, err := kimaiClient.FetchEntries(context.Background(), &client.FetchOpts{
+		User:  "1",
+		Start: start,
+		End:   end,
+	})
+	require.Nil(t, err, "cannot fetch entries")
+
+	companyClient := worklog.IDNameField{ID: "123", Name: "My Awesome Company"}
+	marvel := worklog.IDNameField{ID: "456", Name: "MARVEL"}
+
+	require.Equal(t, worklog.Entries{
+		{
+			Client:           companyClient,
+			Project:          marvel,
+			Task:             worklog.IDNameField{ID: "789", Name: "Meeting"},
+			Summary:          "Meet with The Winter Soldier",
+			Notes:            "Meet with The Winter Soldier",
+			Tags:             []worklog.IDNameField{{ID: "meeting", Name: "meeting"}},
+			Start:            time.Date(2021, 10, 2, 7, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 2, 8, 30, 0, 0, time.UTC),
+			BillableDuration: 90 * time.Minute,
+			Source:           kimai.SourceName,
+			SourceID:         "1",
+		},
+		{
+			Client:             companyClient,
+			Project:            marvel,
+			Task:               worklog.IDNameField{ID: "790", Name: "Training"},
+			Summary:            "Training",
+			Start:              time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC),
+			End:                time.Date(2021, 10, 2, 10, 0, 0, 0, time.UTC),
+			UnbillableDuration: 50 * time.Minute,
+			Source:             kimai.SourceName,
+			SourceID:           "2",
+		},
+	}, entries)
+
+	require.Len(t, queries, 1)
+	require.Equal(t, "2021-10-02T00:00:00", queries[0].Get("begin"))
+	require.Equal(t, "2021-10-03T00:00:00", queries[0].Get("end"))
+	require.Equal(t, "1", queries[0].Get("user"))
+	require.Equal(t, "true", queries[0].Get("full"))
+	require.Equal(t, "0", queries[0].Get("active"))
+	require.Equal(t, "1", queries[0].Get("page"))
+	require.Equal(t, strconv.Itoa(client.DefaultPageSize), queries[0].Get("size"))
+}
+
+func TestKimaiClient_FetchEntries_Pagination(t *testing.T) {
+	totalEntries := client.DefaultPageSize + 1
+	var pages []string
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages = append(pages, r.URL.Query().Get("page"))
+
+		pageEntries := client.DefaultPageSize
+		if page > 1 {
+			pageEntries = totalEntries - client.DefaultPageSize
+		}
+
+		fetchedEntries := make([]kimai.FetchEntry, pageEntries)
+		for i := range fetchedEntries {
+			fetchedEntries[i] = kimai.FetchEntry{
+				ID:       (page-1)*client.DefaultPageSize + i + 1,
+				Begin:    "2021-10-02T09:00:00+0000",
+				End:      stringPtr("2021-10-02T09:01:00+0000"),
+				Duration: 60,
+			}
+		}
+
+		w.Header().Set(kimai.HeaderTotalCount, strconv.Itoa(totalEntries))
+		err := json.NewEncoder(w).Encode(fetchedEntries)
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	kimaiClient, err := kimai.NewFetcher(newClientOpts(mockServer.URL))
+	require.Nil(t, err)
+
+	entries, err := kimaiClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 3, 0, 0, 0, 0, time.UTC),
+	})
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, totalEntries)
+	require.Equal(t, []string{"1", "2"}, pages)
+}
+
+func TestKimaiClient_FetchEntries_RunningEntries(t *testing.T) {
+	started := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	var queries []url.Values
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer t-o-k-e-n", r.Header.Get("Authorization"))
+		require.Empty(t, r.Header.Get(kimai.HeaderAuthUser))
+
+		queries = append(queries, r.URL.Query())
+
+		err := json.NewEncoder(w).Encode([]kimai.FetchEntry{
+			{
+				ID:    1,
+				Begin: started.Format("2006-01-02T15:04:05-0700"),
+			},
+		})
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	clientOpts := newClientOpts(mockServer.URL)
+	clientOpts.Username = ""
+
+	kimaiClient, err := kimai.NewFetcher(clientOpts)
+	require.Nil(t, err)
+
+	entries, err := kimaiClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start:          started.Add(-time.Hour),
+		End:            started.Add(2 * time.Hour),
+		RunningEntries: client.RunningEntriesUntilNow,
+	})
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].Running)
+	require.WithinDuration(t, time.Now(), entries[0].End, time.Second)
+	require.Equal(t, entries[0].End.Sub(started), entries[0].BillableDuration)
+
+	require.Len(t, queries, 1)
+	require.NotContains(t, queries[0], "active")
+}
+
+func TestKimaiClient_UploadEntries(t *testing.T) {
+	start := time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC)
+
+	var lock sync.Mutex
+	var uploadedEntries []kimai.UploadEntry
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "t-o-k-e-n", r.Header.Get(kimai.HeaderAuthToken))
+
+		var err error
+		switch r.URL.Path {
+		case kimai.PathProjects:
+			err = json.NewEncoder(w).Encode([]kimai.ProjectItem{
+				{ID: 455, Name: "MARVEL", Customer: 122, ParentTitle: "Other Company"},
+				{ID: 456, Name: "MARVEL", Customer: 123, ParentTitle: "My Awesome Company"},
+			})
+		case kimai.PathActivities:
+			err = json.NewEncoder(w).Encode([]kimai.ActivityItem{
+				{ID: 788, Name: "Meeting"},
+				{ID: 789, Name: "Meeting", Project: intPtr(456)},
+				{ID: 790, Name: "Training"},
+			})
+		case kimai.PathTimesheets:
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			var uploadEntry kimai.UploadEntry
+			err = json.NewDecoder(r.Body).Decode(&uploadEntry)
+
+			lock.Lock()
+			uploadedEntries = append(uploadedEntries, uploadEntry)
+			lock.Unlock()
+		default:
+			require.Failf(t, "unexpected path", r.URL.Path)
+		}
+
+		require.Nil(t, err)
+	}))
+	defer mockServer.Close()
+
+	companyClient := worklog.IDNameField{ID: "My Awesome Company", Name: "My Awesome Company"}
+	marvel := worklog.IDNameField{ID: "MARVEL", Name: "MARVEL"}
+
+	entries := worklog.Entries{
+		{
+			Client:           companyClient,
+			Project:          marvel,
+			Task:             worklog.IDNameField{ID: "Meeting", Name: "Meeting"},
+			Summary:          "Meet with The Winter Soldier",
+			Tags:             []worklog.IDNameField{{ID: "meeting", Name: "meeting"}, {ID: "remote", Name: "remote"}},
+			Start:            start,
+			BillableDuration: time.Hour,
+		},
+		{
+			Client:             companyClient,
+			Project:            marvel,
+			Task:               worklog.IDNameField{ID: "Training", Name: "Training"},
+			Summary:            "Train with Natasha",
+			Start:              start.Add(2 * time.Hour),
+			UnbillableDuration: 30 * time.Minute,
+		},
+		{
+			Client:           companyClient,
+			Project:          marvel,
+			Task:             worklog.IDNameField{ID: "Fighting", Name: "Fighting"},
+			Summary:          "Fight with Thanos",
+			Start:            start.Add(4 * time.Hour),
+			BillableDuration: time.Hour,
+		},
+	}
+
+	kimaiClient, err := kimai.NewUploader(newClientOpts(mockServer.URL))
+	require.Nil(t, err)
+
+	errChan := make(chan error)
+	kimaiClient.UploadEntries(context.Background(), entries, errChan, &client.UploadOpts{User: "1"})
+
+	var errs []error
+	for i := 0; i < len(entries); i++ {
+		if err := <-errChan; err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], kimai.ErrActivityNotFound.Error())
+
+	require.ElementsMatch(t, []kimai.UploadEntry{
+		{
+			Begin:       "2021-10-02T09:00:00",
+			End:         "2021-10-02T10:00:00",
+			Project:     456,
+			Activity:    789,
+			Description: "Meet with The Winter Soldier",
+			Tags:        "meeting,remote",
+			Billable:    true,
+			User:        1,
+		},
+		{
+			Begin:       "2021-10-02T11:00:00",
+			End:         "2021-10-02T11:30:00",
+			Project:     456,
+			Activity:    790,
+			Description: "Train with Natasha",
+			User:        1,
+		},
+	}, uploadedEntries)
+}
@@ -0,0 +1,190 @@
+package org
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "org"
+	// DefaultClientProperty is the default property of the client name.
+	DefaultClientProperty string = "CLIENT"
+	// DefaultProjectProperty is the default property of the project name.
+	DefaultProjectProperty string = "PROJECT"
+	// DefaultTaskProperty is the default property of the task name.
+	DefaultTaskProperty string = "JIRA"
+)
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// The CLOCK lines are read from the Org files matching the Paths, which can be
+// glob patterns. The client, project, and task are read from the properties of
+// the headings, named by ClientProperty, ProjectProperty, and TaskProperty.
+type ClientOpts struct {
+	client.BaseClientOpts
+	Paths           []string
+	ClientProperty  string
+	ProjectProperty string
+	TaskProperty    string
+	UnbillableTag   string
+}
+
+type orgClient struct {
+	*client.BaseClientOpts
+	paths           []string
+	clientProperty  string
+	projectProperty string
+	taskProperty    string
+	unbillableTag   string
+}
+
+func (c *orgClient) parseClock(clock Clock, opts *client.FetchOpts) (worklog.Entries, error) {
+	heading := clock.Heading
+	endDate := clock.End
+
+	running := clock.End.IsZero()
+	if running {
+		var keep bool
+		var err error
+		if endDate, keep, err = opts.RunningEntryEnd(clock.Start); err != nil || !keep {
+			return nil, err
+		}
+	}
+
+	var tags []worklog.IDNameField
+	for _, tag := range heading.Tags {
+		tags = append(tags, worklog.IDNameField{
+			ID:   tag,
+			Name: tag,
+		})
+	}
+
+	clientName := heading.Property(c.clientProperty)
+	projectName := heading.Property(c.projectProperty)
+	taskName := heading.Property(c.taskProperty)
+
+	worklogEntry := worklog.Entry{
+		Client: worklog.IDNameField{
+			ID:   clientName,
+			Name: clientName,
+		},
+		Project: worklog.IDNameField{
+			ID:   projectName,
+			Name: projectName,
+		},
+		Task: worklog.IDNameField{
+			ID:   taskName,
+			Name: taskName,
+		},
+		Summary:            heading.Title,
+		Notes:              heading.Title,
+		Tags:               tags,
+		Start:              clock.Start,
+		End:                endDate,
+		BillableDuration:   endDate.Sub(clock.Start),
+		UnbillableDuration: 0,
+		Source:             SourceName,
+		SourceID:           fmt.Sprintf("%s:%d", clock.Path, clock.Line),
+		Running:            running,
+	}
+
+	hasTaskTag := false
+	for _, tag := range heading.Tags {
+		if tag == c.unbillableTag {
+			worklogEntry.UnbillableDuration = worklogEntry.BillableDuration
+			worklogEntry.BillableDuration = 0
+		} else if utils.IsRegexSet(opts.TagsAsTasksRegex) && opts.TagsAsTasksRegex.MatchString(tag) {
+			hasTaskTag = true
+		}
+	}
+
+	// The task property takes precedence over the tags
+	if worklogEntry.Task.IsComplete() {
+		return worklog.Entries{worklogEntry}, nil
+	}
+
+	if hasTaskTag {
+		return worklogEntry.SplitByTagsAsTasks(worklogEntry.Summary, opts.TagsAsTasksRegex, tags), nil
+	}
+
+	// If the task was not found, make sure to set it to the heading title
+	worklogEntry.Task = worklog.IDNameField{
+		ID:   heading.Title,
+		Name: heading.Title,
+	}
+
+	return worklog.Entries{worklogEntry}, nil
+}
+
+func (c *orgClient) FetchEntries(_ context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	// The patterns are expanded on every fetch to pick up the new files
+	files, err := ExpandPaths(c.paths)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	var entries worklog.Entries
+	for _, file := range files {
+		clocks, err := ReadFile(file, c.Location())
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+
+		for _, clock := range clocks {
+			// The running clock is treated as it would end now
+			clockEnd := clock.End
+			if clockEnd.IsZero() {
+				clockEnd = time.Now()
+			}
+
+			if !clock.Start.Before(opts.End) || !clockEnd.After(opts.Start) {
+				continue
+			}
+
+			parsedEntries, err := c.parseClock(clock, opts)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+			}
+
+			entries = append(entries, parsedEntries...)
+		}
+	}
+
+	return entries, nil
+}
+
+// NewFetcher returns a new Org client for fetching entries.
+func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
+	if len(opts.Paths) == 0 {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, ErrNoFiles)
+	}
+
+	clientProperty := opts.ClientProperty
+	if clientProperty == "" {
+		clientProperty = DefaultClientProperty
+	}
+
+	projectProperty := opts.ProjectProperty
+	if projectProperty == "" {
+		projectProperty = DefaultProjectProperty
+	}
+
+	taskProperty := opts.TaskProperty
+	if taskProperty == "" {
+		taskProperty = DefaultTaskProperty
+	}
+
+	return &orgClient{
+		BaseClientOpts:  &opts.BaseClientOpts,
+		paths:           opts.Paths,
+		clientProperty:  clientProperty,
+		projectProperty: projectProperty,
+		taskProperty:    taskProperty,
+		unbillableTag:   opts.UnbillableTag,
+	}, nil
+}
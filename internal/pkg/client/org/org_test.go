@@ -0,0 +1,150 @@
+package org_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/org"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		require.Nil(t, err)
+	}
+
+	return dir
+}
+
+func newFetcher(t *testing.T, paths ...string) client.Fetcher {
+	fetcher, err := org.NewFetcher(&org.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Paths:         paths,
+		UnbillableTag: "unbillable",
+	})
+	require.Nil(t, err)
+
+	return fetcher
+}
+
+func TestOrgClient_FetchEntries(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"work.org": document,
+		"private.org": `* Learning                                          :unbillable:
+  CLOCK: [2021-10-12 Tue 18:00]--[2021-10-12 Tue 19:00] =>  1:00
+  CLOCK: [2021-10-13 Wed 18:00]--[2021-10-13 Wed 19:00] =>  1:00
+`,
+	})
+
+	fetcher := newFetcher(t, filepath.Join(dir, "*.org"))
+	entries, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start:            time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:              time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+		TagsAsTasksRegex: regexp.MustCompile(`^TASK_\d+$`),
+	})
+	require.Nil(t, err)
+
+	learning := worklog.IDNameField{ID: "Learning", Name: "Learning"}
+	acme := worklog.IDNameField{ID: "ACME", Name: "ACME"}
+	website := worklog.IDNameField{ID: "website", Name: "website"}
+	taskTags := []worklog.IDNameField{
+		{ID: "work", Name: "work"},
+		{ID: "web", Name: "web"},
+		{ID: "TASK_1", Name: "TASK_1"},
+	}
+	workPath := filepath.Join(dir, "work.org")
+
+	require.Equal(t, worklog.Entries{
+		{
+			Task:               learning,
+			Summary:            "Learning",
+			Notes:              "Learning",
+			Tags:               []worklog.IDNameField{{ID: "unbillable", Name: "unbillable"}},
+			Start:              time.Date(2021, 10, 12, 18, 0, 0, 0, time.UTC),
+			End:                time.Date(2021, 10, 12, 19, 0, 0, 0, time.UTC),
+			UnbillableDuration: time.Hour,
+			Source:             org.SourceName,
+			SourceID:           filepath.Join(dir, "private.org") + ":2",
+		},
+		{
+			Client:           acme,
+			Project:          website,
+			Task:             worklog.IDNameField{ID: "TASK_1", Name: "TASK_1"},
+			Summary:          "Fix the login form",
+			Notes:            "Fix the login form",
+			Tags:             taskTags,
+			Start:            time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 10, 30, 0, 0, time.UTC),
+			BillableDuration: 90 * time.Minute,
+			Source:           org.SourceName,
+			SourceID:         workPath + ":12",
+		},
+		{
+			Client:           acme,
+			Project:          website,
+			Task:             worklog.IDNameField{ID: "TASK_1", Name: "TASK_1"},
+			Summary:          "Fix the login form",
+			Notes:            "Fix the login form",
+			Tags:             taskTags,
+			Start:            time.Date(2021, 10, 12, 8, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 8, 15, 0, 0, time.UTC),
+			BillableDuration: 15 * time.Minute,
+			Source:           org.SourceName,
+			SourceID:         workPath + ":13",
+		},
+		{
+			Client:           worklog.IDNameField{ID: "Internal", Name: "Internal"},
+			Task:             worklog.IDNameField{ID: "Internal", Name: "Internal"},
+			Summary:          "Internal",
+			Notes:            "Internal",
+			Tags:             []worklog.IDNameField{{ID: "work", Name: "work"}},
+			Start:            time.Date(2021, 10, 12, 13, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 14, 0, 0, 0, time.UTC),
+			BillableDuration: time.Hour,
+			Source:           org.SourceName,
+			SourceID:         workPath + ":24",
+		},
+	}, entries)
+}
+
+func TestOrgClient_FetchEntries_Running(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Minute)
+	dir := writeFiles(t, map[string]string{
+		"work.org": "* Review\n  :PROPERTIES:\n  :JIRA: ABC-1\n  :END:\n  CLOCK: [" + start.Format("2006-01-02 Mon 15:04") + "]\n",
+	})
+
+	fetcher := newFetcher(t, filepath.Join(dir, "work.org"))
+	fetchOpts := &client.FetchOpts{
+		Start:          start.Add(-time.Hour),
+		End:            start.Add(2 * time.Hour),
+		RunningEntries: client.RunningEntriesUntilNow,
+	}
+
+	entries, err := fetcher.FetchEntries(context.Background(), fetchOpts)
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].Running)
+	require.Equal(t, worklog.IDNameField{ID: "ABC-1", Name: "ABC-1"}, entries[0].Task)
+	require.Equal(t, start, entries[0].Start)
+
+	fetchOpts.RunningEntries = client.RunningEntriesSkip
+	entries, err = fetcher.FetchEntries(context.Background(), fetchOpts)
+	require.Nil(t, err)
+	require.Empty(t, entries)
+}
+
+func TestNewFetcher_NoPaths(t *testing.T) {
+	_, err := org.NewFetcher(&org.ClientOpts{})
+	require.ErrorContains(t, err, org.ErrNoFiles.Error())
+}
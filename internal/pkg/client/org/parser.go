@@ -0,0 +1,306 @@
+package org
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidClock returns when a CLOCK line cannot be parsed.
+	ErrInvalidClock = errors.New("invalid clock")
+	// ErrNoFiles returns when a path or pattern matches no files.
+	ErrNoFiles = errors.New("no files found")
+
+	// DefaultTodoKeywords are the keywords of the headings used by Org mode
+	// unless the file sets its own keywords.
+	DefaultTodoKeywords = []string{"TODO", "DONE"}
+
+	headingRegex   = regexp.MustCompile(`^(\*+)\s+(.*?)\s*$`)
+	tagsRegex      = regexp.MustCompile(`^(.*?)\s*(:(?:[\w@#%]+:)+)$`)
+	priorityRegex  = regexp.MustCompile(`^\[#[A-Za-z0-9]\]\s*`)
+	propertyRegex  = regexp.MustCompile(`^\s*:([^:\s]+):\s*(.*?)\s*$`)
+	keywordRegex   = regexp.MustCompile(`^#\+(\w+):\s*(.*?)\s*$`)
+	clockRegex     = regexp.MustCompile(`^\s*CLOCK:\s*\[([^]]+)\](?:--\[([^]]+)\])?`)
+	timestampRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:\s+[^\d\s]+)?\s+(\d{1,2}:\d{2})$`)
+)
+
+// Heading represents a heading of an Org file. The tags and properties are
+// including the ones inherited from the parent headings and the file.
+type Heading struct {
+	Title      string
+	Tags       []string
+	Properties map[string]string
+}
+
+// Property returns the value of the property. Property names are case
+// insensitive.
+func (h *Heading) Property(name string) string {
+	return h.Properties[strings.ToUpper(name)]
+}
+
+// Clock represents a CLOCK line of an Org file. The running clock has no end.
+type Clock struct {
+	Heading Heading
+	Start   time.Time
+	End     time.Time
+	Path    string
+	Line    int
+}
+
+// parseTimestamp parses the inactive timestamp of a CLOCK line, like
+// "2021-10-12 Tue 09:00". The name of the day depends on the locale, so it is
+// not validated.
+func parseTimestamp(value string, loc *time.Location) (time.Time, error) {
+	matches := timestampRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil {
+		return time.Time{}, fmt.Errorf("%v: %s", ErrInvalidClock, value)
+	}
+
+	clockTime := matches[2]
+	if len(clockTime) < len("15:04") {
+		clockTime = "0" + clockTime
+	}
+
+	return time.ParseInLocation("2006-01-02 15:04", matches[1]+" "+clockTime, loc)
+}
+
+// parseTags returns the tags of a tags string, like ":work:TASK-1:".
+func parseTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ":") {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// appendTags appends the tags to the inherited tags, skipping duplicates.
+func appendTags(inherited []string, tags []string) []string {
+	merged := append([]string{}, inherited...)
+
+	for _, tag := range tags {
+		found := false
+		for _, existing := range merged {
+			if existing == tag {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			merged = append(merged, tag)
+		}
+	}
+
+	return merged
+}
+
+// parseHeading returns the title and tags of the heading text, dropping the
+// todo keyword and the priority.
+func parseHeading(text string, todoKeywords []string) (string, []string) {
+	var tags []string
+	if matches := tagsRegex.FindStringSubmatch(text); matches != nil {
+		text = matches[1]
+		tags = parseTags(matches[2])
+	}
+
+	if keyword, rest, _ := strings.Cut(text, " "); keyword != "" {
+		for _, todoKeyword := range todoKeywords {
+			if keyword == todoKeyword {
+				text = strings.TrimSpace(rest)
+				break
+			}
+		}
+	}
+
+	return priorityRegex.ReplaceAllString(text, ""), tags
+}
+
+// parseTodoKeywords returns the keywords set by a TODO keyword line, like
+// "TODO NEXT | DONE CANCELLED". The fast access keys are removed.
+func parseTodoKeywords(value string) []string {
+	var keywords []string
+	for _, keyword := range strings.Fields(value) {
+		if keyword == "|" {
+			continue
+		}
+
+		if name, _, found := strings.Cut(keyword, "("); found {
+			keyword = name
+		}
+
+		keywords = append(keywords, keyword)
+	}
+
+	return keywords
+}
+
+// headingLevel represents a heading on the path to the current heading.
+type headingLevel struct {
+	level   int
+	heading Heading
+}
+
+// ParseClocks returns the CLOCK lines of the Org document, read from the
+// reader. The timestamps are parsed in the given location, and the path is set
+// on the clocks to identify them. CLOCK lines before the first heading are
+// ignored, since they are not belonging to any heading.
+func ParseClocks(r io.Reader, path string, loc *time.Location) ([]Clock, error) {
+	var clocks []Clock
+	var levels []headingLevel
+	var fileTodoKeywords []string
+
+	fileHeading := Heading{Properties: map[string]string{}}
+	inProperties := false
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber++
+
+		if matches := headingRegex.FindStringSubmatch(line); matches != nil {
+			level := len(matches[1])
+			for len(levels) > 0 && levels[len(levels)-1].level >= level {
+				levels = levels[:len(levels)-1]
+			}
+
+			parent := fileHeading
+			if len(levels) > 0 {
+				parent = levels[len(levels)-1].heading
+			}
+
+			todoKeywords := DefaultTodoKeywords
+			if len(fileTodoKeywords) > 0 {
+				todoKeywords = fileTodoKeywords
+			}
+
+			title, tags := parseHeading(matches[2], todoKeywords)
+
+			properties := map[string]string{}
+			for name, value := range parent.Properties {
+				properties[name] = value
+			}
+
+			levels = append(levels, headingLevel{
+				level: level,
+				heading: Heading{
+					Title:      title,
+					Tags:       appendTags(parent.Tags, tags),
+					Properties: properties,
+				},
+			})
+
+			inProperties = false
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+
+		if len(levels) == 0 {
+			// File level settings, like "#+FILETAGS: :work:"
+			if matches := keywordRegex.FindStringSubmatch(trimmed); matches != nil {
+				switch strings.ToUpper(matches[1]) {
+				case "FILETAGS":
+					fileHeading.Tags = appendTags(fileHeading.Tags, parseTags(matches[2]))
+				case "PROPERTY":
+					if name, value, found := strings.Cut(matches[2], " "); found {
+						fileHeading.Properties[strings.ToUpper(name)] = strings.TrimSpace(value)
+					}
+				case "TODO", "SEQ_TODO", "TYP_TODO":
+					fileTodoKeywords = append(fileTodoKeywords, parseTodoKeywords(matches[2])...)
+				}
+			}
+
+			continue
+		}
+
+		current := &levels[len(levels)-1].heading
+
+		switch {
+		case strings.EqualFold(trimmed, ":PROPERTIES:"):
+			inProperties = true
+		case inProperties && strings.EqualFold(trimmed, ":END:"):
+			inProperties = false
+		case inProperties:
+			if matches := propertyRegex.FindStringSubmatch(line); matches != nil {
+				current.Properties[strings.ToUpper(matches[1])] = matches[2]
+			}
+		default:
+			matches := clockRegex.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+
+			start, err := parseTimestamp(matches[1], loc)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, lineNumber, err)
+			}
+
+			var end time.Time
+			if matches[2] != "" {
+				if end, err = parseTimestamp(matches[2], loc); err != nil {
+					return nil, fmt.Errorf("%s:%d: %v", path, lineNumber, err)
+				}
+			}
+
+			clocks = append(clocks, Clock{
+				Heading: *current,
+				Start:   start,
+				End:     end,
+				Path:    path,
+				Line:    lineNumber,
+			})
+		}
+	}
+
+	return clocks, scanner.Err()
+}
+
+// ReadFile returns the CLOCK lines of the Org file.
+func ReadFile(path string, loc *time.Location) ([]Clock, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return ParseClocks(file, path, loc)
+}
+
+// ExpandPaths returns the files matching the paths, which can be glob patterns,
+// like "/home/steve/org/*.org". Every path must match at least one file.
+func ExpandPaths(paths []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+
+	for _, path := range paths {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%v: %s", ErrNoFiles, path)
+		}
+
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,113 @@
+package org_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client/org"
+	"github.com/stretchr/testify/require"
+)
+
+const document = `#+TITLE: Work
+#+FILETAGS: :work:
+#+PROPERTY: CLIENT ACME
+#+TODO: TODO NEXT(n) | DONE(d) CANCELLED
+
+* Website                                                          :web:
+  :PROPERTIES:
+  :PROJECT: website
+  :END:
+** NEXT [#A] Fix the login form                                  :TASK_1:
+   :LOGBOOK:
+   CLOCK: [2021-10-12 Tue 09:00]--[2021-10-12 Tue 10:30] =>  1:30
+   CLOCK: [2021-10-12 Tue 8:00]--[2021-10-12 Tue 08:15] =>  0:15
+   :END:
+*** Review
+    :PROPERTIES:
+    :jira: ABC-1
+    :END:
+    CLOCK: [2021-10-12 Tue 11:00]
+* Internal
+  :PROPERTIES:
+  :CLIENT: Internal
+  :END:
+  CLOCK: [2021-10-12 Tue 13:00]--[2021-10-12 Tue 14:00] =>  1:00
+`
+
+func TestParseClocks(t *testing.T) {
+	clocks, err := org.ParseClocks(strings.NewReader(document), "work.org", time.UTC)
+	require.Nil(t, err)
+
+	fixLoginForm := org.Heading{
+		Title:      "Fix the login form",
+		Tags:       []string{"work", "web", "TASK_1"},
+		Properties: map[string]string{"CLIENT": "ACME", "PROJECT": "website"},
+	}
+
+	require.Equal(t, []org.Clock{
+		{
+			Heading: fixLoginForm,
+			Start:   time.Date(2021, 10, 12, 9, 0, 0, 0, time.UTC),
+			End:     time.Date(2021, 10, 12, 10, 30, 0, 0, time.UTC),
+			Path:    "work.org",
+			Line:    12,
+		},
+		{
+			Heading: fixLoginForm,
+			Start:   time.Date(2021, 10, 12, 8, 0, 0, 0, time.UTC),
+			End:     time.Date(2021, 10, 12, 8, 15, 0, 0, time.UTC),
+			Path:    "work.org",
+			Line:    13,
+		},
+		{
+			Heading: org.Heading{
+				Title:      "Review",
+				Tags:       []string{"work", "web", "TASK_1"},
+				Properties: map[string]string{"CLIENT": "ACME", "PROJECT": "website", "JIRA": "ABC-1"},
+			},
+			Start: time.Date(2021, 10, 12, 11, 0, 0, 0, time.UTC),
+			Path:  "work.org",
+			Line:  19,
+		},
+		{
+			Heading: org.Heading{
+				Title:      "Internal",
+				Tags:       []string{"work"},
+				Properties: map[string]string{"CLIENT": "Internal"},
+			},
+			Start: time.Date(2021, 10, 12, 13, 0, 0, 0, time.UTC),
+			End:   time.Date(2021, 10, 12, 14, 0, 0, 0, time.UTC),
+			Path:  "work.org",
+			Line:  24,
+		},
+	}, clocks)
+}
+
+func TestParseClocks_Invalid(t *testing.T) {
+	for _, line := range []string{
+		"CLOCK: [2021/10/12 Tue 09:00]--[2021-10-12 Tue 10:30]",
+		"CLOCK: [2021-10-12 Tue]--[2021-10-12 Tue 10:30]",
+		"CLOCK: [2021-10-12 Tue 09:00]--[2021-10-12 Tue 25:30]",
+	} {
+		t.Run(line, func(t *testing.T) {
+			_, err := org.ParseClocks(strings.NewReader("* Heading\n"+line+"\n"), "work.org", time.UTC)
+			require.ErrorContains(t, err, "work.org:2")
+		})
+	}
+}
+
+func TestExpandPaths(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"work.org":    "",
+		"private.org": "",
+		"notes.txt":   "",
+	})
+
+	files, err := org.ExpandPaths([]string{dir + "/*.org", dir + "/work.org"})
+	require.Nil(t, err)
+	require.Equal(t, []string{dir + "/private.org", dir + "/work.org"}, files)
+
+	_, err = org.ExpandPaths([]string{dir + "/*.md"})
+	require.ErrorContains(t, err, org.ErrNoFiles.Error())
+}
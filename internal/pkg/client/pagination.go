@@ -0,0 +1,319 @@
+package client
+
+import (
+	netURL "net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultOffsetParam used by offset paginated fetchers setting the offset
+	// parameter.
+	DefaultOffsetParam string = "offset"
+	// DefaultLimitParam used by offset paginated fetchers setting the limit
+	// parameter.
+	DefaultLimitParam string = "limit"
+	// DefaultCursorParam used by cursor paginated fetchers setting the cursor
+	// parameter.
+	DefaultCursorParam string = "cursor"
+)
+
+// Pagination specifies how the pages of a paginated API are navigated.
+// The fetching stops when the next page's URL is empty or a page has no
+// entries.
+type Pagination interface {
+	// FirstPage returns the URL of the first page, derived from the URL of
+	// the API endpoint.
+	FirstPage(apiURL string, pageSize int) (string, error)
+	// NextPage returns the URL of the page after the given page, based on the
+	// response of the page. If there are no more pages, an empty string
+	// returns.
+	NextPage(pageURL string, pageSize int, resp *PaginatedFetchResponse) (string, error)
+}
+
+// setQueryParams returns the URL with the given query params set.
+func setQueryParams(rawURL string, params map[string]string) (string, error) {
+	url, err := netURL.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Query()
+	for key, val := range params {
+		query.Set(key, val)
+	}
+
+	url.RawQuery = query.Encode()
+	return url.String(), nil
+}
+
+// getQueryParam returns the integer query param of the URL. If the param is not
+// set or not an integer, the fallback returns.
+func getQueryParam(rawURL string, param string, fallback int) (int, error) {
+	url, err := netURL.Parse(rawURL)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.Atoi(url.Query().Get(param))
+	if err != nil {
+		return fallback, nil
+	}
+
+	return value, nil
+}
+
+// resolveURL returns the reference resolved relative to the base URL, so both
+// absolute and relative next page URLs can be used.
+func resolveURL(baseURL string, reference string) (string, error) {
+	base, err := netURL.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := netURL.Parse(reference)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
+
+// isLastPage returns true if the response indicates that no more pages left.
+// A page is the last if it is empty, shorter than the page size, or the total
+// number of entries is reached.
+func isLastPage(fetched int, pageSize int, resp *PaginatedFetchResponse) bool {
+	if resp.EntriesPerPage > 0 {
+		pageSize = resp.EntriesPerPage
+	}
+
+	if resp.PageEntries == 0 || resp.PageEntries < pageSize {
+		return true
+	}
+
+	return resp.TotalEntries > 0 && fetched >= resp.TotalEntries
+}
+
+// PageNumberPagination navigates the pages by their number, starting from 1.
+// Used by APIs like "?page=2&per_page=50". If the API caps the page size, the
+// capped size must be returned as EntriesPerPage, otherwise the fetching stops
+// after the first page.
+type PageNumberPagination struct {
+	// PageParam is the name of the page number param, defaults to
+	// DefaultPageParam.
+	PageParam string
+	// PageSizeParam is the name of the page size param, defaults to
+	// DefaultPageSizeParam.
+	PageSizeParam string
+}
+
+func (p *PageNumberPagination) params() (string, string) {
+	pageParam := p.PageParam
+	if pageParam == "" {
+		pageParam = DefaultPageParam
+	}
+
+	pageSizeParam := p.PageSizeParam
+	if pageSizeParam == "" {
+		pageSizeParam = DefaultPageSizeParam
+	}
+
+	return pageParam, pageSizeParam
+}
+
+func (p *PageNumberPagination) FirstPage(apiURL string, pageSize int) (string, error) {
+	pageParam, pageSizeParam := p.params()
+
+	return setQueryParams(apiURL, map[string]string{
+		pageParam:     "1",
+		pageSizeParam: strconv.Itoa(pageSize),
+	})
+}
+
+func (p *PageNumberPagination) NextPage(pageURL string, pageSize int, resp *PaginatedFetchResponse) (string, error) {
+	pageParam, _ := p.params()
+
+	page, err := getQueryParam(pageURL, pageParam, 1)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.EntriesPerPage > 0 {
+		pageSize = resp.EntriesPerPage
+	}
+
+	if isLastPage(page*pageSize, pageSize, resp) {
+		return "", nil
+	}
+
+	return setQueryParams(pageURL, map[string]string{
+		pageParam: strconv.Itoa(page + 1),
+	})
+}
+
+// OffsetPagination navigates the pages by the number of entries to skip, like
+// "?offset=100&limit=50".
+type OffsetPagination struct {
+	// OffsetParam is the name of the offset param, defaults to
+	// DefaultOffsetParam.
+	OffsetParam string
+	// LimitParam is the name of the page size param, defaults to
+	// DefaultLimitParam.
+	LimitParam string
+}
+
+func (p *OffsetPagination) params() (string, string) {
+	offsetParam := p.OffsetParam
+	if offsetParam == "" {
+		offsetParam = DefaultOffsetParam
+	}
+
+	limitParam := p.LimitParam
+	if limitParam == "" {
+		limitParam = DefaultLimitParam
+	}
+
+	return offsetParam, limitParam
+}
+
+func (p *OffsetPagination) FirstPage(apiURL string, pageSize int) (string, error) {
+	offsetParam, limitParam := p.params()
+
+	return setQueryParams(apiURL, map[string]string{
+		offsetParam: "0",
+		limitParam:  strconv.Itoa(pageSize),
+	})
+}
+
+func (p *OffsetPagination) NextPage(pageURL string, pageSize int, resp *PaginatedFetchResponse) (string, error) {
+	offsetParam, _ := p.params()
+
+	offset, err := getQueryParam(pageURL, offsetParam, 0)
+	if err != nil {
+		return "", err
+	}
+
+	offset += resp.PageEntries
+
+	if isLastPage(offset, pageSize, resp) {
+		return "", nil
+	}
+
+	return setQueryParams(pageURL, map[string]string{
+		offsetParam: strconv.Itoa(offset),
+	})
+}
+
+// CursorPagination navigates the pages by an opaque cursor returned with the
+// page as NextCursor, like "?cursor=eyJpZCI6MTIzfQ".
+type CursorPagination struct {
+	// CursorParam is the name of the cursor param, defaults to
+	// DefaultCursorParam.
+	CursorParam string
+	// PageSizeParam is the name of the page size param, defaults to
+	// DefaultPageSizeParam.
+	PageSizeParam string
+}
+
+func (p *CursorPagination) FirstPage(apiURL string, pageSize int) (string, error) {
+	return setPageSize(apiURL, p.PageSizeParam, pageSize)
+}
+
+func (p *CursorPagination) NextPage(pageURL string, _ int, resp *PaginatedFetchResponse) (string, error) {
+	if resp.NextCursor == "" || resp.PageEntries == 0 {
+		return "", nil
+	}
+
+	cursorParam := p.CursorParam
+	if cursorParam == "" {
+		cursorParam = DefaultCursorParam
+	}
+
+	return setQueryParams(pageURL, map[string]string{
+		cursorParam: resp.NextCursor,
+	})
+}
+
+// LinkHeaderPagination navigates the pages by following the "next" relation
+// of the RFC 5988 Link header, returned with the page as Header.
+type LinkHeaderPagination struct {
+	// PageSizeParam is the name of the page size param, defaults to
+	// DefaultPageSizeParam.
+	PageSizeParam string
+}
+
+// parseLinkHeader returns the URLs of the Link header by their relation.
+func parseLinkHeader(header string) map[string]string {
+	links := map[string]string{}
+
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+
+		url := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(url, "<") || !strings.HasSuffix(url, ">") {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(key) != "rel" {
+				continue
+			}
+
+			// The relation can list multiple space separated types
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				links[rel] = url[1 : len(url)-1]
+			}
+		}
+	}
+
+	return links
+}
+
+func (p *LinkHeaderPagination) FirstPage(apiURL string, pageSize int) (string, error) {
+	return setPageSize(apiURL, p.PageSizeParam, pageSize)
+}
+
+func (p *LinkHeaderPagination) NextPage(pageURL string, _ int, resp *PaginatedFetchResponse) (string, error) {
+	if resp.Header == nil || resp.PageEntries == 0 {
+		return "", nil
+	}
+
+	next, ok := parseLinkHeader(resp.Header.Get("Link"))["next"]
+	if !ok {
+		return "", nil
+	}
+
+	return resolveURL(pageURL, next)
+}
+
+// NextURLPagination navigates the pages by following the URL of the next page
+// returned in the response body as NextURL.
+type NextURLPagination struct {
+	// PageSizeParam is the name of the page size param, defaults to
+	// DefaultPageSizeParam.
+	PageSizeParam string
+}
+
+func (p *NextURLPagination) FirstPage(apiURL string, pageSize int) (string, error) {
+	return setPageSize(apiURL, p.PageSizeParam, pageSize)
+}
+
+func (p *NextURLPagination) NextPage(pageURL string, _ int, resp *PaginatedFetchResponse) (string, error) {
+	if resp.NextURL == "" || resp.PageEntries == 0 {
+		return "", nil
+	}
+
+	return resolveURL(pageURL, resp.NextURL)
+}
+
+// setPageSize returns the URL with the page size param set.
+func setPageSize(apiURL string, pageSizeParam string, pageSize int) (string, error) {
+	if pageSizeParam == "" {
+		pageSizeParam = DefaultPageSizeParam
+	}
+
+	return setQueryParams(apiURL, map[string]string{
+		pageSizeParam: strconv.Itoa(pageSize),
+	})
+}
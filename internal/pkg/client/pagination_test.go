@@ -0,0 +1,337 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+const paginationTestURL = "https://example.com/entries?user=steve-rogers"
+
+func requireQuery(t *testing.T, expected url.Values, rawURL string) {
+	parsedURL, err := url.Parse(rawURL)
+	require.Nil(t, err)
+	require.Equal(t, expected, parsedURL.Query())
+}
+
+func TestPageNumberPagination(t *testing.T) {
+	pagination := &client.PageNumberPagination{}
+
+	firstPage, err := pagination.FirstPage(paginationTestURL, 2)
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "page": {"1"}, "per_page": {"2"}}, firstPage)
+
+	nextPage, err := pagination.NextPage(firstPage, 2, &client.PaginatedFetchResponse{PageEntries: 2})
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "page": {"2"}, "per_page": {"2"}}, nextPage)
+}
+
+func TestPageNumberPagination_CustomParams(t *testing.T) {
+	pagination := &client.PageNumberPagination{PageParam: "p", PageSizeParam: "page-size"}
+
+	firstPage, err := pagination.FirstPage(paginationTestURL, 2)
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "p": {"1"}, "page-size": {"2"}}, firstPage)
+
+	nextPage, err := pagination.NextPage(firstPage, 2, &client.PaginatedFetchResponse{PageEntries: 2})
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "p": {"2"}, "page-size": {"2"}}, nextPage)
+}
+
+func TestPageNumberPagination_LastPage(t *testing.T) {
+	pagination := &client.PageNumberPagination{}
+	pageURL := paginationTestURL + "&page=2&per_page=2"
+
+	tests := map[string]*client.PaginatedFetchResponse{
+		"empty page":           {PageEntries: 0},
+		"short page":           {PageEntries: 1},
+		"total reached":        {PageEntries: 2, TotalEntries: 4},
+		"total reached capped": {PageEntries: 1, EntriesPerPage: 1, TotalEntries: 2},
+	}
+
+	for name, resp := range tests {
+		t.Run(name, func(t *testing.T) {
+			nextPage, err := pagination.NextPage(pageURL, 2, resp)
+			require.Nil(t, err)
+			require.Equal(t, "", nextPage)
+		})
+	}
+}
+
+func TestPageNumberPagination_CappedPageSize(t *testing.T) {
+	pagination := &client.PageNumberPagination{}
+	pageURL := paginationTestURL + "&page=1&per_page=100"
+
+	// The API returned fewer entries than requested, but it told the page size
+	nextPage, err := pagination.NextPage(pageURL, 100, &client.PaginatedFetchResponse{
+		PageEntries:    50,
+		EntriesPerPage: 50,
+		TotalEntries:   120,
+	})
+
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "page": {"2"}, "per_page": {"100"}}, nextPage)
+}
+
+func TestOffsetPagination(t *testing.T) {
+	pagination := &client.OffsetPagination{}
+
+	firstPage, err := pagination.FirstPage(paginationTestURL, 2)
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "offset": {"0"}, "limit": {"2"}}, firstPage)
+
+	nextPage, err := pagination.NextPage(firstPage, 2, &client.PaginatedFetchResponse{PageEntries: 2})
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "offset": {"2"}, "limit": {"2"}}, nextPage)
+
+	nextPage, err = pagination.NextPage(nextPage, 2, &client.PaginatedFetchResponse{PageEntries: 2, TotalEntries: 5})
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "offset": {"4"}, "limit": {"2"}}, nextPage)
+}
+
+func TestOffsetPagination_CustomParams(t *testing.T) {
+	pagination := &client.OffsetPagination{OffsetParam: "skip", LimitParam: "take"}
+
+	firstPage, err := pagination.FirstPage(paginationTestURL, 2)
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "skip": {"0"}, "take": {"2"}}, firstPage)
+
+	nextPage, err := pagination.NextPage(firstPage, 2, &client.PaginatedFetchResponse{PageEntries: 2})
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "skip": {"2"}, "take": {"2"}}, nextPage)
+}
+
+func TestOffsetPagination_LastPage(t *testing.T) {
+	pagination := &client.OffsetPagination{}
+	pageURL := paginationTestURL + "&offset=2&limit=2"
+
+	tests := map[string]*client.PaginatedFetchResponse{
+		"empty page":    {PageEntries: 0},
+		"short page":    {PageEntries: 1},
+		"total reached": {PageEntries: 2, TotalEntries: 4},
+	}
+
+	for name, resp := range tests {
+		t.Run(name, func(t *testing.T) {
+			nextPage, err := pagination.NextPage(pageURL, 2, resp)
+			require.Nil(t, err)
+			require.Equal(t, "", nextPage)
+		})
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	pagination := &client.CursorPagination{}
+
+	firstPage, err := pagination.FirstPage(paginationTestURL, 2)
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "per_page": {"2"}}, firstPage)
+
+	nextPage, err := pagination.NextPage(firstPage, 2, &client.PaginatedFetchResponse{PageEntries: 2, NextCursor: "eyJpZCI6MTIzfQ"})
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "per_page": {"2"}, "cursor": {"eyJpZCI6MTIzfQ"}}, nextPage)
+
+	nextPage, err = pagination.NextPage(nextPage, 2, &client.PaginatedFetchResponse{PageEntries: 2, NextCursor: "eyJpZCI6NDU2fQ"})
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "per_page": {"2"}, "cursor": {"eyJpZCI6NDU2fQ"}}, nextPage)
+}
+
+func TestCursorPagination_CustomParams(t *testing.T) {
+	pagination := &client.CursorPagination{CursorParam: "after", PageSizeParam: "limit"}
+
+	firstPage, err := pagination.FirstPage(paginationTestURL, 2)
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "limit": {"2"}}, firstPage)
+
+	nextPage, err := pagination.NextPage(firstPage, 2, &client.PaginatedFetchResponse{PageEntries: 2, NextCursor: "123"})
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "limit": {"2"}, "after": {"123"}}, nextPage)
+}
+
+func TestCursorPagination_LastPage(t *testing.T) {
+	pagination := &client.CursorPagination{}
+
+	nextPage, err := pagination.NextPage(paginationTestURL, 2, &client.PaginatedFetchResponse{PageEntries: 2})
+	require.Nil(t, err)
+	require.Equal(t, "", nextPage)
+
+	nextPage, err = pagination.NextPage(paginationTestURL, 2, &client.PaginatedFetchResponse{NextCursor: "123"})
+	require.Nil(t, err)
+	require.Equal(t, "", nextPage)
+}
+
+func TestLinkHeaderPagination(t *testing.T) {
+	pagination := &client.LinkHeaderPagination{}
+
+	firstPage, err := pagination.FirstPage(paginationTestURL, 2)
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "per_page": {"2"}}, firstPage)
+
+	header := http.Header{}
+	header.Set("Link", `<https://example.com/entries?page=3>; rel="last", <https://example.com/entries?page=2>; rel="next"`)
+
+	nextPage, err := pagination.NextPage(firstPage, 2, &client.PaginatedFetchResponse{PageEntries: 2, Header: header})
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com/entries?page=2", nextPage)
+}
+
+func TestLinkHeaderPagination_RelativeURL(t *testing.T) {
+	pagination := &client.LinkHeaderPagination{}
+
+	header := http.Header{}
+	header.Set("Link", `</entries?page=2>; rel="next prefetch"`)
+
+	nextPage, err := pagination.NextPage(paginationTestURL, 2, &client.PaginatedFetchResponse{PageEntries: 2, Header: header})
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com/entries?page=2", nextPage)
+}
+
+func TestLinkHeaderPagination_LastPage(t *testing.T) {
+	pagination := &client.LinkHeaderPagination{}
+
+	header := http.Header{}
+	header.Set("Link", `<https://example.com/entries?page=1>; rel="first", <https://example.com/entries?page=1>; rel="prev"`)
+
+	tests := map[string]*client.PaginatedFetchResponse{
+		"no header":    {PageEntries: 2},
+		"no next link": {PageEntries: 2, Header: header},
+		"malformed":    {PageEntries: 2, Header: http.Header{"Link": {`https://example.com/entries?page=2; rel="next"`}}},
+	}
+
+	for name, resp := range tests {
+		t.Run(name, func(t *testing.T) {
+			nextPage, err := pagination.NextPage(paginationTestURL, 2, resp)
+			require.Nil(t, err)
+			require.Equal(t, "", nextPage)
+		})
+	}
+}
+
+func TestNextURLPagination(t *testing.T) {
+	pagination := &client.NextURLPagination{PageSizeParam: "page-size"}
+
+	firstPage, err := pagination.FirstPage(paginationTestURL, 2)
+	require.Nil(t, err)
+	requireQuery(t, url.Values{"user": {"steve-rogers"}, "page-size": {"2"}}, firstPage)
+
+	nextPage, err := pagination.NextPage(firstPage, 2, &client.PaginatedFetchResponse{PageEntries: 2, NextURL: "https://example.com/entries?page=2"})
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com/entries?page=2", nextPage)
+
+	nextPage, err = pagination.NextPage(firstPage, 2, &client.PaginatedFetchResponse{PageEntries: 2, NextURL: "/entries?page=3"})
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com/entries?page=3", nextPage)
+}
+
+func TestNextURLPagination_LastPage(t *testing.T) {
+	pagination := &client.NextURLPagination{}
+
+	nextPage, err := pagination.NextPage(paginationTestURL, 2, &client.PaginatedFetchResponse{PageEntries: 2})
+	require.Nil(t, err)
+	require.Equal(t, "", nextPage)
+}
+
+func TestHTTPClient_PaginatedFetch_LinkHeader(t *testing.T) {
+	var requestedPages []string
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		requestedPages = append(requestedPages, page)
+
+		var ids []string
+		switch page {
+		case "":
+			ids = []string{"1", "2"}
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+		case "2":
+			ids = []string{"3"}
+		}
+
+		require.Nil(t, json.NewEncoder(w).Encode(ids))
+	}))
+	defer mockServer.Close()
+
+	baseURL, err := url.Parse(mockServer.URL)
+	require.Nil(t, err)
+
+	httpClient := &client.HTTPClient{
+		BaseURL: baseURL,
+	}
+
+	entries, err := httpClient.PaginatedFetch(context.Background(), &client.PaginatedFetchOpts{
+		BaseFetchOpts: &client.FetchOpts{},
+		URL:           "/entries",
+		Pagination:    &client.LinkHeaderPagination{},
+		FetchFunc: func(ctx context.Context, reqURL string) (interface{}, *client.PaginatedFetchResponse, error) {
+			resp, header, err := httpClient.CallWithHeader(ctx, &client.HTTPRequestOpts{
+				Method:  http.MethodGet,
+				Url:     reqURL,
+				Timeout: client.DefaultRequestTimeout,
+			})
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			var ids []string
+			if err = json.Unmarshal(resp, &ids); err != nil {
+				return nil, nil, err
+			}
+
+			return ids, &client.PaginatedFetchResponse{PageEntries: len(ids), Header: header}, nil
+		},
+		ParseFunc: func(rawEntries interface{}, _ *client.FetchOpts) (worklog.Entries, error) {
+			var entries worklog.Entries
+
+			for _, id := range rawEntries.([]string) {
+				entries = append(entries, worklog.Entry{SourceID: id})
+			}
+
+			return entries, nil
+		},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, worklog.Entries{{SourceID: "1"}, {SourceID: "2"}, {SourceID: "3"}}, entries)
+	require.Equal(t, []string{"", "2"}, requestedPages)
+}
+
+func TestHTTPClient_PaginatedFetch_Offset(t *testing.T) {
+	api := &mockPaginatedAPI{totalEntries: 5, reportTotal: true}
+	httpClient := getPaginatedTestClient(t)
+
+	var offsets []string
+
+	entries, err := httpClient.PaginatedFetch(context.Background(), &client.PaginatedFetchOpts{
+		BaseFetchOpts: &client.FetchOpts{},
+		URL:           "/entries",
+		PageSize:      2,
+		Pagination:    &client.OffsetPagination{},
+		FetchFunc: func(ctx context.Context, reqURL string) (interface{}, *client.PaginatedFetchResponse, error) {
+			parsedURL, err := url.Parse(reqURL)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// Translate the offset to the page number of the mocked API
+			offset, _ := strconv.Atoi(parsedURL.Query().Get("offset"))
+			offsets = append(offsets, parsedURL.Query().Get("offset"))
+
+			return api.fetch(ctx, fmt.Sprintf("/entries?page=%d&per_page=2", offset/2+1))
+		},
+		ParseFunc: api.parse,
+	})
+
+	require.Nil(t, err)
+	require.Len(t, entries, 5)
+	require.Equal(t, []string{"0", "2", "4"}, offsets)
+}
@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// DefaultFetchConcurrency sets the maximum number of windows fetched at the
+	// same time by the planned fetcher.
+	DefaultFetchConcurrency int = 4
+)
+
+// FetchWindow represents a part of the fetched date range, including the Start
+// and excluding the End of the window.
+type FetchWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// PlanFetchWindows splits the date range into windows having the given length.
+// The last window is shorter if the date range cannot be split evenly. If the
+// window length is not positive, or it is longer than the date range, the
+// whole date range returns as one window.
+func PlanFetchWindows(start time.Time, end time.Time, window time.Duration) []FetchWindow {
+	if window <= 0 || !start.Add(window).Before(end) {
+		return []FetchWindow{{Start: start, End: end}}
+	}
+
+	var windows []FetchWindow
+
+	for windowStart := start; windowStart.Before(end); windowStart = windowStart.Add(window) {
+		windowEnd := windowStart.Add(window)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+
+		windows = append(windows, FetchWindow{Start: windowStart, End: windowEnd})
+	}
+
+	return windows
+}
+
+// PlannedFetcherOpts specifies how the planned fetcher splits the fetching.
+type PlannedFetcherOpts struct {
+	// Window sets the length of the date range fetched at once. If the window
+	// is not positive, the whole date range is fetched at once.
+	Window time.Duration
+	// Concurrency sets the maximum number of windows fetched at the same time.
+	// If not positive, the DefaultFetchConcurrency is used.
+	Concurrency int
+}
+
+type plannedFetcher struct {
+	fetcher     Fetcher
+	window      time.Duration
+	concurrency int
+}
+
+func (f *plannedFetcher) FetchEntries(ctx context.Context, opts *FetchOpts) (worklog.Entries, error) {
+	windows := PlanFetchWindows(opts.Start, opts.End, f.window)
+	if len(windows) == 1 {
+		return f.fetcher.FetchEntries(ctx, opts)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var fetchErr error
+
+	results := make([]worklog.Entries, len(windows))
+	semaphore := make(chan struct{}, f.concurrency)
+
+	for i, window := range windows {
+		wg.Add(1)
+
+		go func(i int, window FetchWindow) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			// Another window failed already, no need to fetch this one
+			if ctx.Err() != nil {
+				return
+			}
+
+			windowOpts := *opts
+			windowOpts.Start = window.Start
+			windowOpts.End = window.End
+
+			entries, err := f.fetcher.FetchEntries(ctx, &windowOpts)
+			if err != nil {
+				errOnce.Do(func() {
+					fetchErr = err
+					cancel()
+				})
+
+				return
+			}
+
+			results[i] = entries
+		}(i, window)
+	}
+
+	wg.Wait()
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	var entries worklog.Entries
+	for _, windowEntries := range results {
+		entries = append(entries, windowEntries...)
+	}
+
+	// The APIs may return the entries on the window edges for both windows,
+	// hence the duplicates must be removed
+	return entries.Deduplicate(), nil
+}
+
+// plannedStreamFetcher is the planned fetcher of a StreamFetcher, which
+// forwards the pages of the windows as they are fetched.
+type plannedStreamFetcher struct {
+	*plannedFetcher
+	streamFetcher StreamFetcher
+}
+
+// FetchEntriesStream fetches the windows concurrently and forwards their pages
+// in the order they are received. The pages are numbered across the windows,
+// and the entries already sent for another window are removed from them.
+func (f *plannedStreamFetcher) FetchEntriesStream(ctx context.Context, opts *FetchOpts) <-chan FetchPage {
+	windows := PlanFetchWindows(opts.Start, opts.End, f.window)
+	if len(windows) == 1 {
+		return f.streamFetcher.FetchEntriesStream(ctx, opts)
+	}
+
+	pages := make(chan FetchPage)
+
+	go func() {
+		defer close(pages)
+
+		windowCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var wg sync.WaitGroup
+
+		windowPages := make(chan FetchPage)
+		semaphore := make(chan struct{}, f.concurrency)
+
+		for _, window := range windows {
+			wg.Add(1)
+
+			go func(window FetchWindow) {
+				defer wg.Done()
+
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+
+				// Another window failed already, no need to fetch this one
+				if windowCtx.Err() != nil {
+					return
+				}
+
+				windowOpts := *opts
+				windowOpts.Start = window.Start
+				windowOpts.End = window.End
+
+				// The stream is received until it is closed, even if the pages
+				// are not forwarded anymore, so the fetching can stop cleanly
+				for page := range f.streamFetcher.FetchEntriesStream(windowCtx, &windowOpts) {
+					select {
+					case windowPages <- page:
+					case <-windowCtx.Done():
+					}
+				}
+			}(window)
+		}
+
+		go func() {
+			wg.Wait()
+			close(windowPages)
+		}()
+
+		// Drain the pages of the windows when returning early, so the windows
+		// are not blocked on sending them
+		defer func() {
+			for range windowPages {
+			}
+		}()
+
+		var entries worklog.Entries
+		pageNumber := 0
+
+		for page := range windowPages {
+			if page.Err != nil {
+				cancel()
+
+				select {
+				case pages <- FetchPage{Page: pageNumber + 1, Err: page.Err}:
+				case <-ctx.Done():
+				}
+
+				return
+			}
+
+			// The APIs may return the entries on the window edges for both
+			// windows, hence the entries sent already must be removed
+			uniqueEntries := append(entries[:len(entries):len(entries)], page.Entries...)
+			uniqueEntries = uniqueEntries.Deduplicate()
+			pageNumber++
+
+			select {
+			case pages <- FetchPage{Page: pageNumber, Entries: uniqueEntries[len(entries):]}:
+			case <-ctx.Done():
+				return
+			}
+
+			entries = uniqueEntries
+		}
+	}()
+
+	return pages
+}
+
+// NewPlannedFetcher wraps the fetcher to split the fetched date range into
+// windows and fetch the windows concurrently. The entries of the windows are
+// de-duplicated before returning them. If the fetcher is a StreamFetcher, the
+// returned fetcher is a StreamFetcher too, forwarding the pages of the windows.
+func NewPlannedFetcher(fetcher Fetcher, opts *PlannedFetcherOpts) Fetcher {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultFetchConcurrency
+	}
+
+	planned := &plannedFetcher{
+		fetcher:     fetcher,
+		window:      opts.Window,
+		concurrency: concurrency,
+	}
+
+	if streamFetcher, ok := fetcher.(StreamFetcher); ok {
+		return &plannedStreamFetcher{
+			plannedFetcher: planned,
+			streamFetcher:  streamFetcher,
+		}
+	}
+
+	return planned
+}
@@ -0,0 +1,275 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+type mockFetcher struct {
+	mu        sync.Mutex
+	windows   []client.FetchWindow
+	inFlight  int
+	maxFlight int
+	entries   func(opts *client.FetchOpts) (worklog.Entries, error)
+}
+
+func (f *mockFetcher) FetchEntries(_ context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	f.mu.Lock()
+	f.windows = append(f.windows, client.FetchWindow{Start: opts.Start, End: opts.End})
+	f.inFlight++
+	if f.inFlight > f.maxFlight {
+		f.maxFlight = f.inFlight
+	}
+	f.mu.Unlock()
+
+	// Give a chance to the other windows to run concurrently
+	time.Sleep(time.Millisecond * 10)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+
+	return f.entries(opts)
+}
+
+func getPlannerTestEntry(id string, start time.Time) worklog.Entry {
+	return worklog.Entry{
+		Task: worklog.IDNameField{
+			ID:   "task-id",
+			Name: "TASK-0123",
+		},
+		Summary:          "Write worklog transfer CLI tool",
+		Start:            start,
+		BillableDuration: time.Hour,
+		Source:           "mock",
+		SourceID:         id,
+	}
+}
+
+func TestPlanFetchWindows(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 18, 0, 0, 0, 0, time.UTC)
+	week := time.Hour * 24 * 7
+
+	require.Equal(t, []client.FetchWindow{
+		{Start: start, End: start.Add(week)},
+		{Start: start.Add(week), End: start.Add(week * 2)},
+		{Start: start.Add(week * 2), End: end},
+	}, client.PlanFetchWindows(start, end, week))
+}
+
+func TestPlanFetchWindows_SingleWindow(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 8, 0, 0, 0, 0, time.UTC)
+	expectedWindows := []client.FetchWindow{{Start: start, End: end}}
+
+	require.Equal(t, expectedWindows, client.PlanFetchWindows(start, end, 0))
+	require.Equal(t, expectedWindows, client.PlanFetchWindows(start, end, time.Hour*24*7))
+	require.Equal(t, expectedWindows, client.PlanFetchWindows(start, end, time.Hour*24*30))
+}
+
+func TestPlannedFetcher_FetchEntries(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 5, 0, 0, 0, 0, time.UTC)
+	day := time.Hour * 24
+
+	fetcher := &mockFetcher{
+		entries: func(opts *client.FetchOpts) (worklog.Entries, error) {
+			// Every window returns the first entry of the next window too, as
+			// date based APIs are including the end date
+			return worklog.Entries{
+				getPlannerTestEntry(opts.Start.Format("2006-01-02"), opts.Start),
+				getPlannerTestEntry(opts.End.Format("2006-01-02"), opts.End),
+			}, nil
+		},
+	}
+
+	plannedFetcher := client.NewPlannedFetcher(fetcher, &client.PlannedFetcherOpts{
+		Window:      day,
+		Concurrency: 2,
+	})
+
+	entries, err := plannedFetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		User:  "steve-rogers",
+		Start: start,
+		End:   end,
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, worklog.Entries{
+		getPlannerTestEntry("2021-10-01", start),
+		getPlannerTestEntry("2021-10-02", start.Add(day)),
+		getPlannerTestEntry("2021-10-03", start.Add(day*2)),
+		getPlannerTestEntry("2021-10-04", start.Add(day*3)),
+		getPlannerTestEntry("2021-10-05", end),
+	}, entries)
+
+	require.ElementsMatch(t, client.PlanFetchWindows(start, end, day), fetcher.windows)
+	require.LessOrEqual(t, fetcher.maxFlight, 2)
+}
+
+func TestPlannedFetcher_FetchEntries_SingleWindow(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
+
+	fetcher := &mockFetcher{
+		entries: func(opts *client.FetchOpts) (worklog.Entries, error) {
+			return worklog.Entries{getPlannerTestEntry("1", opts.Start)}, nil
+		},
+	}
+
+	plannedFetcher := client.NewPlannedFetcher(fetcher, &client.PlannedFetcherOpts{})
+
+	entries, err := plannedFetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: start,
+		End:   end,
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, worklog.Entries{getPlannerTestEntry("1", start)}, entries)
+	require.Equal(t, []client.FetchWindow{{Start: start, End: end}}, fetcher.windows)
+}
+
+func TestPlannedFetcher_FetchEntries_Error(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 5, 0, 0, 0, 0, time.UTC)
+	fetchErr := errors.New("failed to fetch entries: 500: internal server error")
+
+	fetcher := &mockFetcher{
+		entries: func(opts *client.FetchOpts) (worklog.Entries, error) {
+			if opts.Start.Day() == 2 {
+				return nil, fetchErr
+			}
+
+			return worklog.Entries{getPlannerTestEntry("1", opts.Start)}, nil
+		},
+	}
+
+	plannedFetcher := client.NewPlannedFetcher(fetcher, &client.PlannedFetcherOpts{
+		Window:      time.Hour * 24,
+		Concurrency: 1,
+	})
+
+	entries, err := plannedFetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: start,
+		End:   end,
+	})
+
+	require.Nil(t, entries)
+	require.ErrorIs(t, err, fetchErr)
+}
+
+type mockStreamFetcher struct {
+	mockFetcher
+}
+
+func (f *mockStreamFetcher) FetchEntriesStream(ctx context.Context, opts *client.FetchOpts) <-chan client.FetchPage {
+	entries, err := f.FetchEntries(ctx, opts)
+	if err != nil {
+		return client.FailedFetchStream(err)
+	}
+
+	// Every entry is sent on its own page
+	pages := make(chan client.FetchPage, len(entries))
+	for i, entry := range entries {
+		pages <- client.FetchPage{Page: i + 1, TotalPages: len(entries), Entries: worklog.Entries{entry}}
+	}
+	close(pages)
+
+	return pages
+}
+
+func TestNewPlannedFetcher_StreamFetcher(t *testing.T) {
+	plannedFetcher := client.NewPlannedFetcher(&mockFetcher{}, &client.PlannedFetcherOpts{})
+	_, ok := plannedFetcher.(client.StreamFetcher)
+	require.False(t, ok)
+
+	plannedFetcher = client.NewPlannedFetcher(&mockStreamFetcher{}, &client.PlannedFetcherOpts{})
+	_, ok = plannedFetcher.(client.StreamFetcher)
+	require.True(t, ok)
+}
+
+func TestPlannedFetcher_FetchEntriesStream(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 5, 0, 0, 0, 0, time.UTC)
+	day := time.Hour * 24
+
+	fetcher := &mockStreamFetcher{
+		mockFetcher: mockFetcher{
+			entries: func(opts *client.FetchOpts) (worklog.Entries, error) {
+				return worklog.Entries{
+					getPlannerTestEntry(opts.Start.Format("2006-01-02"), opts.Start),
+					getPlannerTestEntry(opts.End.Format("2006-01-02"), opts.End),
+				}, nil
+			},
+		},
+	}
+
+	plannedFetcher := client.NewPlannedFetcher(fetcher, &client.PlannedFetcherOpts{
+		Window:      day,
+		Concurrency: 2,
+	}).(client.StreamFetcher)
+
+	var pageNumbers []int
+	var entries worklog.Entries
+
+	for page := range plannedFetcher.FetchEntriesStream(context.Background(), &client.FetchOpts{
+		Start: start,
+		End:   end,
+	}) {
+		require.Nil(t, page.Err)
+		pageNumbers = append(pageNumbers, page.Page)
+		entries = append(entries, page.Entries...)
+	}
+
+	// The pages of the windows are forwarded, without the duplicated entries
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, pageNumbers)
+	require.ElementsMatch(t, worklog.Entries{
+		getPlannerTestEntry("2021-10-01", start),
+		getPlannerTestEntry("2021-10-02", start.Add(day)),
+		getPlannerTestEntry("2021-10-03", start.Add(day*2)),
+		getPlannerTestEntry("2021-10-04", start.Add(day*3)),
+		getPlannerTestEntry("2021-10-05", end),
+	}, entries)
+
+	require.ElementsMatch(t, client.PlanFetchWindows(start, end, day), fetcher.windows)
+	require.LessOrEqual(t, fetcher.maxFlight, 2)
+}
+
+func TestPlannedFetcher_FetchEntriesStream_Error(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 5, 0, 0, 0, 0, time.UTC)
+	fetchErr := errors.New("failed to fetch entries: 500: internal server error")
+
+	fetcher := &mockStreamFetcher{
+		mockFetcher: mockFetcher{
+			entries: func(opts *client.FetchOpts) (worklog.Entries, error) {
+				if opts.Start.Day() == 2 {
+					return nil, fetchErr
+				}
+
+				return worklog.Entries{getPlannerTestEntry("1", opts.Start)}, nil
+			},
+		},
+	}
+
+	plannedFetcher := client.NewPlannedFetcher(fetcher, &client.PlannedFetcherOpts{
+		Window:      time.Hour * 24,
+		Concurrency: 1,
+	}).(client.StreamFetcher)
+
+	entries, err := client.CollectFetchPages(context.Background(), plannedFetcher.FetchEntriesStream(context.Background(), &client.FetchOpts{
+		Start: start,
+		End:   end,
+	}))
+
+	require.Nil(t, entries)
+	require.ErrorIs(t, err, fetchErr)
+}
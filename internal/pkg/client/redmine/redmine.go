@@ -0,0 +1,493 @@
+package redmine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "redmine"
+	// PathTimeEntries is the API endpoint used to search and create time
+	// entries.
+	PathTimeEntries string = "/time_entries.json"
+	// PathProjects is the API endpoint used to list the projects.
+	PathProjects string = "/projects.json"
+	// HeaderAPIKey is the header of the API key.
+	HeaderAPIKey string = "X-Redmine-API-Key"
+	// DefaultDayStart is the default offset from midnight when the first entry
+	// of a day starts.
+	DefaultDayStart time.Duration = 9 * time.Hour
+	// MaxPageSize is the maximum number of entries returned by Redmine.
+	MaxPageSize int = 100
+)
+
+var (
+	// ErrProjectNotFound returns when no project found for an entry.
+	ErrProjectNotFound = errors.New("project not found")
+
+	issueRegex = regexp.MustCompile(`^#?(\d+)$`)
+)
+
+// IDNameField represents a resource referenced by a time entry. Issues have no
+// name in the time entries.
+type IDNameField struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// TimeEntry represents the time entry fetched from Redmine. Time entries have
+// no start time, only the day the time was spent on.
+type TimeEntry struct {
+	ID       int          `json:"id"`
+	Project  IDNameField  `json:"project"`
+	Issue    *IDNameField `json:"issue"`
+	User     IDNameField  `json:"user"`
+	Activity IDNameField  `json:"activity"`
+	Hours    float64      `json:"hours"`
+	Comments string       `json:"comments"`
+	SpentOn  string       `json:"spent_on"`
+}
+
+// FetchResponse represents the paginated response of the time entry search.
+type FetchResponse struct {
+	TimeEntries []TimeEntry `json:"time_entries"`
+	TotalCount  int         `json:"total_count"`
+	Offset      int         `json:"offset"`
+	Limit       int         `json:"limit"`
+}
+
+// UploadEntry represents the time entry created in Redmine. Either the issue
+// or the project must be set. If the activity is not set, the default activity
+// of Redmine is used.
+type UploadEntry struct {
+	IssueID    int     `json:"issue_id,omitempty"`
+	ProjectID  int     `json:"project_id,omitempty"`
+	SpentOn    string  `json:"spent_on"`
+	Hours      float64 `json:"hours"`
+	ActivityID int     `json:"activity_id,omitempty"`
+	Comments   string  `json:"comments,omitempty"`
+	UserID     int     `json:"user_id,omitempty"`
+}
+
+// UploadRequest represents the payload to create a new time entry.
+type UploadRequest struct {
+	TimeEntry UploadEntry `json:"time_entry"`
+}
+
+// Project represents a project returned by the project list.
+type Project struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Identifier string `json:"identifier"`
+}
+
+// ProjectsResponse represents the paginated response of the project list.
+type ProjectsResponse struct {
+	Projects   []Project `json:"projects"`
+	TotalCount int       `json:"total_count"`
+}
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// Since Redmine has no clients, the Client is set on every fetched entry,
+// defaulting to the project of the entry. Since time entries have no start
+// time, the entries of a day are placed one after the other from DayStart. The
+// Activities map the names of the tags and tasks to activity IDs, used when
+// uploading, and the DefaultActivity is used if no activity matches. The
+// activity names are case-insensitive.
+type ClientOpts struct {
+	client.BaseClientOpts
+	client.TokenAuth
+	BaseURL         string
+	Client          string
+	DayStart        time.Duration
+	Activities      map[string]int
+	DefaultActivity int
+}
+
+type redmineClient struct {
+	*client.BaseClientOpts
+	*client.HTTPClient
+	*client.DefaultUploader
+	authenticator   client.Authenticator
+	clientName      string
+	dayStart        time.Duration
+	activities      map[string]int
+	defaultActivity int
+
+	// The projects are listed once, when the first entry without issue is
+	// uploaded
+	projectsOnce sync.Once
+	projectsErr  error
+	projects     []Project
+}
+
+func (c *redmineClient) parseEntries(rawEntries interface{}, opts *client.FetchOpts) (worklog.Entries, error) {
+	var entries worklog.Entries
+
+	fetchedEntries, ok := rawEntries.([]TimeEntry)
+	if !ok {
+		return nil, fmt.Errorf("%v: %s", client.ErrFetchEntries, "cannot parse returned entries")
+	}
+
+	startYear, startMonth, startDay := opts.Start.In(c.Location()).Date()
+	firstDay := time.Date(startYear, startMonth, startDay, 0, 0, 0, 0, c.Location())
+
+	for _, entry := range fetchedEntries {
+		spentOn, err := utils.DateFormatISO8601.ParseInLocation(entry.SpentOn, c.Location())
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+
+		// The date range is checked by day, so the entries of the last day
+		// are returned too
+		if spentOn.Before(firstDay) || !spentOn.Before(opts.End) {
+			continue
+		}
+
+		activity := worklog.IDNameField{
+			ID:   strconv.Itoa(entry.Activity.ID),
+			Name: entry.Activity.Name,
+		}
+
+		project := worklog.IDNameField{
+			ID:   strconv.Itoa(entry.Project.ID),
+			Name: entry.Project.Name,
+		}
+
+		// Redmine has no clients, so the project is the client unless set
+		worklogClient := project
+		if c.clientName != "" {
+			worklogClient = worklog.IDNameField{ID: c.clientName, Name: c.clientName}
+		}
+
+		worklogEntry := worklog.Entry{
+			Client:  worklogClient,
+			Project: project,
+			// Time entries logged on the project have no issue, so their task
+			// is the activity
+			Task:    activity,
+			Summary: entry.Comments,
+			Notes:   entry.Comments,
+			Tags:    []worklog.IDNameField{activity},
+			// The time of the day is set when the entries of the day are known
+			Start:              spentOn,
+			BillableDuration:   time.Duration(entry.Hours * float64(time.Hour)).Round(time.Second),
+			UnbillableDuration: 0,
+			Source:             SourceName,
+			SourceID:           strconv.Itoa(entry.ID),
+		}
+
+		if entry.Issue != nil {
+			worklogEntry.Task = worklog.IDNameField{
+				ID:   strconv.Itoa(entry.Issue.ID),
+				Name: "#" + strconv.Itoa(entry.Issue.ID),
+			}
+		}
+
+		// Time entries without comments are summarized by their activity
+		if worklogEntry.Summary == "" {
+			worklogEntry.Summary = entry.Activity.Name
+		}
+
+		if utils.IsRegexSet(opts.TagsAsTasksRegex) && opts.TagsAsTasksRegex.MatchString(activity.Name) {
+			entries = append(entries, worklogEntry.SplitByTagsAsTasks(worklogEntry.Summary, opts.TagsAsTasksRegex, worklogEntry.Tags)...)
+		} else {
+			entries = append(entries, worklogEntry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (c *redmineClient) fetchEntries(ctx context.Context, reqURL string) (interface{}, *client.PaginatedFetchResponse, error) {
+	resp, err := c.Call(ctx, &client.HTTPRequestOpts{
+		Method:  http.MethodGet,
+		Url:     reqURL,
+		Auth:    c.authenticator,
+		Timeout: c.Timeout,
+	})
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	var fetchResponse FetchResponse
+	if err = json.Unmarshal(resp, &fetchResponse); err != nil {
+		return nil, nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	paginatedResponse := &client.PaginatedFetchResponse{
+		PageEntries:    len(fetchResponse.TimeEntries),
+		EntriesPerPage: fetchResponse.Limit,
+		TotalEntries:   fetchResponse.TotalCount,
+	}
+
+	return fetchResponse.TimeEntries, paginatedResponse, err
+}
+
+// scheduleEntries sets the start and end of the entries. The entries of a day
+// are placed one after the other from the start of the day, in the order they
+// were created.
+func (c *redmineClient) scheduleEntries(entries worklog.Entries) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Start.Equal(entries[j].Start) {
+			return entries[i].Start.Before(entries[j].Start)
+		}
+
+		// The time entry IDs are increasing, so they are ordered by creation
+		iID, _ := strconv.Atoi(entries[i].SourceID)
+		jID, _ := strconv.Atoi(entries[j].SourceID)
+		return iID < jID
+	})
+
+	var day time.Time
+	var next time.Time
+
+	for i := range entries {
+		if !entries[i].Start.Equal(day) {
+			day = entries[i].Start
+			next = day.Add(c.dayStart)
+		}
+
+		entries[i].Start = next
+		entries[i].End = next.Add(entries[i].BillableDuration + entries[i].UnbillableDuration)
+		next = entries[i].End
+	}
+}
+
+// FetchEntries fetches the time entries of the date range. The entries are not
+// streamed page by page, since the time of the entries can be set only when
+// every entry of their day is fetched.
+func (c *redmineClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	// Redmine includes the last day of the range
+	params := map[string]string{
+		"from": utils.DateFormatISO8601.FormatInLocation(opts.Start, c.Location()),
+		"to":   utils.DateFormatISO8601.FormatInLocation(opts.End.Add(-time.Nanosecond), c.Location()),
+	}
+
+	if opts.User != "" {
+		params["user_id"] = opts.User
+	}
+
+	fetchURL, err := c.URL(PathTimeEntries, params)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	entries, err := c.PaginatedFetch(ctx, &client.PaginatedFetchOpts{
+		BaseFetchOpts: opts,
+		URL:           fetchURL,
+		PageSize:      MaxPageSize,
+		Pagination:    &client.OffsetPagination{},
+		FetchFunc:     c.fetchEntries,
+		ParseFunc:     c.parseEntries,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.scheduleEntries(entries)
+	return entries, nil
+}
+
+// loadProjects lists the projects page by page once, so the entries can be
+// uploaded concurrently.
+func (c *redmineClient) loadProjects(ctx context.Context) error {
+	c.projectsOnce.Do(func() {
+		for offset := 0; ; offset += MaxPageSize {
+			listURL, err := c.URL(PathProjects, map[string]string{
+				client.DefaultOffsetParam: strconv.Itoa(offset),
+				client.DefaultLimitParam:  strconv.Itoa(MaxPageSize),
+			})
+
+			if err != nil {
+				c.projectsErr = err
+				return
+			}
+
+			resp, err := c.Call(ctx, &client.HTTPRequestOpts{
+				Method:  http.MethodGet,
+				Url:     listURL,
+				Auth:    c.authenticator,
+				Timeout: c.Timeout,
+			})
+
+			if err != nil {
+				c.projectsErr = err
+				return
+			}
+
+			var projectsResponse ProjectsResponse
+			if c.projectsErr = json.Unmarshal(resp, &projectsResponse); c.projectsErr != nil {
+				return
+			}
+
+			c.projects = append(c.projects, projectsResponse.Projects...)
+
+			if len(projectsResponse.Projects) == 0 || len(c.projects) >= projectsResponse.TotalCount {
+				return
+			}
+		}
+	})
+
+	return c.projectsErr
+}
+
+// findProject returns the ID of the project having the name or identifier of
+// the entry's project.
+func (c *redmineClient) findProject(ctx context.Context, entry worklog.Entry) (int, error) {
+	if err := c.loadProjects(ctx); err != nil {
+		return 0, err
+	}
+
+	for _, project := range c.projects {
+		if project.Name == entry.Project.Name || project.Identifier == entry.Project.Name {
+			return project.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%v: %s", ErrProjectNotFound, entry.Project.Name)
+}
+
+// findActivity returns the ID of the activity matching the name of a tag or the
+// task of the entry. If no activity matches, the default activity returns.
+func (c *redmineClient) findActivity(entry worklog.Entry) int {
+	for _, tag := range entry.Tags {
+		if activityID, ok := c.activities[strings.ToLower(tag.Name)]; ok {
+			return activityID
+		}
+	}
+
+	if activityID, ok := c.activities[strings.ToLower(entry.Task.Name)]; ok {
+		return activityID
+	}
+
+	return c.defaultActivity
+}
+
+// uploadEntry returns the time entry created for the entry. If the task of the
+// entry is an issue, like "#123", the time is logged on the issue, otherwise
+// on the project.
+func (c *redmineClient) uploadEntry(ctx context.Context, entry worklog.Entry, opts *client.UploadOpts) (*UploadEntry, error) {
+	comment, err := c.RenderComment(entry, opts.CommentTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	billableDuration, unbillableDuration := c.Durations(entry, opts)
+
+	uploadEntry := &UploadEntry{
+		SpentOn:    utils.DateFormatISO8601.FormatInLocation(entry.Start, c.Location()),
+		Hours:      (billableDuration + unbillableDuration).Hours(),
+		ActivityID: c.findActivity(entry),
+		Comments:   comment,
+	}
+
+	if matches := issueRegex.FindStringSubmatch(entry.Task.Name); matches != nil {
+		uploadEntry.IssueID, _ = strconv.Atoi(matches[1])
+	} else if uploadEntry.ProjectID, err = c.findProject(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	if opts.User != "" {
+		if uploadEntry.UserID, err = strconv.Atoi(opts.User); err != nil {
+			return nil, err
+		}
+	}
+
+	return uploadEntry, nil
+}
+
+func (c *redmineClient) UploadEntries(ctx context.Context, entries worklog.Entries, errChan chan error, opts *client.UploadOpts) {
+	createURL, err := c.URL(PathTimeEntries, map[string]string{})
+	if err != nil {
+		errChan <- fmt.Errorf("%v: %v", client.ErrUploadEntries, err)
+		return
+	}
+
+	for _, groupEntries := range entries.GroupByTask() {
+		go func(ctx context.Context, entries worklog.Entries, errChan chan error, opts *client.UploadOpts) {
+			for _, entry := range entries {
+				uploadEntry, err := c.uploadEntry(ctx, entry, opts)
+				if err != nil {
+					errChan <- fmt.Errorf("%v: %v", client.ErrUploadEntries, err)
+					continue
+				}
+
+				tracker := c.StartTracking(entry, opts.ProgressWriter)
+
+				_, err = c.Call(ctx, &client.HTTPRequestOpts{
+					Method:  http.MethodPost,
+					Url:     createURL,
+					Auth:    c.authenticator,
+					Timeout: c.Timeout,
+					Data:    &UploadRequest{TimeEntry: *uploadEntry},
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+				})
+
+				if err != nil {
+					err = fmt.Errorf("%v: %+v: %v", client.ErrUploadEntries, uploadEntry, err)
+				}
+
+				c.StopTracking(tracker, err)
+				errChan <- err
+			}
+		}(ctx, groupEntries, errChan, opts)
+	}
+}
+
+func newClient(opts *ClientOpts) (*redmineClient, error) {
+	baseURL, err := url.Parse(opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	authenticator, err := client.NewTokenAuth(HeaderAPIKey, "", opts.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Viper lowercases the keys of maps read from the config file
+	activities := make(map[string]int, len(opts.Activities))
+	for name, activityID := range opts.Activities {
+		activities[strings.ToLower(name)] = activityID
+	}
+
+	return &redmineClient{
+		authenticator:   authenticator,
+		HTTPClient:      &client.HTTPClient{BaseURL: baseURL},
+		BaseClientOpts:  &opts.BaseClientOpts,
+		clientName:      opts.Client,
+		dayStart:        opts.DayStart,
+		activities:      activities,
+		defaultActivity: opts.DefaultActivity,
+	}, nil
+}
+
+// NewFetcher returns a new Redmine client for fetching entries.
+func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
+	return newClient(opts)
+}
+
+// NewUploader returns a new Redmine client for uploading entries.
+func NewUploader(opts *ClientOpts) (client.Uploader, error) {
+	return newClient(opts)
+}
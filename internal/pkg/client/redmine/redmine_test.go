@@ -0,0 +1,321 @@
+package redmine_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/redmine"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func newClientOpts(baseURL string) *redmine.ClientOpts {
+	return &redmine.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
+		},
+		TokenAuth: client.TokenAuth{
+			Token: "t-o-k-e-n",
+		},
+		BaseURL:  baseURL,
+		DayStart: redmine.DefaultDayStart,
+		Activities: map[string]int{
+			"meeting":  8,
+			"Training": 9,
+		},
+		DefaultActivity: 10,
+	}
+}
+
+func TestRedmineClient_FetchEntries(t *testing.T) {
+	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 4, 0, 0, 0, 0, time.UTC)
+
+	project := redmine.IDNameField{ID: 456, Name: "MARVEL"}
+	var queries []url.Values
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, redmine.PathTimeEntries, r.URL.Path)
+		require.Equal(t, "t-o-k-e-n", r.Header.Get(redmine.HeaderAPIKey))
+
+		queries = append(queries, r.URL.Query())
+
+		// Redmine returns the latest entries first
+		err := json.NewEncoder(w).Encode(&redmine.FetchResponse{
+			TimeEntries: []redmine.TimeEntry{
+				{
+					ID:       3,
+					Project:  project,
+					Activity: redmine.IDNameField{ID: 9, Name: "Training"},
+					Hours:    0.5,
+					Comments: "Train with Natasha",
+					SpentOn:  "2021-10-03",
+				},
+				{
+					ID:       2,
+					Project:  project,
+					Issue:    &redmine.IDNameField{ID: 124},
+					Activity: redmine.IDNameField{ID: 9, Name: "Training"},
+					Hours:    0.25,
+					SpentOn:  "2021-10-02",
+				},
+				{
+					ID:       1,
+					Project:  project,
+					Issue:    &redmine.IDNameField{ID: 123},
+					Activity: redmine.IDNameField{ID: 8, Name: "Meeting"},
+					Hours:    1.5,
+					Comments: "Meet with The Winter Soldier",
+					SpentOn:  "2021-10-02",
+				},
+			},
+			TotalCount: 3,
+			Limit:      redmine.MaxPageSize,
+		})
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	redmineClient, err := redmine.NewFetcher(newClientOpts(mockServer.URL))
+	require.Nil(t, err)
+
+	entries, err := redmineClient.FetchEntries(context.Background(), &client.FetchOpts{
+		User:  "1",
+		Start: start,
+		End:   end,
+	})
+	require.Nil(t, err, "cannot fetch entries")
+
+	marvel := worklog.IDNameField{ID: "456", Name: "MARVEL"}
+	meeting := worklog.IDNameField{ID: "8", Name: "Meeting"}
+	training := worklog.IDNameField{ID: "9", Name: "Training"}
+
+	require.Equal(t, worklog.Entries{
+		{
+			Client:           marvel,
+			Project:          marvel,
+			Task:             worklog.IDNameField{ID: "123", Name: "#123"},
+			Summary:          "Meet with The Winter Soldier",
+			Notes:            "Meet with The Winter Soldier",
+			Tags:             []worklog.IDNameField{meeting},
+			Start:            time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 2, 10, 30, 0, 0, time.UTC),
+			BillableDuration: 90 * time.Minute,
+			Source:           redmine.SourceName,
+			SourceID:         "1",
+		},
+		{
+			Client:           marvel,
+			Project:          marvel,
+			Task:             worklog.IDNameField{ID: "124", Name: "#124"},
+			Summary:          "Training",
+			Tags:             []worklog.IDNameField{training},
+			Start:            time.Date(2021, 10, 2, 10, 30, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 2, 10, 45, 0, 0, time.UTC),
+			BillableDuration: 15 * time.Minute,
+			Source:           redmine.SourceName,
+			SourceID:         "2",
+		},
+		{
+			Client:           marvel,
+			Project:          marvel,
+			Task:             training,
+			Summary:          "Train with Natasha",
+			Notes:            "Train with Natasha",
+			Tags:             []worklog.IDNameField{training},
+			Start:            time.Date(2021, 10, 3, 9, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 3, 9, 30, 0, 0, time.UTC),
+			BillableDuration: 30 * time.Minute,
+			Source:           redmine.SourceName,
+			SourceID:         "3",
+		},
+	}, entries)
+
+	// The entries must be complete to be uploaded to other targets
+	for _, entry := range entries {
+		require.True(t, entry.IsComplete())
+	}
+
+	require.Len(t, queries, 1)
+	require.Equal(t, "2021-10-02", queries[0].Get("from"))
+	require.Equal(t, "2021-10-03", queries[0].Get("to"))
+	require.Equal(t, "1", queries[0].Get("user_id"))
+	require.Equal(t, "0", queries[0].Get("offset"))
+	require.Equal(t, strconv.Itoa(redmine.MaxPageSize), queries[0].Get("limit"))
+}
+
+func TestRedmineClient_FetchEntries_Pagination(t *testing.T) {
+	totalEntries := redmine.MaxPageSize + 1
+	var offsets []string
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+
+		pageEntries := redmine.MaxPageSize
+		if offset > 0 {
+			pageEntries = totalEntries - redmine.MaxPageSize
+		}
+
+		fetchedEntries := make([]redmine.TimeEntry, pageEntries)
+		for i := range fetchedEntries {
+			fetchedEntries[i] = redmine.TimeEntry{
+				ID:      offset + i + 1,
+				Hours:   0.05,
+				SpentOn: "2021-10-02",
+			}
+		}
+
+		err := json.NewEncoder(w).Encode(&redmine.FetchResponse{
+			TimeEntries: fetchedEntries,
+			TotalCount:  totalEntries,
+			Offset:      offset,
+			Limit:       redmine.MaxPageSize,
+		})
+		require.Nil(t, err, "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	clientOpts := newClientOpts(mockServer.URL)
+	clientOpts.Client = "My Awesome Company"
+
+	redmineClient, err := redmine.NewFetcher(clientOpts)
+	require.Nil(t, err)
+
+	entries, err := redmineClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 3, 0, 0, 0, 0, time.UTC),
+	})
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, totalEntries)
+	require.Equal(t, []string{"0", strconv.Itoa(redmine.MaxPageSize)}, offsets)
+	require.Equal(t, worklog.IDNameField{ID: "My Awesome Company", Name: "My Awesome Company"}, entries[0].Client)
+
+	// The entries of the day must not overlap across pages
+	for i := 1; i < len(entries); i++ {
+		require.Equal(t, entries[i-1].End, entries[i].Start)
+	}
+}
+
+func TestRedmineClient_UploadEntries(t *testing.T) {
+	start := time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC)
+
+	var lock sync.Mutex
+	var uploadedEntries []redmine.UploadEntry
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "t-o-k-e-n", r.Header.Get(redmine.HeaderAPIKey))
+
+		var err error
+		switch r.URL.Path {
+		case redmine.PathProjects:
+			err = json.NewEncoder(w).Encode(&redmine.ProjectsResponse{
+				Projects: []redmine.Project{
+					{ID: 455, Name: "Avengers", Identifier: "avengers"},
+					{ID: 456, Name: "Marvel Studios", Identifier: "marvel"},
+				},
+				TotalCount: 2,
+			})
+		case redmine.PathTimeEntries:
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			var uploadRequest redmine.UploadRequest
+			err = json.NewDecoder(r.Body).Decode(&uploadRequest)
+
+			lock.Lock()
+			uploadedEntries = append(uploadedEntries, uploadRequest.TimeEntry)
+			lock.Unlock()
+		default:
+			require.Failf(t, "unexpected path", r.URL.Path)
+		}
+
+		require.Nil(t, err)
+	}))
+	defer mockServer.Close()
+
+	entries := worklog.Entries{
+		{
+			Project:          worklog.IDNameField{ID: "456", Name: "Marvel Studios"},
+			Task:             worklog.IDNameField{ID: "123", Name: "#123"},
+			Summary:          "Meet with The Winter Soldier",
+			Tags:             []worklog.IDNameField{{ID: "meeting", Name: "meeting"}},
+			Start:            start,
+			BillableDuration: 90 * time.Minute,
+		},
+		{
+			Project:            worklog.IDNameField{ID: "marvel", Name: "marvel"},
+			Task:               worklog.IDNameField{ID: "Training", Name: "Training"},
+			Summary:            "Train with Natasha",
+			Start:              start.Add(2 * time.Hour),
+			UnbillableDuration: 30 * time.Minute,
+		},
+		{
+			Project:          worklog.IDNameField{ID: "Avengers", Name: "Avengers"},
+			Task:             worklog.IDNameField{ID: "Fighting", Name: "Fighting"},
+			Summary:          "Fight with Thanos",
+			Start:            start.Add(4 * time.Hour),
+			BillableDuration: time.Hour,
+		},
+		{
+			Project:          worklog.IDNameField{ID: "Shield", Name: "Shield"},
+			Task:             worklog.IDNameField{ID: "Hiding", Name: "Hiding"},
+			Summary:          "Hide from Hydra",
+			Start:            start.Add(6 * time.Hour),
+			BillableDuration: time.Hour,
+		},
+	}
+
+	redmineClient, err := redmine.NewUploader(newClientOpts(mockServer.URL))
+	require.Nil(t, err)
+
+	errChan := make(chan error)
+	redmineClient.UploadEntries(context.Background(), entries, errChan, &client.UploadOpts{User: "1"})
+
+	var errs []error
+	for i := 0; i < len(entries); i++ {
+		if err := <-errChan; err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], redmine.ErrProjectNotFound.Error())
+
+	require.ElementsMatch(t, []redmine.UploadEntry{
+		{
+			IssueID:    123,
+			SpentOn:    "2021-10-02",
+			Hours:      1.5,
+			ActivityID: 8,
+			Comments:   "Meet with The Winter Soldier",
+			UserID:     1,
+		},
+		{
+			ProjectID:  456,
+			SpentOn:    "2021-10-02",
+			Hours:      0.5,
+			ActivityID: 9,
+			Comments:   "Train with Natasha",
+			UserID:     1,
+		},
+		{
+			ProjectID:  455,
+			SpentOn:    "2021-10-02",
+			Hours:      1,
+			ActivityID: 10,
+			Comments:   "Fight with Thanos",
+			UserID:     1,
+		},
+	}, uploadedEntries)
+}
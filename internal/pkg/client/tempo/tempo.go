@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -16,6 +15,8 @@ import (
 )
 
 const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "tempo"
 	// PathWorklogCreate is the endpoint used to create new worklogs.
 	PathWorklogCreate string = "/rest/tempo-timesheets/4/worklogs"
 	// PathWorklogSearch is the endpoint used to search existing worklogs.
@@ -90,8 +91,8 @@ func (c *tempoClient) FetchEntries(ctx context.Context, opts *client.FetchOpts)
 		Auth:    c.authenticator,
 		Timeout: c.Timeout,
 		Data: &SearchParams{
-			From:   utils.DateFormatISO8601.Format(opts.Start.Local()),
-			To:     utils.DateFormatISO8601.Format(opts.End.Local()),
+			From:   utils.DateFormatISO8601.FormatInLocation(opts.Start, c.Location()),
+			To:     utils.DateFormatISO8601.FormatInLocation(opts.End, c.Location()),
 			Worker: opts.User,
 		},
 		Headers: map[string]string{
@@ -126,8 +127,11 @@ func (c *tempoClient) FetchEntries(ctx context.Context, opts *client.FetchOpts)
 			Summary:            entry.Issue.Summary,
 			Notes:              entry.Comment,
 			Start:              entry.StartDate,
+			End:                entry.StartDate.Add(time.Second * time.Duration(entry.TimeSpentSeconds)),
 			BillableDuration:   time.Second * time.Duration(entry.BillableSeconds),
 			UnbillableDuration: time.Second * time.Duration(entry.TimeSpentSeconds-entry.BillableSeconds),
+			Source:             SourceName,
+			SourceID:           strconv.Itoa(entry.ID),
 		})
 	}
 
@@ -144,26 +148,20 @@ func (c *tempoClient) UploadEntries(ctx context.Context, entries worklog.Entries
 	for _, groupEntries := range entries.GroupByTask() {
 		go func(ctx context.Context, entries worklog.Entries, errChan chan error, opts *client.UploadOpts) {
 			for _, entry := range entries {
-				billableDuration := entry.BillableDuration
-				unbillableDuration := entry.UnbillableDuration
+				billableDuration, unbillableDuration := c.Durations(entry, opts)
 				totalTimeSpent := billableDuration + unbillableDuration
 
-				if opts.TreatDurationAsBilled {
-					billableDuration = entry.UnbillableDuration + entry.BillableDuration
-					unbillableDuration = 0
-				}
-
-				if opts.RoundToClosestMinute {
-					billableDuration = time.Second * time.Duration(math.Round(billableDuration.Minutes())*60)
-					unbillableDuration = time.Second * time.Duration(math.Round(unbillableDuration.Minutes())*60)
-					totalTimeSpent = billableDuration + unbillableDuration
+				comment, err := c.RenderComment(entry, opts.CommentTemplate)
+				if err != nil {
+					errChan <- fmt.Errorf("%v: %v", client.ErrUploadEntries, err)
+					continue
 				}
 
 				uploadEntry := &UploadEntry{
-					Comment:               entry.Summary,
+					Comment:               comment,
 					IncludeNonWorkingDays: true,
 					OriginTaskID:          entry.Task.Name,
-					Started:               utils.DateFormatISO8601.Format(entry.Start.Local()),
+					Started:               utils.DateFormatISO8601.FormatInLocation(entry.Start, c.Location()),
 					BillableSeconds:       int(billableDuration.Seconds()),
 					TimeSpentSeconds:      int(totalTimeSpent.Seconds()),
 					Worker:                opts.User,
@@ -171,7 +169,7 @@ func (c *tempoClient) UploadEntries(ctx context.Context, entries worklog.Entries
 
 				tracker := c.StartTracking(entry, opts.ProgressWriter)
 
-				_, err := c.Call(ctx, &client.HTTPRequestOpts{
+				_, err = c.Call(ctx, &client.HTTPRequestOpts{
 					Method:  http.MethodPost,
 					Url:     createURL,
 					Auth:    c.authenticator,
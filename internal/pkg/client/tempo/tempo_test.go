@@ -76,20 +76,12 @@ func mockServer(t *testing.T, e *mockServerOpts) *httptest.Server {
 				// Although in tests we define upload entries as a list, in the
 				// reality it is uploaded one by one.
 				allEntries := e.RequestData.(*[]tempo.UploadEntry)
-				data = tempo.UploadEntry{}
-				if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+				var uploadEntry tempo.UploadEntry
+				if err := json.NewDecoder(r.Body).Decode(&uploadEntry); err != nil {
 					t.Fatal(err)
 				}
 
-				for i, entry := range *allEntries {
-					if data == entry {
-						break
-					}
-
-					if i == len(*allEntries) && data != entry {
-						t.Fatal("cannot find expected upload entry")
-					}
-				}
+				require.Contains(t, *allEntries, uploadEntry, "cannot find expected upload entry")
 			default:
 				t.Fatalf("%s is not a known data type", dataType)
 			}
@@ -134,8 +126,11 @@ func TestTempoClient_FetchEntries(t *testing.T) {
 			Summary:            "Meet with The Winter Soldier",
 			Notes:              "I met with The Winter Soldier",
 			Start:              start,
+			End:                start.Add(time.Second * 3600),
 			BillableDuration:   time.Second * 3600,
 			UnbillableDuration: 0,
+			Source:             tempo.SourceName,
+			SourceID:           "123",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -153,8 +148,11 @@ func TestTempoClient_FetchEntries(t *testing.T) {
 			Summary:            "Meet with The Winter Soldier",
 			Notes:              "I met with him again",
 			Start:              start,
+			End:                start.Add(time.Second * 3600),
 			BillableDuration:   time.Second * 1800,
 			UnbillableDuration: time.Second * 1800,
+			Source:             tempo.SourceName,
+			SourceID:           "456",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -172,8 +170,11 @@ func TestTempoClient_FetchEntries(t *testing.T) {
 			Summary:            "Meet with The Winter Soldier",
 			Notes:              "I helped him to get back on track",
 			Start:              start,
+			End:                start.Add(time.Second * 3600),
 			BillableDuration:   0,
 			UnbillableDuration: time.Second * 3600,
+			Source:             tempo.SourceName,
+			SourceID:           "789",
 		},
 	}
 
@@ -319,9 +320,9 @@ func TestTempoClient_UploadEntries(t *testing.T) {
 	var responseEntries []tempo.UploadEntry
 	for _, entry := range entries {
 		responseEntries = append(responseEntries, tempo.UploadEntry{
-			Comment:               entry.Notes,
+			Comment:               entry.Summary,
 			IncludeNonWorkingDays: true,
-			OriginTaskID:          entry.Task.ID,
+			OriginTaskID:          entry.Task.Name,
 			Started:               utils.DateFormatISO8601.Format(entry.Start.Local()),
 			BillableSeconds:       int(entry.BillableDuration.Seconds()),
 			TimeSpentSeconds:      int((entry.BillableDuration + entry.UnbillableDuration).Seconds()),
@@ -361,6 +362,86 @@ func TestTempoClient_UploadEntries(t *testing.T) {
 	}
 }
 
+func TestTempoClient_UploadEntries_Timezone(t *testing.T) {
+	// The entry is started on 2021-10-03 in UTC+9, but on 2021-10-02 in UTC
+	start := time.Date(2021, 10, 2, 23, 30, 0, 0, time.UTC)
+
+	clientUsername := "Thor"
+	clientPassword := "The strongest Avenger"
+
+	progressWriter := cmdUtils.NewProgressWriter(progress.DefaultUpdateFrequency)
+	uploadOpts := &client.UploadOpts{
+		User:           "steve-rogers",
+		ProgressWriter: progressWriter,
+	}
+
+	entries := worklog.Entries{
+		{
+			Client: worklog.IDNameField{
+				ID:   "My Awesome Company",
+				Name: "My Awesome Company",
+			},
+			Project: worklog.IDNameField{
+				ID:   strconv.Itoa(456),
+				Name: "MARVEL",
+			},
+			Task: worklog.IDNameField{
+				ID:   strconv.Itoa(789),
+				Name: "CPT-2014",
+			},
+			Summary:            "Meet with The Winter Soldier",
+			Notes:              "I met with The Winter Soldier",
+			Start:              start,
+			BillableDuration:   time.Second * 3600,
+			UnbillableDuration: 0,
+		},
+	}
+
+	responseEntries := []tempo.UploadEntry{
+		{
+			Comment:               entries[0].Summary,
+			IncludeNonWorkingDays: true,
+			OriginTaskID:          entries[0].Task.Name,
+			Started:               "2021-10-03",
+			BillableSeconds:       3600,
+			TimeSpentSeconds:      3600,
+			Worker:                uploadOpts.User,
+		},
+	}
+
+	mockServer := newMockServer(t, &mockServerOpts{
+		Path:        tempo.PathWorklogCreate,
+		Method:      http.MethodPost,
+		StatusCode:  http.StatusOK,
+		Username:    clientUsername,
+		Password:    clientPassword,
+		RequestData: &responseEntries,
+	})
+	defer mockServer.Close()
+
+	tempoClient, err := tempo.NewUploader(&tempo.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.FixedZone("UTC+9", 9*60*60),
+		},
+		BasicAuth: client.BasicAuth{
+			Username: clientUsername,
+			Password: clientPassword,
+		},
+		BaseURL: mockServer.URL,
+	})
+	require.Nil(t, err)
+
+	errChan := make(chan error)
+	tempoClient.UploadEntries(context.Background(), entries, errChan, uploadOpts)
+
+	for i := 0; i < len(entries); i++ {
+		if err := <-errChan; err != nil {
+			require.Failf(t, "cannot upload entries", err.Error())
+		}
+	}
+}
+
 func TestTempoClient_UploadEntries_TreatDurationAsBilled(t *testing.T) {
 	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
 
@@ -416,9 +497,9 @@ func TestTempoClient_UploadEntries_TreatDurationAsBilled(t *testing.T) {
 	var responseEntries []tempo.UploadEntry
 	for _, entry := range entries {
 		responseEntries = append(responseEntries, tempo.UploadEntry{
-			Comment:               entry.Notes,
+			Comment:               entry.Summary,
 			IncludeNonWorkingDays: true,
-			OriginTaskID:          entry.Task.ID,
+			OriginTaskID:          entry.Task.Name,
 			Started:               entry.Start.Local().Format("2006-01-02"),
 			BillableSeconds:       int(entry.BillableDuration.Seconds()),
 			TimeSpentSeconds:      int((entry.BillableDuration + entry.UnbillableDuration).Seconds()),
@@ -546,36 +627,36 @@ func TestTempoClient_UploadEntries_RoundToClosestMinute(t *testing.T) {
 
 	responseEntries := []tempo.UploadEntry{
 		{
-			Comment:               entries[0].Notes,
+			Comment:               entries[0].Summary,
 			IncludeNonWorkingDays: true,
-			OriginTaskID:          entries[0].Task.ID,
+			OriginTaskID:          entries[0].Task.Name,
 			Started:               utils.DateFormatISO8601.Format(entries[0].Start.Local()),
 			BillableSeconds:       60,
 			TimeSpentSeconds:      60,
 			Worker:                uploadOpts.User,
 		},
 		{
-			Comment:               entries[1].Notes,
+			Comment:               entries[1].Summary,
 			IncludeNonWorkingDays: true,
-			OriginTaskID:          entries[1].Task.ID,
+			OriginTaskID:          entries[1].Task.Name,
 			Started:               utils.DateFormatISO8601.Format(entries[1].Start.Local()),
 			BillableSeconds:       0,
 			TimeSpentSeconds:      0,
 			Worker:                uploadOpts.User,
 		},
 		{
-			Comment:               entries[2].Notes,
+			Comment:               entries[2].Summary,
 			IncludeNonWorkingDays: true,
-			OriginTaskID:          entries[2].Task.ID,
+			OriginTaskID:          entries[2].Task.Name,
 			Started:               utils.DateFormatISO8601.Format(entries[2].Start.Local()),
 			BillableSeconds:       1,
 			TimeSpentSeconds:      60,
 			Worker:                uploadOpts.User,
 		},
 		{
-			Comment:               entries[3].Notes,
+			Comment:               entries[3].Summary,
 			IncludeNonWorkingDays: true,
-			OriginTaskID:          entries[3].Task.ID,
+			OriginTaskID:          entries[3].Task.Name,
 			Started:               utils.DateFormatISO8601.Format(entries[3].Start.Local()),
 			BillableSeconds:       0,
 			TimeSpentSeconds:      60,
@@ -0,0 +1,240 @@
+package timewarrior
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+)
+
+const (
+	// EnvDatabase is the environment variable used by Timewarrior to override
+	// the location of its database.
+	EnvDatabase string = "TIMEWARRIORDB"
+)
+
+var (
+	// ErrInvalidInterval returns when a line of a data file cannot be parsed.
+	ErrInvalidInterval = errors.New("invalid interval")
+
+	dataFileRegex = regexp.MustCompile(`^\d{4}-\d{2}\.data$`)
+)
+
+// DefaultDataDir returns the directory of the Timewarrior data files. The
+// database location is read from the TIMEWARRIORDB environment variable, and
+// defaults to the ".timewarrior" directory in the home directory of the user.
+func DefaultDataDir() (string, error) {
+	database := os.Getenv(EnvDatabase)
+
+	if database == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+
+		database = filepath.Join(homeDir, ".timewarrior")
+	}
+
+	return filepath.Join(database, "data"), nil
+}
+
+// token represents a word of an interval line. Quoted words are kept together,
+// and cannot act as a separator.
+type token struct {
+	value  string
+	quoted bool
+}
+
+// tokenize splits the line by whitespaces, keeping the quoted words together
+// and unescaping the escaped characters within them.
+func tokenize(line string) ([]token, error) {
+	var tokens []token
+	var current strings.Builder
+
+	inWord := false
+	inQuotes := false
+	escaped := false
+	quoted := false
+
+	for _, char := range line {
+		switch {
+		case escaped:
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\':
+			escaped = true
+			inWord = true
+		case char == '"':
+			inQuotes = !inQuotes
+			quoted = true
+			inWord = true
+		case (char == ' ' || char == '\t') && !inQuotes:
+			if inWord {
+				tokens = append(tokens, token{value: current.String(), quoted: quoted})
+				current.Reset()
+			}
+
+			inWord = false
+			quoted = false
+		default:
+			current.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	if inQuotes || escaped {
+		return nil, fmt.Errorf("%v: unterminated quote: %s", ErrInvalidInterval, line)
+	}
+
+	if inWord {
+		tokens = append(tokens, token{value: current.String(), quoted: quoted})
+	}
+
+	return tokens, nil
+}
+
+// ParseInterval parses a line of a Timewarrior data file, having the format of
+// `inc <start> [- <end>] [# <tags>] [# "<annotation>"]`. The end of the open
+// interval, which is the running interval, is left empty. The ID of the entry
+// is not part of the line, hence it is not set.
+func ParseInterval(line string) (FetchEntry, error) {
+	var entry FetchEntry
+
+	tokens, err := tokenize(line)
+	if err != nil {
+		return entry, err
+	}
+
+	if len(tokens) < 2 || tokens[0].value != "inc" {
+		return entry, fmt.Errorf("%v: %s", ErrInvalidInterval, line)
+	}
+
+	entry.Start = tokens[1].value
+	tokens = tokens[2:]
+
+	if len(tokens) > 0 && !tokens[0].quoted && tokens[0].value == "-" {
+		if len(tokens) < 2 {
+			return entry, fmt.Errorf("%v: %s", ErrInvalidInterval, line)
+		}
+
+		entry.End = tokens[1].value
+		tokens = tokens[2:]
+	}
+
+	// The first separator starts the tags, the second one the annotation
+	var annotation []string
+	separators := 0
+
+	for _, tok := range tokens {
+		if !tok.quoted && tok.value == "#" {
+			separators++
+			continue
+		}
+
+		switch separators {
+		case 1:
+			entry.Tags = append(entry.Tags, tok.value)
+		case 2:
+			annotation = append(annotation, tok.value)
+		default:
+			return entry, fmt.Errorf("%v: %s", ErrInvalidInterval, line)
+		}
+	}
+
+	entry.Annotation = strings.Join(annotation, " ")
+
+	return entry, nil
+}
+
+// readDataFile returns the intervals of the data file.
+func readDataFile(path string) ([]FetchEntry, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var entries []FetchEntry
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		entry, err := ParseInterval(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// ReadDataDir returns the intervals stored in the data files of the directory
+// that overlap the given date range. Similarly to `timew export`, the intervals
+// are numbered in reverse chronological order, hence the latest interval gets
+// the ID 1. The open interval is treated as it would end now.
+func ReadDataDir(dataDir string, start time.Time, end time.Time) ([]FetchEntry, error) {
+	files, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []FetchEntry
+
+	// Every data file must be read to number the intervals the way Timewarrior
+	// does, though the files are small as they are holding a month of data
+	for _, file := range files {
+		if file.IsDir() || !dataFileRegex.MatchString(file.Name()) {
+			continue
+		}
+
+		fileEntries, err := readDataFile(filepath.Join(dataDir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, fileEntries...)
+	}
+
+	// The compact RFC3339 dates are sortable as strings
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start < entries[j].Start
+	})
+
+	var overlapping []FetchEntry
+
+	for i, entry := range entries {
+		entry.ID = len(entries) - i
+
+		entryStart, err := utils.DateFormatRFC3339Compact.Parse(entry.Start)
+		if err != nil {
+			return nil, err
+		}
+
+		entryEnd := time.Now()
+		if entry.End != "" {
+			if entryEnd, err = utils.DateFormatRFC3339Compact.Parse(entry.End); err != nil {
+				return nil, err
+			}
+		}
+
+		if entryStart.Before(end) && entryEnd.After(start) {
+			overlapping = append(overlapping, entry)
+		}
+	}
+
+	return overlapping, nil
+}
@@ -0,0 +1,206 @@
+package timewarrior_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/timewarrior"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDataFiles(t *testing.T, files map[string]string) string {
+	dataDir := t.TempDir()
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dataDir, name), []byte(content), 0600)
+		require.Nil(t, err)
+	}
+
+	return dataDir
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := map[string]timewarrior.FetchEntry{
+		`inc 20211012T054408Z - 20211012T054420Z`: {
+			Start: "20211012T054408Z",
+			End:   "20211012T054420Z",
+		},
+		`inc 20211012T054408Z - 20211012T054420Z # TASK-123 project`: {
+			Start: "20211012T054408Z",
+			End:   "20211012T054420Z",
+			Tags:  []string{"TASK-123", "project"},
+		},
+		`inc 20211012T054408Z - 20211012T054420Z # TASK-123 "client tag" # "working on \"timewarrior\" integration"`: {
+			Start:      "20211012T054408Z",
+			End:        "20211012T054420Z",
+			Tags:       []string{"TASK-123", "client tag"},
+			Annotation: `working on "timewarrior" integration`,
+		},
+		`inc 20211012T054408Z - 20211012T054420Z # # "working unbilled"`: {
+			Start:      "20211012T054408Z",
+			End:        "20211012T054420Z",
+			Annotation: "working unbilled",
+		},
+		`inc 20211012T054408Z # TASK-123 "#hashtag"`: {
+			Start: "20211012T054408Z",
+			Tags:  []string{"TASK-123", "#hashtag"},
+		},
+	}
+
+	for line, expectedEntry := range tests {
+		t.Run(line, func(t *testing.T) {
+			entry, err := timewarrior.ParseInterval(line)
+			require.Nil(t, err)
+			require.Equal(t, expectedEntry, entry)
+		})
+	}
+}
+
+func TestParseInterval_Invalid(t *testing.T) {
+	for _, line := range []string{
+		`exc 20211012T054408Z - 20211012T054420Z`,
+		`inc`,
+		`inc 20211012T054408Z -`,
+		`inc 20211012T054408Z - 20211012T054420Z TASK-123`,
+		`inc 20211012T054408Z - 20211012T054420Z # "TASK-123`,
+		`inc 20211012T054408Z - 20211012T054420Z # TASK-123 # "note" # extra`,
+	} {
+		t.Run(line, func(t *testing.T) {
+			_, err := timewarrior.ParseInterval(line)
+			require.ErrorContains(t, err, timewarrior.ErrInvalidInterval.Error())
+		})
+	}
+}
+
+func TestDefaultDataDir(t *testing.T) {
+	t.Setenv(timewarrior.EnvDatabase, "/srv/timewarrior")
+
+	dataDir, err := timewarrior.DefaultDataDir()
+	require.Nil(t, err)
+	require.Equal(t, "/srv/timewarrior/data", dataDir)
+
+	t.Setenv(timewarrior.EnvDatabase, "")
+	t.Setenv("HOME", "/home/steve")
+
+	dataDir, err = timewarrior.DefaultDataDir()
+	require.Nil(t, err)
+	require.Equal(t, "/home/steve/.timewarrior/data", dataDir)
+}
+
+func TestReadDataDir(t *testing.T) {
+	dataDir := writeDataFiles(t, map[string]string{
+		"2021-09.data": "inc 20210930T220000Z - 20210930T230000Z # TASK-1\n",
+		"2021-10.data": "inc 20211012T054408Z - 20211012T054420Z # TASK-2\n\n" +
+			"inc 20211013T080000Z - 20211013T090000Z # TASK-3\n" +
+			"inc 20211014T080000Z # TASK-4\n",
+		"tags.data":  "{}",
+		"undo.data":  "txn:\n",
+		"backlog.db": "",
+	})
+
+	entries, err := timewarrior.ReadDataDir(
+		dataDir,
+		time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+	)
+
+	require.Nil(t, err)
+	require.Equal(t, []timewarrior.FetchEntry{
+		{ID: 3, Start: "20211012T054408Z", End: "20211012T054420Z", Tags: []string{"TASK-2"}},
+	}, entries)
+
+	// The open interval is treated as it would end now
+	entries, err = timewarrior.ReadDataDir(
+		dataDir,
+		time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+		time.Now().Add(time.Hour),
+	)
+
+	require.Nil(t, err)
+	require.Equal(t, []timewarrior.FetchEntry{
+		{ID: 2, Start: "20211013T080000Z", End: "20211013T090000Z", Tags: []string{"TASK-3"}},
+		{ID: 1, Start: "20211014T080000Z", Tags: []string{"TASK-4"}},
+	}, entries)
+}
+
+func TestReadDataDir_InvalidInterval(t *testing.T) {
+	dataDir := writeDataFiles(t, map[string]string{
+		"2021-10.data": "inc 20211012T054408Z - 20211012T054420Z # \"TASK-2\n",
+	})
+
+	_, err := timewarrior.ReadDataDir(dataDir, time.Time{}, time.Now())
+	require.ErrorContains(t, err, timewarrior.ErrInvalidInterval.Error())
+}
+
+func TestTimewarriorClient_FetchEntries_DataBackend(t *testing.T) {
+	start := time.Date(2021, 10, 12, 5, 44, 8, 0, time.UTC)
+	end := time.Date(2021, 10, 12, 5, 44, 20, 0, time.UTC)
+
+	dataDir := writeDataFiles(t, map[string]string{
+		"2021-10.data": `inc 20211012T054408Z - 20211012T054420Z # TASK-123 project client unbillable # "working \"unbilled\""` + "\n",
+	})
+
+	timewarriorClient, err := timewarrior.NewFetcher(&timewarrior.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout: client.DefaultRequestTimeout,
+		},
+		Backend:         timewarrior.BackendData,
+		DataDir:         dataDir,
+		UnbillableTag:   "unbillable",
+		ClientTagRegex:  "^(client|otherclient)$",
+		ProjectTagRegex: "^(project)$",
+	})
+	require.Nil(t, err)
+
+	entries, err := timewarriorClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start:            start,
+		End:              end,
+		TagsAsTasksRegex: regexp.MustCompile(`^TASK-\d+$`),
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, worklog.Entries{
+		{
+			Client: worklog.IDNameField{
+				ID:   "client",
+				Name: "client",
+			},
+			Project: worklog.IDNameField{
+				ID:   "project",
+				Name: "project",
+			},
+			Task: worklog.IDNameField{
+				ID:   "TASK-123",
+				Name: "TASK-123",
+			},
+			Summary: `working "unbilled"`,
+			Notes:   `working "unbilled"`,
+			Tags: []worklog.IDNameField{
+				{ID: "TASK-123", Name: "TASK-123"},
+				{ID: "project", Name: "project"},
+				{ID: "client", Name: "client"},
+				{ID: "unbillable", Name: "unbillable"},
+			},
+			Start:              start,
+			End:                end,
+			BillableDuration:   0,
+			UnbillableDuration: end.Sub(start),
+			Source:             timewarrior.SourceName,
+			SourceID:           "1",
+		},
+	}, entries)
+}
+
+func TestNewFetcher_UnknownBackend(t *testing.T) {
+	_, err := timewarrior.NewFetcher(&timewarrior.ClientOpts{
+		Backend: "sqlite",
+	})
+
+	require.ErrorContains(t, err, timewarrior.ErrUnknownBackend.Error())
+}
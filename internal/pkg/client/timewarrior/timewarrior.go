@@ -3,8 +3,10 @@ package timewarrior
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/gabor-boros/minutes/internal/pkg/client"
@@ -12,6 +14,22 @@ import (
 	"github.com/gabor-boros/minutes/internal/pkg/worklog"
 )
 
+const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "timewarrior"
+	// BackendCLI sets to fetch the entries by exporting them using the CLI.
+	BackendCLI string = "cli"
+	// BackendData sets to fetch the entries by reading the data files of
+	// Timewarrior directly, without running the CLI.
+	BackendData string = "data"
+)
+
+var (
+	// ErrUnknownBackend returns when the backend is not BackendCLI or
+	// BackendData.
+	ErrUnknownBackend = errors.New("unknown backend")
+)
+
 // FetchEntry represents the entry exported from Timewarrior.
 type FetchEntry struct {
 	ID         int      `json:"id"`
@@ -26,10 +44,13 @@ type FetchEntry struct {
 // Although client.HTTPClientOpts is part of client.BaseClientOpts, we are
 // not using that as part of this integration, instead we are defining the path
 // of the executable (Command) and the command arguments used for export
-// (CommandArguments).
+// (CommandArguments). When the Backend is BackendData, the data files are read
+// from the DataDir instead, and the CLI is not used at all.
 type ClientOpts struct {
 	client.BaseClientOpts
 	client.CLIClient
+	Backend         string
+	DataDir         string
 	UnbillableTag   string
 	ClientTagRegex  string
 	ProjectTagRegex string
@@ -38,6 +59,8 @@ type ClientOpts struct {
 type timewarriorClient struct {
 	*client.BaseClientOpts
 	*client.CLIClient
+	backend         string
+	dataDir         string
 	clientTagRegex  *regexp.Regexp
 	projectTagRegex *regexp.Regexp
 	unbillableTag   string
@@ -46,22 +69,43 @@ type timewarriorClient struct {
 func (c *timewarriorClient) parseEntry(entry FetchEntry, opts *client.FetchOpts) (worklog.Entries, error) {
 	var entries worklog.Entries
 
-	startDate, err := time.ParseInLocation(utils.DateFormatRFC3339Compact.String(), entry.Start, time.Local)
+	startDate, err := utils.DateFormatRFC3339Compact.Parse(entry.Start)
 	if err != nil {
 		return nil, err
 	}
 
-	endDate, err := time.ParseInLocation(utils.DateFormatRFC3339Compact.String(), entry.End, time.Local)
-	if err != nil {
+	// The running interval has no end
+	var endDate time.Time
+	running := entry.End == ""
+
+	if running {
+		var keep bool
+		if endDate, keep, err = opts.RunningEntryEnd(startDate); err != nil || !keep {
+			return nil, err
+		}
+	} else if endDate, err = utils.DateFormatRFC3339Compact.Parse(entry.End); err != nil {
 		return nil, err
 	}
 
+	var tags []worklog.IDNameField
+	for _, tag := range entry.Tags {
+		tags = append(tags, worklog.IDNameField{
+			ID:   tag,
+			Name: tag,
+		})
+	}
+
 	worklogEntry := worklog.Entry{
 		Summary:            entry.Annotation,
 		Notes:              entry.Annotation,
+		Tags:               tags,
 		Start:              startDate,
+		End:                endDate,
 		BillableDuration:   endDate.Sub(startDate),
 		UnbillableDuration: 0,
+		Source:             SourceName,
+		SourceID:           strconv.Itoa(entry.ID),
+		Running:            running,
 	}
 
 	for _, tag := range entry.Tags {
@@ -94,15 +138,7 @@ func (c *timewarriorClient) parseEntry(entry FetchEntry, opts *client.FetchOpts)
 		}
 	}
 
-	if utils.IsRegexSet(opts.TagsAsTasksRegex) && len(entry.Tags) > 0 {
-		var tags []worklog.IDNameField
-		for _, tag := range entry.Tags {
-			tags = append(tags, worklog.IDNameField{
-				ID:   tag,
-				Name: tag,
-			})
-		}
-
+	if utils.IsRegexSet(opts.TagsAsTasksRegex) && len(tags) > 0 {
 		splitEntries := worklogEntry.SplitByTagsAsTasks(worklogEntry.Summary, opts.TagsAsTasksRegex, tags)
 		entries = append(entries, splitEntries...)
 	} else {
@@ -118,8 +154,8 @@ func (c *timewarriorClient) executeCommand(ctx context.Context, subcommand strin
 	arguments = append(
 		arguments,
 		[]string{
-			"from", utils.DateFormatRFC3339Local.Format(opts.Start),
-			"to", utils.DateFormatRFC3339Local.Format(opts.End),
+			"from", utils.DateFormatRFC3339Local.FormatInLocation(opts.Start, c.Location()),
+			"to", utils.DateFormatRFC3339Local.FormatInLocation(opts.End, c.Location()),
 		}...,
 	)
 
@@ -141,8 +177,16 @@ func (c *timewarriorClient) executeCommand(ctx context.Context, subcommand strin
 }
 
 func (c *timewarriorClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	var err error
 	var fetchedEntries []FetchEntry
-	if err := c.executeCommand(ctx, "export", &fetchedEntries, opts); err != nil {
+
+	if c.backend == BackendData {
+		fetchedEntries, err = ReadDataDir(c.dataDir, opts.Start, opts.End)
+	} else {
+		err = c.executeCommand(ctx, "export", &fetchedEntries, opts)
+	}
+
+	if err != nil {
 		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
 	}
 
@@ -171,9 +215,27 @@ func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
 		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
 	}
 
+	backend := opts.Backend
+	if backend == "" {
+		backend = BackendCLI
+	}
+
+	if backend != BackendCLI && backend != BackendData {
+		return nil, fmt.Errorf("%v: %v: %s", client.ErrFetchEntries, ErrUnknownBackend, backend)
+	}
+
+	dataDir := opts.DataDir
+	if backend == BackendData && dataDir == "" {
+		if dataDir, err = DefaultDataDir(); err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+	}
+
 	return &timewarriorClient{
 		BaseClientOpts:  &opts.BaseClientOpts,
 		CLIClient:       &opts.CLIClient,
+		backend:         backend,
+		dataDir:         dataDir,
 		unbillableTag:   opts.UnbillableTag,
 		clientTagRegex:  clientTagRegex,
 		projectTagRegex: projectTagRegex,
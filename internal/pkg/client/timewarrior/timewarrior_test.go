@@ -12,7 +12,6 @@ import (
 
 	"github.com/gabor-boros/minutes/internal/pkg/client"
 	"github.com/gabor-boros/minutes/internal/pkg/client/timewarrior"
-	"github.com/gabor-boros/minutes/internal/pkg/utils"
 	"github.com/gabor-boros/minutes/internal/pkg/worklog"
 	"github.com/stretchr/testify/require"
 )
@@ -51,8 +50,8 @@ func TestExecCommandHelper(t *testing.T) {
 }
 
 func TestTimewarriorClient_FetchEntries(t *testing.T) {
-	start, _ := time.ParseInLocation(utils.DateFormatRFC3339Compact.String(), "20211012T054408Z", time.Local)
-	end, _ := time.ParseInLocation(utils.DateFormatRFC3339Compact.String(), "20211012T054420Z", time.Local)
+	start := time.Date(2021, 10, 12, 5, 44, 8, 0, time.UTC)
+	end := time.Date(2021, 10, 12, 5, 44, 20, 0, time.UTC)
 
 	mockedExitCode = 0
 	mockedStdout = `[
@@ -75,11 +74,28 @@ func TestTimewarriorClient_FetchEntries(t *testing.T) {
 				ID:   "working on timewarrior integration",
 				Name: "working on timewarrior integration",
 			},
-			Summary:            "working on timewarrior integration",
-			Notes:              "working on timewarrior integration",
+			Summary: "working on timewarrior integration",
+			Notes:   "working on timewarrior integration",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-123",
+					Name: "TASK-123",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "otherclient",
+					Name: "otherclient",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   end.Sub(start),
 			UnbillableDuration: 0,
+			Source:             timewarrior.SourceName,
+			SourceID:           "3",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -94,11 +110,32 @@ func TestTimewarriorClient_FetchEntries(t *testing.T) {
 				ID:   "working unbilled",
 				Name: "working unbilled",
 			},
-			Summary:            "working unbilled",
-			Notes:              "working unbilled",
+			Summary: "working unbilled",
+			Notes:   "working unbilled",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-123",
+					Name: "TASK-123",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "client",
+					Name: "client",
+				},
+				{
+					ID:   "unbillable",
+					Name: "unbillable",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start),
+			Source:             timewarrior.SourceName,
+			SourceID:           "2",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -113,11 +150,36 @@ func TestTimewarriorClient_FetchEntries(t *testing.T) {
 				ID:   "working unbilled",
 				Name: "working unbilled",
 			},
-			Summary:            "working unbilled",
-			Notes:              "working unbilled",
+			Summary: "working unbilled",
+			Notes:   "working unbilled",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-123",
+					Name: "TASK-123",
+				},
+				{
+					ID:   "TASK-456",
+					Name: "TASK-456",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "client",
+					Name: "client",
+				},
+				{
+					ID:   "unbillable",
+					Name: "unbillable",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start),
+			Source:             timewarrior.SourceName,
+			SourceID:           "1",
 		},
 	}
 
@@ -148,8 +210,8 @@ func TestTimewarriorClient_FetchEntries(t *testing.T) {
 }
 
 func TestTimewarriorClient_FetchEntries_TagsAsTasksRegex_NoSplit(t *testing.T) {
-	start, _ := time.ParseInLocation(utils.DateFormatRFC3339Compact.String(), "20211012T054408Z", time.Local)
-	end, _ := time.ParseInLocation(utils.DateFormatRFC3339Compact.String(), "20211012T054420Z", time.Local)
+	start := time.Date(2021, 10, 12, 5, 44, 8, 0, time.UTC)
+	end := time.Date(2021, 10, 12, 5, 44, 20, 0, time.UTC)
 
 	mockedExitCode = 0
 	mockedStdout = `[
@@ -172,11 +234,28 @@ func TestTimewarriorClient_FetchEntries_TagsAsTasksRegex_NoSplit(t *testing.T) {
 				ID:   "TASK-123",
 				Name: "TASK-123",
 			},
-			Summary:            "working on timewarrior integration",
-			Notes:              "working on timewarrior integration",
+			Summary: "working on timewarrior integration",
+			Notes:   "working on timewarrior integration",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-123",
+					Name: "TASK-123",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "otherclient",
+					Name: "otherclient",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   end.Sub(start),
 			UnbillableDuration: 0,
+			Source:             timewarrior.SourceName,
+			SourceID:           "3",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -191,11 +270,32 @@ func TestTimewarriorClient_FetchEntries_TagsAsTasksRegex_NoSplit(t *testing.T) {
 				ID:   "TASK-123",
 				Name: "TASK-123",
 			},
-			Summary:            "working unbilled",
-			Notes:              "working unbilled",
+			Summary: "working unbilled",
+			Notes:   "working unbilled",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-123",
+					Name: "TASK-123",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "client",
+					Name: "client",
+				},
+				{
+					ID:   "unbillable",
+					Name: "unbillable",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start),
+			Source:             timewarrior.SourceName,
+			SourceID:           "2",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -210,11 +310,32 @@ func TestTimewarriorClient_FetchEntries_TagsAsTasksRegex_NoSplit(t *testing.T) {
 				ID:   "TASK-456",
 				Name: "TASK-456",
 			},
-			Summary:            "working unbilled",
-			Notes:              "working unbilled",
+			Summary: "working unbilled",
+			Notes:   "working unbilled",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-456",
+					Name: "TASK-456",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "client",
+					Name: "client",
+				},
+				{
+					ID:   "unbillable",
+					Name: "unbillable",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start),
+			Source:             timewarrior.SourceName,
+			SourceID:           "1",
 		},
 	}
 
@@ -245,8 +366,8 @@ func TestTimewarriorClient_FetchEntries_TagsAsTasksRegex_NoSplit(t *testing.T) {
 }
 
 func TestTimewarriorClient_FetchEntries_TagsAsTasks(t *testing.T) {
-	start, _ := time.ParseInLocation(utils.DateFormatRFC3339Compact.String(), "20211012T054408Z", time.Local)
-	end, _ := time.ParseInLocation(utils.DateFormatRFC3339Compact.String(), "20211012T054420Z", time.Local)
+	start := time.Date(2021, 10, 12, 5, 44, 8, 0, time.UTC)
+	end := time.Date(2021, 10, 12, 5, 44, 20, 0, time.UTC)
 
 	mockedExitCode = 0
 	mockedStdout = `[
@@ -269,11 +390,28 @@ func TestTimewarriorClient_FetchEntries_TagsAsTasks(t *testing.T) {
 				ID:   "TASK-123",
 				Name: "TASK-123",
 			},
-			Summary:            "working on timewarrior integration",
-			Notes:              "working on timewarrior integration",
+			Summary: "working on timewarrior integration",
+			Notes:   "working on timewarrior integration",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-123",
+					Name: "TASK-123",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "otherclient",
+					Name: "otherclient",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   end.Sub(start),
 			UnbillableDuration: 0,
+			Source:             timewarrior.SourceName,
+			SourceID:           "3",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -288,11 +426,32 @@ func TestTimewarriorClient_FetchEntries_TagsAsTasks(t *testing.T) {
 				ID:   "TASK-123",
 				Name: "TASK-123",
 			},
-			Summary:            "working unbilled",
-			Notes:              "working unbilled",
+			Summary: "working unbilled",
+			Notes:   "working unbilled",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-123",
+					Name: "TASK-123",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "client",
+					Name: "client",
+				},
+				{
+					ID:   "unbillable",
+					Name: "unbillable",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start),
+			Source:             timewarrior.SourceName,
+			SourceID:           "2",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -307,11 +466,36 @@ func TestTimewarriorClient_FetchEntries_TagsAsTasks(t *testing.T) {
 				ID:   "TASK-123",
 				Name: "TASK-123",
 			},
-			Summary:            "working unbilled split",
-			Notes:              "working unbilled split",
+			Summary: "working unbilled split",
+			Notes:   "working unbilled split",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-123",
+					Name: "TASK-123",
+				},
+				{
+					ID:   "TASK-456",
+					Name: "TASK-456",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "client",
+					Name: "client",
+				},
+				{
+					ID:   "unbillable",
+					Name: "unbillable",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start) / 2,
+			Source:             timewarrior.SourceName,
+			SourceID:           "1",
 		},
 		{
 			Client: worklog.IDNameField{
@@ -326,11 +510,36 @@ func TestTimewarriorClient_FetchEntries_TagsAsTasks(t *testing.T) {
 				ID:   "TASK-456",
 				Name: "TASK-456",
 			},
-			Summary:            "working unbilled split",
-			Notes:              "working unbilled split",
+			Summary: "working unbilled split",
+			Notes:   "working unbilled split",
+			Tags: []worklog.IDNameField{
+				{
+					ID:   "TASK-123",
+					Name: "TASK-123",
+				},
+				{
+					ID:   "TASK-456",
+					Name: "TASK-456",
+				},
+				{
+					ID:   "project",
+					Name: "project",
+				},
+				{
+					ID:   "client",
+					Name: "client",
+				},
+				{
+					ID:   "unbillable",
+					Name: "unbillable",
+				},
+			},
 			Start:              start,
+			End:                end,
 			BillableDuration:   0,
 			UnbillableDuration: end.Sub(start) / 2,
+			Source:             timewarrior.SourceName,
+			SourceID:           "1",
 		},
 	}
 
@@ -359,3 +568,54 @@ func TestTimewarriorClient_FetchEntries_TagsAsTasks(t *testing.T) {
 	require.Nil(t, err, "cannot fetch entries")
 	require.ElementsMatch(t, expectedEntries, entries, "fetched entries are not matching")
 }
+
+func TestTimewarriorClient_FetchEntries_RunningEntries(t *testing.T) {
+	start := time.Date(2021, 10, 12, 5, 44, 8, 0, time.UTC)
+	end := time.Date(2021, 10, 12, 5, 44, 20, 0, time.UTC)
+
+	mockedExitCode = 0
+	mockedStdout = `[
+		{"id":2,"start":"20211012T054408Z","end":"20211012T054420Z","tags":["project","client"],"annotation":"working on timewarrior integration"},
+		{"id":1,"start":"20211012T054408Z","tags":["project","client"],"annotation":"still working"}
+	]`
+
+	timewarriorClient, err := timewarrior.NewFetcher(&timewarrior.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout: client.DefaultRequestTimeout,
+		},
+		CLIClient: client.CLIClient{
+			Command:            "timewarrior-command",
+			CommandArguments:   []string{},
+			CommandCtxExecutor: mockedExecCommand,
+		},
+		UnbillableTag:   "unbillable",
+		ClientTagRegex:  "^(client)$",
+		ProjectTagRegex: "^(project)$",
+	})
+	require.Nil(t, err)
+
+	fetchOpts := &client.FetchOpts{
+		Start: start,
+		End:   end,
+	}
+
+	entries, err := timewarriorClient.FetchEntries(context.Background(), fetchOpts)
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "2", entries[0].SourceID)
+	require.False(t, entries[0].Running)
+
+	fetchOpts.RunningEntries = client.RunningEntriesUntilNow
+	entries, err = timewarriorClient.FetchEntries(context.Background(), fetchOpts)
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "1", entries[1].SourceID)
+	require.True(t, entries[1].Running)
+	require.WithinDuration(t, time.Now(), entries[1].End, time.Second)
+	require.Equal(t, entries[1].End.Sub(start), entries[1].BillableDuration)
+
+	fetchOpts.RunningEntries = client.RunningEntriesError
+	entries, err = timewarriorClient.FetchEntries(context.Background(), fetchOpts)
+	require.Nil(t, entries)
+	require.ErrorContains(t, err, client.ErrRunningEntry.Error())
+}
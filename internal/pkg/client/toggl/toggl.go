@@ -15,31 +15,58 @@ import (
 )
 
 const (
-	// PathWorklog is the endpoint used to search existing worklogs.
-	PathWorklog string = "/reports/api/v2/details"
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "toggl"
+	// PathWorklog is the endpoint used to search existing worklogs. The
+	// workspace ID must be substituted.
+	PathWorklog string = "/reports/api/v3/workspace/%d/search/time_entries"
+	// HeaderNextRowNumber is the response header holding the row number the
+	// next page starts with. The header is not set on the last page.
+	HeaderNextRowNumber string = "X-Next-Row-Number"
+	// ParamFirstRowNumber is the name of the search request field setting the
+	// row number the page starts with.
+	ParamFirstRowNumber string = "first_row_number"
+	// ParamPageSize is the name of the search request field setting the page
+	// size.
+	ParamPageSize string = "page_size"
 )
 
-// FetchEntry represents the entry fetched from Toggl Track.
+// FetchTimeEntry represents a time entry of a search result row.
+type FetchTimeEntry struct {
+	ID      int       `json:"id"`
+	Seconds int       `json:"seconds"`
+	Start   time.Time `json:"start"`
+	Stop    time.Time `json:"stop"`
+}
+
+// FetchEntry represents a row of the detailed search result fetched from Toggl
+// Track. The names of the client, project, task and tags are returned only if
+// the response is enriched.
 type FetchEntry struct {
-	Client      string    `json:"client"`
-	Description string    `json:"description"`
-	Duration    int       `json:"dur"`
-	IsBillable  bool      `json:"is_billable"`
-	Project     string    `json:"project"`
-	ProjectID   int       `json:"pid"`
-	Start       time.Time `json:"start"`
-	End         time.Time `json:"end"`
-	Tags        []string  `json:"tags"`
-	Task        string    `json:"task"`
-	TaskID      int       `json:"tid"`
+	UserID      int              `json:"user_id"`
+	ClientID    int              `json:"client_id"`
+	ClientName  string           `json:"client_name"`
+	ProjectID   int              `json:"project_id"`
+	ProjectName string           `json:"project_name"`
+	TaskID      int              `json:"task_id"`
+	TaskName    string           `json:"task_name"`
+	Billable    bool             `json:"billable"`
+	Description string           `json:"description"`
+	TagIDs      []int            `json:"tag_ids"`
+	TagNames    []string         `json:"tag_names"`
+	RowNumber   int              `json:"row_number"`
+	TimeEntries []FetchTimeEntry `json:"time_entries"`
 }
 
-// FetchResponse represents the response of Toggl Track report APIs.
-// The response would have more fields, but those are not relevant for us.
-type FetchResponse struct {
-	TotalCount int          `json:"total_count"`
-	PerPage    int          `json:"per_page"`
-	Data       []FetchEntry `json:"data"`
+// SearchRequest represents the request body of the detailed search.
+// The request would accept more fields, but those are not relevant for us.
+type SearchRequest struct {
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+	UserIDs        []int  `json:"user_ids,omitempty"`
+	PageSize       int    `json:"page_size,omitempty"`
+	FirstRowNumber int    `json:"first_row_number,omitempty"`
+	EnrichResponse bool   `json:"enrich_response"`
 }
 
 // ClientOpts is the client specific options, extending client.BaseClientOpts.
@@ -57,6 +84,17 @@ type togglClient struct {
 	workspace     int
 }
 
+// idField returns the ID and name as an IDNameField. Toggl Track uses 0 or
+// omits the ID for unset fields, hence the ID is left empty in that case.
+func idField(id int, name string) worklog.IDNameField {
+	field := worklog.IDNameField{Name: name}
+	if id != 0 {
+		field.ID = strconv.Itoa(id)
+	}
+
+	return field
+}
+
 func (c *togglClient) parseEntries(rawEntries interface{}, opts *client.FetchOpts) (worklog.Entries, error) {
 	var entries worklog.Entries
 
@@ -66,99 +104,151 @@ func (c *togglClient) parseEntries(rawEntries interface{}, opts *client.FetchOpt
 	}
 
 	for _, fetchedEntry := range fetchedEntries {
-		billableDuration := time.Millisecond * time.Duration(fetchedEntry.Duration)
-		unbillableDuration := time.Duration(0)
+		var tags []worklog.IDNameField
+		for i, tagID := range fetchedEntry.TagIDs {
+			var tagName string
+			if i < len(fetchedEntry.TagNames) {
+				tagName = fetchedEntry.TagNames[i]
+			}
 
-		if !fetchedEntry.IsBillable {
-			unbillableDuration = billableDuration
-			billableDuration = 0
+			tags = append(tags, idField(tagID, tagName))
 		}
 
-		entry := worklog.Entry{
-			Client: worklog.IDNameField{
-				ID:   fetchedEntry.Client,
-				Name: fetchedEntry.Client,
-			},
-			Project: worklog.IDNameField{
-				ID:   strconv.Itoa(fetchedEntry.ProjectID),
-				Name: fetchedEntry.Project,
-			},
-			Task: worklog.IDNameField{
-				ID:   strconv.Itoa(fetchedEntry.TaskID),
-				Name: fetchedEntry.Task,
-			},
-			Summary:            fetchedEntry.Description,
-			Notes:              fetchedEntry.Description,
-			Start:              fetchedEntry.Start,
-			BillableDuration:   billableDuration,
-			UnbillableDuration: unbillableDuration,
-		}
+		for _, timeEntry := range fetchedEntry.TimeEntries {
+			billableDuration := time.Second * time.Duration(timeEntry.Seconds)
+
+			// The running entries have no stop and a negative duration
+			end := timeEntry.Stop
+			running := end.IsZero() || timeEntry.Seconds < 0
 
-		if utils.IsRegexSet(opts.TagsAsTasksRegex) && len(fetchedEntry.Tags) > 0 {
-			var tags []worklog.IDNameField
-			for _, tag := range fetchedEntry.Tags {
-				tags = append(tags, worklog.IDNameField{
-					ID:   tag,
-					Name: tag,
-				})
+			if running {
+				var keep bool
+				var err error
+
+				if end, keep, err = opts.RunningEntryEnd(timeEntry.Start); err != nil {
+					return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+				} else if !keep {
+					continue
+				}
+
+				billableDuration = end.Sub(timeEntry.Start)
 			}
+			unbillableDuration := time.Duration(0)
 
-			splitEntries := entry.SplitByTagsAsTasks(entry.Summary, opts.TagsAsTasksRegex, tags)
-			entries = append(entries, splitEntries...)
-		} else {
-			entries = append(entries, entry)
+			if !fetchedEntry.Billable {
+				unbillableDuration = billableDuration
+				billableDuration = 0
+			}
+
+			entry := worklog.Entry{
+				Client:             idField(fetchedEntry.ClientID, fetchedEntry.ClientName),
+				Project:            idField(fetchedEntry.ProjectID, fetchedEntry.ProjectName),
+				Task:               idField(fetchedEntry.TaskID, fetchedEntry.TaskName),
+				Summary:            fetchedEntry.Description,
+				Notes:              fetchedEntry.Description,
+				Tags:               tags,
+				Start:              timeEntry.Start,
+				End:                end,
+				BillableDuration:   billableDuration,
+				UnbillableDuration: unbillableDuration,
+				Source:             SourceName,
+				SourceID:           strconv.Itoa(timeEntry.ID),
+				Running:            running,
+			}
+
+			if utils.IsRegexSet(opts.TagsAsTasksRegex) && len(tags) > 0 {
+				splitEntries := entry.SplitByTagsAsTasks(entry.Summary, opts.TagsAsTasksRegex, tags)
+				entries = append(entries, splitEntries...)
+			} else {
+				entries = append(entries, entry)
+			}
 		}
 	}
 
 	return entries, nil
 }
 
-func (c *togglClient) fetchEntries(ctx context.Context, reqURL string) (interface{}, *client.PaginatedFetchResponse, error) {
-	resp, err := c.Call(ctx, &client.HTTPRequestOpts{
-		Method:  http.MethodGet,
-		Url:     reqURL,
+// fetchEntries searches the entries page by page. The search endpoint accepts
+// the pagination in the request body, hence the pagination params set on the
+// URL are moved to the body.
+func (c *togglClient) fetchEntries(ctx context.Context, reqURL string, searchRequest SearchRequest) (interface{}, *client.PaginatedFetchResponse, error) {
+	pageURL, err := url.Parse(reqURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	query := pageURL.Query()
+
+	// The params are set by the pagination, and missing on the first page
+	searchRequest.PageSize, _ = strconv.Atoi(query.Get(ParamPageSize))
+	searchRequest.FirstRowNumber, _ = strconv.Atoi(query.Get(ParamFirstRowNumber))
+
+	query.Del(ParamPageSize)
+	query.Del(ParamFirstRowNumber)
+	pageURL.RawQuery = query.Encode()
+
+	resp, header, err := c.CallWithHeader(ctx, &client.HTTPRequestOpts{
+		Method:  http.MethodPost,
+		Url:     pageURL.String(),
+		Data:    searchRequest,
 		Auth:    c.authenticator,
 		Timeout: c.Timeout,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
 	})
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
 	}
 
-	var fetchResponse FetchResponse
-	if err = json.Unmarshal(resp, &fetchResponse); err != nil {
+	var fetchedEntries []FetchEntry
+	if err = json.Unmarshal(resp, &fetchedEntries); err != nil {
 		return nil, nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
 	}
 
 	paginatedResponse := &client.PaginatedFetchResponse{
-		EntriesPerPage: fetchResponse.PerPage,
-		TotalEntries:   fetchResponse.TotalCount,
+		PageEntries: len(fetchedEntries),
+		NextCursor:  header.Get(HeaderNextRowNumber),
 	}
 
-	return fetchResponse.Data, paginatedResponse, err
+	return fetchedEntries, paginatedResponse, err
 }
 
-func (c *togglClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
-	fetchURL, err := c.URL(PathWorklog, map[string]string{
-		"since":        utils.DateFormatISO8601.Format(opts.Start),
-		"until":        utils.DateFormatISO8601.Format(opts.End),
-		"user_id":      opts.User,
-		"workspace_id": strconv.Itoa(c.workspace),
-		"user_agent":   "github.com/gabor-boros/minutes",
-	})
+func (c *togglClient) FetchEntriesStream(ctx context.Context, opts *client.FetchOpts) <-chan client.FetchPage {
+	searchRequest := SearchRequest{
+		StartDate:      utils.DateFormatISO8601.FormatInLocation(opts.Start, c.Location()),
+		EndDate:        utils.DateFormatISO8601.FormatInLocation(opts.End, c.Location()),
+		EnrichResponse: true,
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	if opts.User != "" {
+		userID, err := strconv.Atoi(opts.User)
+		if err != nil {
+			return client.FailedFetchStream(fmt.Errorf("%v: %v", client.ErrFetchEntries, err))
+		}
+
+		searchRequest.UserIDs = []int{userID}
 	}
 
-	return c.PaginatedFetch(ctx, &client.PaginatedFetchOpts{
+	return c.PaginatedFetchStream(ctx, &client.PaginatedFetchOpts{
 		BaseFetchOpts: opts,
-		URL:           fetchURL,
-		FetchFunc:     c.fetchEntries,
-		ParseFunc:     c.parseEntries,
+		URL:           fmt.Sprintf(PathWorklog, c.workspace),
+		Pagination: &client.CursorPagination{
+			CursorParam:   ParamFirstRowNumber,
+			PageSizeParam: ParamPageSize,
+		},
+		FetchFunc: func(ctx context.Context, reqURL string) (interface{}, *client.PaginatedFetchResponse, error) {
+			return c.fetchEntries(ctx, reqURL, searchRequest)
+		},
+		ParseFunc: c.parseEntries,
 	})
 }
 
+func (c *togglClient) FetchEntries(ctx context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	return client.CollectFetchPages(ctx, c.FetchEntriesStream(ctx, opts))
+}
+
 // NewFetcher returns a new Toggl client for fetching entries.
 func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
 	baseURL, err := url.Parse(opts.BaseURL)
@@ -3,11 +3,10 @@ package toggl_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"regexp"
-	"strconv"
 	"testing"
 	"time"
 
@@ -19,20 +18,21 @@ import (
 )
 
 type mockServerOpts struct {
-	Path         string
-	QueryParams  url.Values
-	Method       string
-	StatusCode   int
-	Username     string
-	Password     string
-	ResponseData *toggl.FetchResponse
+	Path          string
+	Method        string
+	StatusCode    int
+	Username      string
+	Password      string
+	RequestData   *toggl.SearchRequest
+	ResponseData  []toggl.FetchEntry
+	NextRowNumber string
 }
 
 func mockServer(t *testing.T, e *mockServerOpts) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		require.Equal(t, e.Method, r.Method, "API call methods are not matching")
 		require.Equal(t, e.Path, r.URL.Path, "API call URLs are not matching")
-		require.Equal(t, e.QueryParams, r.URL.Query())
+		require.Empty(t, r.URL.Query(), "pagination params are not moved to the body")
 
 		if e.Username != "" && e.Password != "" {
 			username, password, _ := r.BasicAuth()
@@ -40,12 +40,22 @@ func mockServer(t *testing.T, e *mockServerOpts) *httptest.Server {
 			require.Equal(t, e.Password, password, "API call basic auth password mismatch")
 		}
 
+		if e.RequestData != nil {
+			var searchRequest toggl.SearchRequest
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&searchRequest), "cannot decode request data")
+			require.Equal(t, *e.RequestData, searchRequest, "API call request data mismatch")
+		}
+
+		if e.NextRowNumber != "" {
+			w.Header().Set(toggl.HeaderNextRowNumber, e.NextRowNumber)
+		}
+
+		w.WriteHeader(e.StatusCode)
+
 		if e.ResponseData != nil {
 			err := json.NewEncoder(w).Encode(e.ResponseData)
 			require.Nil(t, err, "cannot encode response data")
 		}
-
-		w.WriteHeader(e.StatusCode)
 	}))
 }
 
@@ -55,6 +65,51 @@ func newMockServer(t *testing.T, opts *mockServerOpts) *httptest.Server {
 	return mockServer
 }
 
+func getTestFetchEntries(start time.Time) []toggl.FetchEntry {
+	return []toggl.FetchEntry{
+		{
+			UserID:      987654321,
+			ClientID:    321,
+			ClientName:  "My Awesome Company",
+			ProjectID:   456,
+			ProjectName: "MARVEL",
+			TaskID:      789,
+			TaskName:    "CPT-2014",
+			Billable:    true,
+			Description: "I met with The Winter Soldier",
+			RowNumber:   1,
+			TimeEntries: []toggl.FetchTimeEntry{
+				{
+					ID:      1,
+					Seconds: 3600,
+					Start:   start,
+					Stop:    start.Add(time.Hour),
+				},
+			},
+		},
+		{
+			UserID:      987654321,
+			ClientID:    321,
+			ClientName:  "My Awesome Company",
+			ProjectID:   456,
+			ProjectName: "MARVEL",
+			TaskID:      789,
+			TaskName:    "CPT-2014",
+			Billable:    false,
+			Description: "I helped him to get back on track",
+			RowNumber:   2,
+			TimeEntries: []toggl.FetchTimeEntry{
+				{
+					ID:      2,
+					Seconds: 3600,
+					Start:   start,
+					Stop:    start.Add(time.Hour),
+				},
+			},
+		},
+	}
+}
+
 func TestTogglClient_FetchEntries(t *testing.T) {
 	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2021, 10, 2, 23, 59, 59, 0, time.UTC)
@@ -65,97 +120,71 @@ func TestTogglClient_FetchEntries(t *testing.T) {
 	expectedEntries := worklog.Entries{
 		{
 			Client: worklog.IDNameField{
-				ID:   "My Awesome Company",
+				ID:   "321",
 				Name: "My Awesome Company",
 			},
 			Project: worklog.IDNameField{
-				ID:   strconv.Itoa(456),
+				ID:   "456",
 				Name: "MARVEL",
 			},
 			Task: worklog.IDNameField{
-				ID:   strconv.Itoa(789),
+				ID:   "789",
 				Name: "CPT-2014",
 			},
 			Summary:            "I met with The Winter Soldier",
 			Notes:              "I met with The Winter Soldier",
 			Start:              start,
+			End:                start.Add(time.Hour),
 			BillableDuration:   time.Second * 3600,
 			UnbillableDuration: 0,
+			Source:             toggl.SourceName,
+			SourceID:           "1",
 		},
 		{
 			Client: worklog.IDNameField{
-				ID:   "My Awesome Company",
+				ID:   "321",
 				Name: "My Awesome Company",
 			},
 			Project: worklog.IDNameField{
-				ID:   strconv.Itoa(456),
+				ID:   "456",
 				Name: "MARVEL",
 			},
 			Task: worklog.IDNameField{
-				ID:   strconv.Itoa(789),
+				ID:   "789",
 				Name: "CPT-2014",
 			},
 			Summary:            "I helped him to get back on track",
 			Notes:              "I helped him to get back on track",
 			Start:              start,
+			End:                start.Add(time.Hour),
 			BillableDuration:   0,
 			UnbillableDuration: time.Second * 3600,
+			Source:             toggl.SourceName,
+			SourceID:           "2",
 		},
 	}
 
 	mockServer := newMockServer(t, &mockServerOpts{
-		Path: toggl.PathWorklog,
-		QueryParams: url.Values{
-			"page":         {"1"},
-			"per_page":     {"50"},
-			"since":        {utils.DateFormatISO8601.Format(start)},
-			"until":        {utils.DateFormatISO8601.Format(end)},
-			"user_id":      {"987654321"},
-			"workspace_id": {"123456789"},
-			"user_agent":   {"github.com/gabor-boros/minutes"},
-		},
-		Method:     http.MethodGet,
+		Path:       fmt.Sprintf(toggl.PathWorklog, 123456789),
+		Method:     http.MethodPost,
 		StatusCode: http.StatusOK,
 		Username:   clientUsername,
 		Password:   clientPassword,
-		ResponseData: &toggl.FetchResponse{
-			TotalCount: 2,
-			PerPage:    50,
-			Data: []toggl.FetchEntry{
-				{
-					Client:      "My Awesome Company",
-					Description: "I met with The Winter Soldier",
-					Duration:    3600000,
-					IsBillable:  true,
-					Project:     "MARVEL",
-					ProjectID:   456,
-					Start:       start,
-					End:         start.Add(3600000),
-					Tags:        nil,
-					Task:        "CPT-2014",
-					TaskID:      789,
-				},
-				{
-					Client:      "My Awesome Company",
-					Description: "I helped him to get back on track",
-					Duration:    3600000,
-					IsBillable:  false,
-					Project:     "MARVEL",
-					ProjectID:   456,
-					Start:       start,
-					End:         start.Add(3600000),
-					Tags:        nil,
-					Task:        "CPT-2014",
-					TaskID:      789,
-				},
-			},
+		RequestData: &toggl.SearchRequest{
+			StartDate:      utils.DateFormatISO8601.Format(start),
+			EndDate:        utils.DateFormatISO8601.Format(end),
+			UserIDs:        []int{987654321},
+			PageSize:       client.DefaultPageSize,
+			EnrichResponse: true,
 		},
+		ResponseData: getTestFetchEntries(start),
 	})
 	defer mockServer.Close()
 
 	togglClient, err := toggl.NewFetcher(&toggl.ClientOpts{
 		BaseClientOpts: client.BaseClientOpts{
-			Timeout: client.DefaultRequestTimeout,
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
 		},
 		BasicAuth: client.BasicAuth{
 			Username: clientUsername,
@@ -176,6 +205,133 @@ func TestTogglClient_FetchEntries(t *testing.T) {
 	require.ElementsMatch(t, expectedEntries, entries, "fetched entries are not matching")
 }
 
+func TestTogglClient_FetchEntries_Pagination(t *testing.T) {
+	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 2, 23, 59, 59, 0, time.UTC)
+	fetchEntries := getTestFetchEntries(start)
+
+	var requests []toggl.SearchRequest
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var searchRequest toggl.SearchRequest
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&searchRequest), "cannot decode request data")
+		requests = append(requests, searchRequest)
+
+		rows := fetchEntries[:1]
+		if searchRequest.FirstRowNumber == 0 {
+			w.Header().Set(toggl.HeaderNextRowNumber, "2")
+		} else {
+			rows = fetchEntries[1:]
+		}
+
+		require.Nil(t, json.NewEncoder(w).Encode(rows), "cannot encode response data")
+	}))
+	defer mockServer.Close()
+
+	togglClient, err := toggl.NewFetcher(&toggl.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout:  client.DefaultRequestTimeout,
+			Timezone: time.UTC,
+		},
+		BasicAuth: client.BasicAuth{
+			Username: "token-of-the-day",
+			Password: "api_token",
+		},
+		BaseURL:   mockServer.URL,
+		Workspace: 123456789,
+	})
+	require.Nil(t, err)
+
+	entries, err := togglClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: start,
+		End:   end,
+	})
+
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, 2)
+	require.Equal(t, "1", entries[0].SourceID)
+	require.Equal(t, "2", entries[1].SourceID)
+
+	require.Len(t, requests, 2)
+	require.Nil(t, requests[0].UserIDs)
+	require.Equal(t, 0, requests[0].FirstRowNumber)
+	require.Equal(t, 2, requests[1].FirstRowNumber)
+	require.Equal(t, requests[0].PageSize, requests[1].PageSize)
+}
+
+func TestTogglClient_FetchEntries_MissingIDs(t *testing.T) {
+	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 2, 23, 59, 59, 0, time.UTC)
+
+	mockServer := newMockServer(t, &mockServerOpts{
+		Path:       fmt.Sprintf(toggl.PathWorklog, 123456789),
+		Method:     http.MethodPost,
+		StatusCode: http.StatusOK,
+		ResponseData: []toggl.FetchEntry{
+			{
+				Description: "I met with The Winter Soldier",
+				TimeEntries: []toggl.FetchTimeEntry{
+					{
+						ID:      1,
+						Seconds: 3600,
+						Start:   start,
+						Stop:    start.Add(time.Hour),
+					},
+				},
+			},
+		},
+	})
+	defer mockServer.Close()
+
+	togglClient, err := toggl.NewFetcher(&toggl.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout: client.DefaultRequestTimeout,
+		},
+		BasicAuth: client.BasicAuth{
+			Username: "token-of-the-day",
+			Password: "api_token",
+		},
+		BaseURL:   mockServer.URL,
+		Workspace: 123456789,
+	})
+	require.Nil(t, err)
+
+	entries, err := togglClient.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: start,
+		End:   end,
+	})
+
+	require.Nil(t, err, "cannot fetch entries")
+	require.Len(t, entries, 1)
+	require.Equal(t, worklog.IDNameField{}, entries[0].Client)
+	require.Equal(t, worklog.IDNameField{}, entries[0].Project)
+	require.Equal(t, worklog.IDNameField{}, entries[0].Task)
+}
+
+func TestTogglClient_FetchEntries_InvalidUser(t *testing.T) {
+	togglClient, err := toggl.NewFetcher(&toggl.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timeout: client.DefaultRequestTimeout,
+		},
+		BasicAuth: client.BasicAuth{
+			Username: "token-of-the-day",
+			Password: "api_token",
+		},
+		BaseURL:   "https://api.track.toggl.com",
+		Workspace: 123456789,
+	})
+	require.Nil(t, err)
+
+	entries, err := togglClient.FetchEntries(context.Background(), &client.FetchOpts{
+		User:  "steve-rogers",
+		Start: time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 2, 23, 59, 59, 0, time.UTC),
+	})
+
+	require.Nil(t, entries)
+	require.ErrorContains(t, err, client.ErrFetchEntries.Error())
+}
+
 func TestTogglClient_FetchEntries_TagsAsTasks(t *testing.T) {
 	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2021, 10, 2, 23, 59, 59, 0, time.UTC)
@@ -183,115 +339,111 @@ func TestTogglClient_FetchEntries_TagsAsTasks(t *testing.T) {
 	clientUsername := "token-of-the-day"
 	clientPassword := "api_token"
 
+	tags := []worklog.IDNameField{
+		{
+			ID:   "11",
+			Name: "CPT-2014",
+		},
+		{
+			ID:   "12",
+			Name: "CPT-MISC",
+		},
+		{
+			ID:   "13",
+			Name: "IGNORED",
+		},
+	}
+
 	expectedEntries := worklog.Entries{
 		{
 			Client: worklog.IDNameField{
-				ID:   "My Awesome Company",
+				ID:   "321",
 				Name: "My Awesome Company",
 			},
 			Project: worklog.IDNameField{
-				ID:   strconv.Itoa(456),
+				ID:   "456",
 				Name: "MARVEL",
 			},
 			Task: worklog.IDNameField{
-				ID:   "CPT-2014",
+				ID:   "11",
 				Name: "CPT-2014",
 			},
 			Summary:            "I met with The Winter Soldier",
 			Notes:              "I met with The Winter Soldier",
+			Tags:               tags[:1],
 			Start:              start,
+			End:                start.Add(time.Hour),
 			BillableDuration:   time.Second * 3600,
 			UnbillableDuration: 0,
+			Source:             toggl.SourceName,
+			SourceID:           "1",
 		},
 		{
 			Client: worklog.IDNameField{
-				ID:   "My Awesome Company",
+				ID:   "321",
 				Name: "My Awesome Company",
 			},
 			Project: worklog.IDNameField{
-				ID:   strconv.Itoa(456),
+				ID:   "456",
 				Name: "MARVEL",
 			},
 			Task: worklog.IDNameField{
-				ID:   "CPT-2014",
+				ID:   "11",
 				Name: "CPT-2014",
 			},
 			Summary:            "I helped him to get back on track",
 			Notes:              "I helped him to get back on track",
+			Tags:               tags,
 			Start:              start,
+			End:                start.Add(time.Hour),
 			BillableDuration:   0,
 			UnbillableDuration: time.Second * 1800,
+			Source:             toggl.SourceName,
+			SourceID:           "2",
 		},
 		{
 			Client: worklog.IDNameField{
-				ID:   "My Awesome Company",
+				ID:   "321",
 				Name: "My Awesome Company",
 			},
 			Project: worklog.IDNameField{
-				ID:   strconv.Itoa(456),
+				ID:   "456",
 				Name: "MARVEL",
 			},
 			Task: worklog.IDNameField{
-				ID:   "CPT-MISC",
+				ID:   "12",
 				Name: "CPT-MISC",
 			},
 			Summary:            "I helped him to get back on track",
 			Notes:              "I helped him to get back on track",
+			Tags:               tags,
 			Start:              start,
+			End:                start.Add(time.Hour),
 			BillableDuration:   0,
 			UnbillableDuration: time.Second * 1800,
+			Source:             toggl.SourceName,
+			SourceID:           "2",
 		},
 	}
 
+	fetchEntries := getTestFetchEntries(start)
+	for i := range fetchEntries {
+		fetchEntries[i].TaskID = 0
+		fetchEntries[i].TaskName = ""
+	}
+
+	fetchEntries[0].TagIDs = []int{11}
+	fetchEntries[0].TagNames = []string{"CPT-2014"}
+	fetchEntries[1].TagIDs = []int{11, 12, 13}
+	fetchEntries[1].TagNames = []string{"CPT-2014", "CPT-MISC", "IGNORED"}
+
 	mockServer := newMockServer(t, &mockServerOpts{
-		Path: toggl.PathWorklog,
-		QueryParams: url.Values{
-			"page":         {"1"},
-			"per_page":     {"50"},
-			"since":        {utils.DateFormatISO8601.Format(start)},
-			"until":        {utils.DateFormatISO8601.Format(end)},
-			"user_id":      {"987654321"},
-			"workspace_id": {"123456789"},
-			"user_agent":   {"github.com/gabor-boros/minutes"},
-		},
-		Method:     http.MethodGet,
-		StatusCode: http.StatusOK,
-		Username:   clientUsername,
-		Password:   clientPassword,
-		ResponseData: &toggl.FetchResponse{
-			TotalCount: 2,
-			PerPage:    50,
-			Data: []toggl.FetchEntry{
-				{
-					Client:      "My Awesome Company",
-					Description: "I met with The Winter Soldier",
-					Duration:    3600000,
-					IsBillable:  true,
-					Project:     "MARVEL",
-					ProjectID:   456,
-					Start:       start,
-					End:         start.Add(3600000),
-					Tags: []string{
-						"CPT-2014",
-					},
-				},
-				{
-					Client:      "My Awesome Company",
-					Description: "I helped him to get back on track",
-					Duration:    3600000,
-					IsBillable:  false,
-					Project:     "MARVEL",
-					ProjectID:   456,
-					Start:       start,
-					End:         start.Add(3600000),
-					Tags: []string{
-						"CPT-2014",
-						"CPT-MISC",
-						"IGNORED",
-					},
-				},
-			},
-		},
+		Path:         fmt.Sprintf(toggl.PathWorklog, 123456789),
+		Method:       http.MethodPost,
+		StatusCode:   http.StatusOK,
+		Username:     clientUsername,
+		Password:     clientPassword,
+		ResponseData: fetchEntries,
 	})
 	defer mockServer.Close()
 
@@ -1,16 +1,31 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/gabor-boros/minutes/internal/pkg/worklog"
 	"github.com/jedib0t/go-pretty/v6/progress"
 )
 
+const (
+	// DefaultCommentTemplate is the template used to render the comment or
+	// description of the uploaded entries if no template is set.
+	DefaultCommentTemplate string = "{{.Summary}}"
+)
+
 var (
 	// ErrUploadEntries wraps the error when upload failed.
 	ErrUploadEntries = errors.New("failed to upload entries")
+	// ErrInvalidCommentTemplate wraps the error when the comment template
+	// cannot be parsed.
+	ErrInvalidCommentTemplate = errors.New("invalid comment template")
 )
 
 // UploadOpts specifies the only options for the Uploader. In contrast to the
@@ -36,6 +51,31 @@ type UploadOpts struct {
 	// In case the ProgressWriter is nil, that means the upload progress should
 	// not be tracked, hence, that's not an error.
 	ProgressWriter progress.Writer
+	// CommentTemplate is used to render the comment or description of the
+	// uploaded entries. In case the CommentTemplate is nil, the summary of the
+	// entry will be used.
+	CommentTemplate *template.Template
+}
+
+// NewCommentTemplate parses the template used for rendering comments and
+// descriptions. The template receives the worklog.Entry, so it can reference
+// the client, project, task, tags, source, summary, and notes of the entry.
+// Besides the builtin functions, `join` is available to join string slices.
+// If the text is empty, the DefaultCommentTemplate will be used.
+func NewCommentTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultCommentTemplate
+	}
+
+	tmpl, err := template.New("comment").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(text)
+
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidCommentTemplate, err)
+	}
+
+	return tmpl, nil
 }
 
 // Uploader specifies the functions used to upload worklog entries.
@@ -67,6 +107,43 @@ func (u *DefaultUploader) StartTracking(entry worklog.Entry, writer progress.Wri
 	return tracker
 }
 
+// RenderComment renders the comment of the entry using the template. If the
+// template is nil, the summary of the entry returns. The leading and trailing
+// whitespaces are removed, so optional parts can be rendered on new lines.
+func (u *DefaultUploader) RenderComment(entry worklog.Entry, tmpl *template.Template) (string, error) {
+	if tmpl == nil {
+		return entry.Summary, nil
+	}
+
+	var comment bytes.Buffer
+	if err := tmpl.Execute(&comment, &entry); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(comment.String()), nil
+}
+
+// Durations returns the billable and unbillable duration of the entry to upload.
+// If every time spent is treated as billed, the unbillable duration is added
+// to the billable duration. If rounding is requested, the durations are
+// rounded separately to the closest minute.
+func (u *DefaultUploader) Durations(entry worklog.Entry, opts *UploadOpts) (time.Duration, time.Duration) {
+	billableDuration := entry.BillableDuration
+	unbillableDuration := entry.UnbillableDuration
+
+	if opts.TreatDurationAsBilled {
+		billableDuration = entry.UnbillableDuration + entry.BillableDuration
+		unbillableDuration = 0
+	}
+
+	if opts.RoundToClosestMinute {
+		billableDuration = time.Second * time.Duration(math.Round(billableDuration.Minutes())*60)
+		unbillableDuration = time.Second * time.Duration(math.Round(unbillableDuration.Minutes())*60)
+	}
+
+	return billableDuration, unbillableDuration
+}
+
 func (u *DefaultUploader) StopTracking(tracker *progress.Tracker, err error) {
 	if tracker == nil {
 		return
@@ -93,3 +93,52 @@ func TestDefaultUploader_StopTracking_NoTracker(t *testing.T) {
 
 	uploader.StopTracking(tracker, nil)
 }
+
+func TestNewCommentTemplate_Default(t *testing.T) {
+	entry := getTestEntry()
+
+	tmpl, err := client.NewCommentTemplate("")
+	require.Nil(t, err)
+
+	uploader := client.DefaultUploader{}
+	comment, err := uploader.RenderComment(entry, tmpl)
+	require.Nil(t, err)
+	require.Equal(t, entry.Summary, comment)
+}
+
+func TestNewCommentTemplate_Invalid(t *testing.T) {
+	_, err := client.NewCommentTemplate("{{.Summary")
+	require.ErrorContains(t, err, client.ErrInvalidCommentTemplate.Error())
+}
+
+func TestDefaultUploader_RenderComment(t *testing.T) {
+	entry := getTestEntry()
+	entry.Source = "toggl"
+	entry.Tags = []worklog.IDNameField{
+		{ID: "1", Name: "coffee"},
+		{ID: "2", Name: "meeting"},
+	}
+
+	tmpl, err := client.NewCommentTemplate(
+		"[{{.Client.Name}}/{{.Project.Name}}/{{.Task.Name}}] {{.Summary}}\n{{.Notes}}\n{{join .TagNames \", \"}} ({{.Source}})\n",
+	)
+	require.Nil(t, err)
+
+	uploader := client.DefaultUploader{}
+	comment, err := uploader.RenderComment(entry, tmpl)
+	require.Nil(t, err)
+	require.Equal(
+		t,
+		"[My Awesome Company/Internal projects/TASK-0123] Write worklog transfer CLI tool\nIt is a lot easier than expected\ncoffee, meeting (toggl)",
+		comment,
+	)
+}
+
+func TestDefaultUploader_RenderComment_NoTemplate(t *testing.T) {
+	entry := getTestEntry()
+
+	uploader := client.DefaultUploader{}
+	comment, err := uploader.RenderComment(entry, nil)
+	require.Nil(t, err)
+	require.Equal(t, entry.Summary, comment)
+}
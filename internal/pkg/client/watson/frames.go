@@ -0,0 +1,193 @@
+package watson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// EnvDir is the environment variable used by Watson to override the
+	// location of its files.
+	EnvDir string = "WATSON_DIR"
+	// FramesFile is the name of the file storing the finished frames.
+	FramesFile string = "frames"
+	// StateFile is the name of the file storing the running frame.
+	StateFile string = "state"
+	// RunningFrameIDPrefix is the prefix of the ID given to the running frame,
+	// which is suffixed with the Unix timestamp of its start.
+	RunningFrameIDPrefix string = "running:"
+)
+
+var (
+	// ErrInvalidFrame returns when a frame cannot be parsed.
+	ErrInvalidFrame = errors.New("invalid frame")
+)
+
+// DefaultDir returns the directory of the Watson files. The location is read
+// from the WATSON_DIR environment variable, and defaults to the "watson"
+// directory in the configuration directory of the user, like ~/.config/watson.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "watson"), nil
+}
+
+// Frame represents a frame tracked by Watson. The running frame has no ID in
+// Watson, so it gets a synthetic ID derived from its start, and its Stop is
+// zero.
+type Frame struct {
+	ID        string
+	Project   string
+	Tags      []string
+	Start     time.Time
+	Stop      time.Time
+	UpdatedAt time.Time
+}
+
+// parseTimestamp parses the Unix timestamp stored by Watson. The timestamps
+// are integers, though floats are accepted as well.
+func parseTimestamp(data json.RawMessage) (time.Time, error) {
+	var timestamp float64
+	if err := json.Unmarshal(data, &timestamp); err != nil {
+		return time.Time{}, err
+	}
+
+	seconds, fraction := math.Modf(timestamp)
+	return time.Unix(int64(seconds), int64(fraction*float64(time.Second))), nil
+}
+
+// UnmarshalJSON parses the frame stored as an array of
+// `[start, stop, project, id, tags, updated_at]`.
+func (f *Frame) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidFrame, err)
+	}
+
+	if len(fields) < 5 {
+		return fmt.Errorf("%v: %s", ErrInvalidFrame, data)
+	}
+
+	var err error
+	if f.Start, err = parseTimestamp(fields[0]); err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidFrame, err)
+	}
+
+	if f.Stop, err = parseTimestamp(fields[1]); err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidFrame, err)
+	}
+
+	if err = json.Unmarshal(fields[2], &f.Project); err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidFrame, err)
+	}
+
+	if err = json.Unmarshal(fields[3], &f.ID); err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidFrame, err)
+	}
+
+	if err = json.Unmarshal(fields[4], &f.Tags); err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidFrame, err)
+	}
+
+	// Frames written by old versions of Watson have no update date
+	if len(fields) > 5 {
+		if f.UpdatedAt, err = parseTimestamp(fields[5]); err != nil {
+			return fmt.Errorf("%v: %v", ErrInvalidFrame, err)
+		}
+	}
+
+	return nil
+}
+
+// runningState represents the state file of Watson. The file holds an empty
+// object if no frame is running.
+type runningState struct {
+	Project string           `json:"project"`
+	Start   *json.RawMessage `json:"start"`
+	Tags    []string         `json:"tags"`
+}
+
+// ReadFrames returns the frames stored in the directory that overlap the given
+// date range. If a frame is running, it is returned as well. Missing files are
+// treated as they would be empty, the same way as Watson does.
+func ReadFrames(dir string, start time.Time, end time.Time) ([]Frame, error) {
+	var frames []Frame
+
+	data, err := os.ReadFile(filepath.Clean(filepath.Join(dir, FramesFile)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &frames); err != nil {
+			return nil, fmt.Errorf("%s: %v", FramesFile, err)
+		}
+	}
+
+	running, err := readRunningFrame(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if running != nil {
+		frames = append(frames, *running)
+	}
+
+	var overlapping []Frame
+	for _, frame := range frames {
+		frameEnd := frame.Stop
+		if frameEnd.IsZero() {
+			frameEnd = time.Now()
+		}
+
+		if frame.Start.Before(end) && frameEnd.After(start) {
+			overlapping = append(overlapping, frame)
+		}
+	}
+
+	return overlapping, nil
+}
+
+// readRunningFrame returns the running frame stored in the state file of the
+// directory. If no frame is running, nil returns.
+func readRunningFrame(dir string) (*Frame, error) {
+	data, err := os.ReadFile(filepath.Clean(filepath.Join(dir, StateFile)))
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var state runningState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %v", StateFile, err)
+	}
+
+	if state.Start == nil {
+		return nil, nil
+	}
+
+	startDate, err := parseTimestamp(*state.Start)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v: %v", StateFile, ErrInvalidFrame, err)
+	}
+
+	return &Frame{
+		ID:      fmt.Sprintf("%s%d", RunningFrameIDPrefix, startDate.Unix()),
+		Project: state.Project,
+		Tags:    state.Tags,
+		Start:   startDate,
+	}, nil
+}
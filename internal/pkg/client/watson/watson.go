@@ -0,0 +1,145 @@
+package watson
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+)
+
+const (
+	// SourceName is the name of the source set on the fetched entries.
+	SourceName string = "watson"
+)
+
+// ClientOpts is the client specific options, extending client.BaseClientOpts.
+// Watson is a CLI tool without an export command, therefore the frames are
+// read from the files stored in the Dir directly, and the CLI is not used.
+type ClientOpts struct {
+	client.BaseClientOpts
+	Dir            string
+	UnbillableTag  string
+	ClientTagRegex string
+}
+
+type watsonClient struct {
+	*client.BaseClientOpts
+	dir            string
+	unbillableTag  string
+	clientTagRegex *regexp.Regexp
+}
+
+func (c *watsonClient) parseFrame(frame Frame, opts *client.FetchOpts) (worklog.Entries, error) {
+	startDate := frame.Start.In(c.Location())
+	endDate := frame.Stop.In(c.Location())
+
+	running := frame.Stop.IsZero()
+	if running {
+		var keep bool
+		var err error
+		if endDate, keep, err = opts.RunningEntryEnd(startDate); err != nil || !keep {
+			return nil, err
+		}
+	}
+
+	var tags []worklog.IDNameField
+	for _, tag := range frame.Tags {
+		tags = append(tags, worklog.IDNameField{
+			ID:   tag,
+			Name: tag,
+		})
+	}
+
+	// Frames have no description, so the project is the best summary
+	worklogEntry := worklog.Entry{
+		Project: worklog.IDNameField{
+			ID:   frame.Project,
+			Name: frame.Project,
+		},
+		Summary:            frame.Project,
+		Notes:              frame.Project,
+		Tags:               tags,
+		Start:              startDate,
+		End:                endDate,
+		BillableDuration:   endDate.Sub(startDate),
+		UnbillableDuration: 0,
+		Source:             SourceName,
+		SourceID:           frame.ID,
+		Running:            running,
+	}
+
+	for _, tag := range frame.Tags {
+		if tag == c.unbillableTag {
+			worklogEntry.UnbillableDuration = worklogEntry.BillableDuration
+			worklogEntry.BillableDuration = 0
+		} else if utils.IsRegexSet(c.clientTagRegex) && c.clientTagRegex.MatchString(tag) {
+			worklogEntry.Client = worklog.IDNameField{
+				ID:   tag,
+				Name: tag,
+			}
+		} else if utils.IsRegexSet(opts.TagsAsTasksRegex) && opts.TagsAsTasksRegex.MatchString(tag) {
+			worklogEntry.Task = worklog.IDNameField{
+				ID:   tag,
+				Name: tag,
+			}
+		}
+	}
+
+	// If the task was not found in tags, make sure to set it to the project
+	if !worklogEntry.Task.IsComplete() {
+		worklogEntry.Task = worklog.IDNameField{
+			ID:   frame.Project,
+			Name: frame.Project,
+		}
+	}
+
+	if utils.IsRegexSet(opts.TagsAsTasksRegex) && len(tags) > 0 {
+		return worklogEntry.SplitByTagsAsTasks(worklogEntry.Summary, opts.TagsAsTasksRegex, tags), nil
+	}
+
+	return worklog.Entries{worklogEntry}, nil
+}
+
+func (c *watsonClient) FetchEntries(_ context.Context, opts *client.FetchOpts) (worklog.Entries, error) {
+	frames, err := ReadFrames(c.dir, opts.Start, opts.End)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	var entries worklog.Entries
+	for _, frame := range frames {
+		parsedEntries, err := c.parseFrame(frame, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+
+		entries = append(entries, parsedEntries...)
+	}
+
+	return entries, nil
+}
+
+// NewFetcher returns a new Watson client for fetching entries.
+func NewFetcher(opts *ClientOpts) (client.Fetcher, error) {
+	clientTagRegex, err := regexp.Compile(opts.ClientTagRegex)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		if dir, err = DefaultDir(); err != nil {
+			return nil, fmt.Errorf("%v: %v", client.ErrFetchEntries, err)
+		}
+	}
+
+	return &watsonClient{
+		BaseClientOpts: &opts.BaseClientOpts,
+		dir:            dir,
+		unbillableTag:  opts.UnbillableTag,
+		clientTagRegex: clientTagRegex,
+	}, nil
+}
@@ -0,0 +1,227 @@
+package watson_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/client"
+	"github.com/gabor-boros/minutes/internal/pkg/client/watson"
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		require.Nil(t, err)
+	}
+
+	return dir
+}
+
+func newFetcher(t *testing.T, dir string) client.Fetcher {
+	fetcher, err := watson.NewFetcher(&watson.ClientOpts{
+		BaseClientOpts: client.BaseClientOpts{
+			Timezone: time.UTC,
+		},
+		Dir:            dir,
+		UnbillableTag:  "unbillable",
+		ClientTagRegex: "^(oc)$",
+	})
+	require.Nil(t, err)
+
+	return fetcher
+}
+
+func TestReadFrames(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		watson.FramesFile: `[
+			[1634014800, 1634018400, "minutes", "a1", ["TASK-1"], 1634018400],
+			[1634022000, 1634025600.5, "minutes", "b2", [], 1634025600],
+			[1634104800, 1634108400, "minutes", "c3", []]
+		]`,
+		watson.StateFile: `{"project": "website", "start": 1634029200, "tags": ["oc"]}`,
+	})
+
+	frames, err := watson.ReadFrames(
+		dir,
+		time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+	)
+	require.Nil(t, err)
+
+	require.Len(t, frames, 3)
+	require.Equal(t, "a1", frames[0].ID)
+	require.Equal(t, []string{"TASK-1"}, frames[0].Tags)
+	require.Equal(t, time.Unix(1634014800, 0), frames[0].Start)
+	require.Equal(t, time.Unix(1634025600, int64(500*time.Millisecond)), frames[1].Stop)
+	require.Equal(t, watson.Frame{
+		ID:      "running:1634029200",
+		Project: "website",
+		Tags:    []string{"oc"},
+		Start:   time.Unix(1634029200, 0),
+	}, frames[2])
+}
+
+func TestReadFrames_Missing(t *testing.T) {
+	frames, err := watson.ReadFrames(t.TempDir(), time.Now().Add(-time.Hour), time.Now())
+	require.Nil(t, err)
+	require.Empty(t, frames)
+}
+
+func TestReadFrames_Invalid(t *testing.T) {
+	for _, content := range []string{
+		`[[1634014800, 1634018400, "minutes"]]`,
+		`[["2021-10-12", 1634018400, "minutes", "a1", []]]`,
+		`[[1634014800, 1634018400, "minutes", "a1", "TASK-1"]]`,
+	} {
+		t.Run(content, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{watson.FramesFile: content})
+			_, err := watson.ReadFrames(dir, time.Unix(0, 0), time.Now())
+			require.ErrorContains(t, err, watson.ErrInvalidFrame.Error())
+		})
+	}
+}
+
+func TestWatsonClient_FetchEntries(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		watson.FramesFile: `[
+			[1634014800, 1634018400, "minutes", "a1", ["oc", "TASK-1", "TASK-2"], 1634018400]
+		]`,
+		watson.StateFile: `{}`,
+	})
+
+	fetcher := newFetcher(t, dir)
+	entries, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start:            time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:              time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+		TagsAsTasksRegex: regexp.MustCompile(`^TASK-\d+$`),
+	})
+	require.Nil(t, err)
+
+	project := worklog.IDNameField{ID: "minutes", Name: "minutes"}
+	tags := []worklog.IDNameField{
+		{ID: "oc", Name: "oc"},
+		{ID: "TASK-1", Name: "TASK-1"},
+		{ID: "TASK-2", Name: "TASK-2"},
+	}
+
+	require.Equal(t, worklog.Entries{
+		{
+			Client:           worklog.IDNameField{ID: "oc", Name: "oc"},
+			Project:          project,
+			Task:             worklog.IDNameField{ID: "TASK-1", Name: "TASK-1"},
+			Summary:          "minutes",
+			Notes:            "minutes",
+			Tags:             tags,
+			Start:            time.Date(2021, 10, 12, 5, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 6, 0, 0, 0, time.UTC),
+			BillableDuration: 30 * time.Minute,
+			Source:           watson.SourceName,
+			SourceID:         "a1",
+		},
+		{
+			Client:           worklog.IDNameField{ID: "oc", Name: "oc"},
+			Project:          project,
+			Task:             worklog.IDNameField{ID: "TASK-2", Name: "TASK-2"},
+			Summary:          "minutes",
+			Notes:            "minutes",
+			Tags:             tags,
+			Start:            time.Date(2021, 10, 12, 5, 0, 0, 0, time.UTC),
+			End:              time.Date(2021, 10, 12, 6, 0, 0, 0, time.UTC),
+			BillableDuration: 30 * time.Minute,
+			Source:           watson.SourceName,
+			SourceID:         "a1",
+		},
+	}, entries)
+}
+
+func TestWatsonClient_FetchEntries_Unbillable(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		watson.FramesFile: `[[1634022000, 1634025600, "minutes", "b2", ["unbillable"], 1634025600]]`,
+	})
+
+	fetcher := newFetcher(t, dir)
+	entries, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start: time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 10, 13, 0, 0, 0, 0, time.UTC),
+	})
+	require.Nil(t, err)
+
+	require.Equal(t, worklog.Entries{
+		{
+			Project:            worklog.IDNameField{ID: "minutes", Name: "minutes"},
+			Task:               worklog.IDNameField{ID: "minutes", Name: "minutes"},
+			Summary:            "minutes",
+			Notes:              "minutes",
+			Tags:               []worklog.IDNameField{{ID: "unbillable", Name: "unbillable"}},
+			Start:              time.Date(2021, 10, 12, 7, 0, 0, 0, time.UTC),
+			End:                time.Date(2021, 10, 12, 8, 0, 0, 0, time.UTC),
+			UnbillableDuration: time.Hour,
+			Source:             watson.SourceName,
+			SourceID:           "b2",
+		},
+	}, entries)
+}
+
+func TestWatsonClient_FetchEntries_Running(t *testing.T) {
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	dir := writeFiles(t, map[string]string{
+		watson.StateFile: `{"project": "minutes", "start": ` + strconv.FormatInt(start.Unix(), 10) + `, "tags": []}`,
+	})
+
+	fetcher := newFetcher(t, dir)
+	fetchOpts := &client.FetchOpts{
+		Start: start.Add(-time.Hour),
+		End:   start.Add(2 * time.Hour),
+	}
+
+	entries, err := fetcher.FetchEntries(context.Background(), fetchOpts)
+	require.Nil(t, err)
+	require.Empty(t, entries)
+
+	fetchOpts.RunningEntries = client.RunningEntriesUntilNow
+	entries, err = fetcher.FetchEntries(context.Background(), fetchOpts)
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].Running)
+	require.Equal(t, start.UTC(), entries[0].Start)
+	require.GreaterOrEqual(t, entries[0].BillableDuration, time.Hour)
+	require.Equal(t, watson.RunningFrameIDPrefix+strconv.FormatInt(start.Unix(), 10), entries[0].SourceID)
+
+	fetchOpts.RunningEntries = client.RunningEntriesError
+	_, err = fetcher.FetchEntries(context.Background(), fetchOpts)
+	require.ErrorContains(t, err, client.ErrRunningEntry.Error())
+}
+
+func TestWatsonClient_FetchEntries_RunningSplitByTags(t *testing.T) {
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	dir := writeFiles(t, map[string]string{
+		watson.StateFile: `{"project": "minutes", "start": ` + strconv.FormatInt(start.Unix(), 10) + `, "tags": ["ABC-1", "ABC-2"]}`,
+	})
+
+	fetcher := newFetcher(t, dir)
+	entries, err := fetcher.FetchEntries(context.Background(), &client.FetchOpts{
+		Start:            start.Add(-time.Hour),
+		End:              start.Add(2 * time.Hour),
+		TagsAsTasksRegex: regexp.MustCompile(`^ABC-\d+$`),
+		RunningEntries:   client.RunningEntriesUntilNow,
+	})
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+
+	// The parts of the running frame are the same record, so they are not
+	// reported as overlapping
+	require.NotEmpty(t, entries[0].SourceID)
+	require.True(t, entries[0].IsSameRecord(&entries[1]))
+
+	analysis := worklog.Analyze(entries, &worklog.AnalyzeOpts{})
+	require.False(t, analysis.HasOverlaps())
+}
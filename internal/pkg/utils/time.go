@@ -1,6 +1,19 @@
 package utils
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidDuration returns when the duration cannot be parsed by
+	// ParseDuration.
+	ErrInvalidDuration = errors.New("invalid duration")
+)
 
 // DateFormat is the enumeration of available date formats, used by clients.
 // Although the builtin time package contains several formatting options, some
@@ -30,12 +43,79 @@ func (d DateFormat) String() string {
 	}[d]
 }
 
-// Format returns the formatted version of the given time.
+// isUTC returns true if the format has a literal "Z" suffix, hence the time
+// must be in UTC to be represented correctly.
+func (d DateFormat) isUTC() bool {
+	return d == DateFormatRFC3339UTC || d == DateFormatRFC3339Compact
+}
+
+// Format returns the formatted version of the given time in the time's own
+// location. The UTC formats are always converted to UTC first.
 func (d DateFormat) Format(t time.Time) string {
-	return t.Format(d.String())
+	return d.FormatInLocation(t, t.Location())
 }
 
-// Parse the given string with the specified layout.
+// FormatInLocation converts the given time to the location, and returns its
+// formatted version. The location is used by the formats that have no offset
+// information, like dates, while the UTC formats are always converted to UTC.
+func (d DateFormat) FormatInLocation(t time.Time, loc *time.Location) string {
+	if d.isUTC() {
+		loc = time.UTC
+	}
+
+	return t.In(loc).Format(d.String())
+}
+
+// Parse the given string with the specified layout in UTC.
 func (d DateFormat) Parse(s string) (time.Time, error) {
-	return time.Parse(d.String(), s)
+	return d.ParseInLocation(s, time.UTC)
+}
+
+// ParseInLocation parses the given string with the specified layout. The
+// location is used by the formats that have no offset information, while the
+// UTC formats are always parsed in UTC.
+func (d DateFormat) ParseInLocation(s string, loc *time.Location) (time.Time, error) {
+	if d.isUTC() {
+		loc = time.UTC
+	}
+
+	return time.ParseInLocation(d.String(), s, loc)
+}
+
+// ParseDuration parses the durations written by humans, like in spreadsheets.
+// It accepts Go durations like "1h30m", decimal hours like "1.5" or "1,5", and
+// clock durations like "01:30" or "1:30:00".
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	if strings.HasPrefix(s, "-") {
+		return 0, fmt.Errorf("%v: %s", ErrInvalidDuration, s)
+	}
+
+	if duration, err := time.ParseDuration(s); err == nil {
+		return duration, nil
+	}
+
+	if hours, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64); err == nil && !math.IsNaN(hours) && !math.IsInf(hours, 0) {
+		return time.Duration(hours * float64(time.Hour)).Round(time.Second), nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return 0, fmt.Errorf("%v: %s", ErrInvalidDuration, s)
+	}
+
+	var duration time.Duration
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 || (i > 0 && (value > 59 || len(part) != 2)) {
+			return 0, fmt.Errorf("%v: %s", ErrInvalidDuration, s)
+		}
+
+		duration += time.Duration(value) * units[i]
+	}
+
+	return duration, nil
 }
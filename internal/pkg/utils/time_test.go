@@ -0,0 +1,98 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	tokyo  = time.FixedZone("UTC+9", 9*60*60)
+	berlin = time.FixedZone("UTC+2", 2*60*60)
+)
+
+func TestDateFormat_Format(t *testing.T) {
+	// 2021-10-02 08:30 in Tokyo is 2021-10-01 23:30 in UTC
+	date := time.Date(2021, 10, 2, 8, 30, 0, 0, tokyo)
+
+	require.Equal(t, "2021-10-02", utils.DateFormatISO8601.Format(date))
+	require.Equal(t, "2021-10-01T23:30:00Z", utils.DateFormatRFC3339UTC.Format(date))
+	require.Equal(t, "20211001T233000Z", utils.DateFormatRFC3339Compact.Format(date))
+	require.Equal(t, "2021-10-02T08:30:00", utils.DateFormatRFC3339Local.Format(date))
+}
+
+func TestDateFormat_FormatInLocation(t *testing.T) {
+	// 2021-10-02 08:30 in Tokyo is 2021-10-02 01:30 in Berlin
+	date := time.Date(2021, 10, 2, 8, 30, 0, 0, tokyo)
+
+	require.Equal(t, "2021-10-02", utils.DateFormatISO8601.FormatInLocation(date, berlin))
+	require.Equal(t, "2021-10-01", utils.DateFormatISO8601.FormatInLocation(date, time.UTC))
+	require.Equal(t, "2021-10-01T23:30:00Z", utils.DateFormatRFC3339UTC.FormatInLocation(date, berlin))
+	require.Equal(t, "20211001T233000Z", utils.DateFormatRFC3339Compact.FormatInLocation(date, berlin))
+	require.Equal(t, "2021-10-02T01:30:00", utils.DateFormatRFC3339Local.FormatInLocation(date, berlin))
+}
+
+func TestDateFormat_Parse(t *testing.T) {
+	parsed, err := utils.DateFormatISO8601.Parse("2021-10-02")
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC), parsed)
+
+	parsed, err = utils.DateFormatRFC3339Compact.Parse("20211001T233000Z")
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 1, 23, 30, 0, 0, time.UTC), parsed)
+
+	_, err = utils.DateFormatISO8601.Parse("2021/10/02")
+	require.Error(t, err)
+}
+
+func TestDateFormat_ParseInLocation(t *testing.T) {
+	parsed, err := utils.DateFormatISO8601.ParseInLocation("2021-10-02", tokyo)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 2, 0, 0, 0, 0, tokyo), parsed)
+
+	parsed, err = utils.DateFormatRFC3339Local.ParseInLocation("2021-10-02T08:30:00", berlin)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 2, 8, 30, 0, 0, berlin), parsed)
+
+	parsed, err = utils.DateFormatRFC3339UTC.ParseInLocation("2021-10-01T23:30:00Z", tokyo)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 1, 23, 30, 0, 0, time.UTC), parsed)
+
+	parsed, err = utils.DateFormatRFC3339Compact.ParseInLocation("20211001T233000Z", tokyo)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 10, 1, 23, 30, 0, 0, time.UTC), parsed)
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"1h30m":   time.Hour + time.Minute*30,
+		"45m":     time.Minute * 45,
+		"1.5":     time.Hour + time.Minute*30,
+		"1,25":    time.Hour + time.Minute*15,
+		"2":       time.Hour * 2,
+		"0.1":     time.Minute * 6,
+		"01:30":   time.Hour + time.Minute*30,
+		"1:30:15": time.Hour + time.Minute*30 + time.Second*15,
+		"10:05":   time.Hour*10 + time.Minute*5,
+		" 0:45 ":  time.Minute * 45,
+	}
+
+	for raw, expected := range tests {
+		t.Run(raw, func(t *testing.T) {
+			duration, err := utils.ParseDuration(raw)
+			require.Nil(t, err)
+			require.Equal(t, expected, duration)
+		})
+	}
+}
+
+func TestParseDuration_Invalid(t *testing.T) {
+	for _, raw := range []string{"", "one hour", "1:3", "1:60", "1:30:15:00", "-1:30", "-1.5", "-1h", "1h 30m", "NaN", "Inf"} {
+		t.Run(raw, func(t *testing.T) {
+			_, err := utils.ParseDuration(raw)
+			require.ErrorContains(t, err, utils.ErrInvalidDuration.Error())
+		})
+	}
+}
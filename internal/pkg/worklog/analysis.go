@@ -0,0 +1,213 @@
+package worklog
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	analysisTimeFormat string = "15:04"
+)
+
+// AnalyzeOpts represents the options used to analyze the entries.
+type AnalyzeOpts struct {
+	// WorkdayStart is the offset from midnight when the working hours start.
+	WorkdayStart time.Duration
+	// WorkdayEnd is the offset from midnight when the working hours end.
+	WorkdayEnd time.Duration
+	// IdleGapThreshold sets the minimum length of an idle period within the
+	// working hours to be reported as a gap. If the threshold is 0 (zero), the
+	// gap detection is disabled.
+	IdleGapThreshold time.Duration
+	// Location sets the timezone of the working hours and the reported
+	// times. If not set, the local timezone is used.
+	Location *time.Location
+}
+
+// Overlap represents two entries that are overlapping in time.
+type Overlap struct {
+	Entry Entry
+	Other Entry
+	Start time.Time
+	End   time.Time
+}
+
+// Gap represents an idle period within the working hours. The Entry is the
+// entry right after the gap, except when the gap is at the end of the working
+// hours, in which case the Entry is the last entry of the day.
+type Gap struct {
+	Entry Entry
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the idle period.
+func (g Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// Analysis is the result of analyzing entries for overlaps and idle gaps.
+type Analysis struct {
+	Overlaps []Overlap
+	Gaps     []Gap
+
+	location *time.Location
+}
+
+// HasOverlaps returns true if there is at least one overlap detected.
+func (a *Analysis) HasOverlaps() bool {
+	return len(a.Overlaps) > 0
+}
+
+// Warnings returns the human-readable warnings grouped by the entry key.
+// Since the keys are matching the keys used for merging entries, the warnings
+// can be looked up for the entries of a Worklog as well.
+func (a *Analysis) Warnings() map[string][]string {
+	warnings := map[string][]string{}
+
+	for _, overlap := range a.Overlaps {
+		key := overlap.Entry.Key()
+		warnings[key] = append(warnings[key], fmt.Sprintf(
+			"overlaps %s (%s-%s)",
+			overlap.Other.Task.Name,
+			inLocation(overlap.Start, a.location).Format(analysisTimeFormat),
+			inLocation(overlap.End, a.location).Format(analysisTimeFormat),
+		))
+
+		otherKey := overlap.Other.Key()
+		warnings[otherKey] = append(warnings[otherKey], fmt.Sprintf(
+			"overlaps %s (%s-%s)",
+			overlap.Entry.Task.Name,
+			inLocation(overlap.Start, a.location).Format(analysisTimeFormat),
+			inLocation(overlap.End, a.location).Format(analysisTimeFormat),
+		))
+	}
+
+	for _, gap := range a.Gaps {
+		key := gap.Entry.Key()
+		warnings[key] = append(warnings[key], fmt.Sprintf(
+			"idle %s (%s-%s)",
+			gap.Duration().String(),
+			inLocation(gap.Start, a.location).Format(analysisTimeFormat),
+			inLocation(gap.End, a.location).Format(analysisTimeFormat),
+		))
+	}
+
+	return warnings
+}
+
+// sortedByStart returns a copy of the entries that have a start date, sorted by
+// the start date.
+func sortedByStart(entries Entries) Entries {
+	var sorted Entries
+
+	for _, entry := range entries {
+		if !entry.Start.IsZero() {
+			sorted = append(sorted, entry)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	return sorted
+}
+
+func findOverlaps(sorted Entries) []Overlap {
+	var overlaps []Overlap
+
+	for i, entry := range sorted {
+		for _, other := range sorted[i+1:] {
+			// The entries are sorted, so no other entry can overlap
+			if !other.Start.Before(entry.EndTime()) {
+				break
+			}
+
+			if entry.IsSameRecord(&other) {
+				continue
+			}
+
+			end := entry.EndTime()
+			if otherEnd := other.EndTime(); otherEnd.Before(end) {
+				end = otherEnd
+			}
+
+			overlaps = append(overlaps, Overlap{
+				Entry: entry,
+				Other: other,
+				Start: other.Start,
+				End:   end,
+			})
+		}
+	}
+
+	return overlaps
+}
+
+func findGaps(sorted Entries, opts *AnalyzeOpts) []Gap {
+	var gaps []Gap
+
+	days := map[time.Time]Entries{}
+	var dayKeys []time.Time
+
+	for _, entry := range sorted {
+		midnight := localDate(entry.Start, opts.Location)
+
+		if _, ok := days[midnight]; !ok {
+			dayKeys = append(dayKeys, midnight)
+		}
+
+		days[midnight] = append(days[midnight], entry)
+	}
+
+	for _, midnight := range dayKeys {
+		workdayStart := midnight.Add(opts.WorkdayStart)
+		workdayEnd := midnight.Add(opts.WorkdayEnd)
+
+		idleSince := workdayStart
+		dayEntries := days[midnight]
+
+		for _, entry := range dayEntries {
+			if entry.Start.After(idleSince) && idleSince.Before(workdayEnd) {
+				gapEnd := entry.Start
+				if gapEnd.After(workdayEnd) {
+					gapEnd = workdayEnd
+				}
+
+				if gapEnd.Sub(idleSince) >= opts.IdleGapThreshold {
+					gaps = append(gaps, Gap{Entry: entry, Start: idleSince, End: gapEnd})
+				}
+			}
+
+			if end := entry.EndTime(); end.After(idleSince) {
+				idleSince = end
+			}
+		}
+
+		if workdayEnd.Sub(idleSince) >= opts.IdleGapThreshold {
+			gaps = append(gaps, Gap{Entry: dayEntries[len(dayEntries)-1], Start: idleSince, End: workdayEnd})
+		}
+	}
+
+	return gaps
+}
+
+// Analyze detects the overlapping entries and the idle gaps within the working
+// hours. The entries should be analyzed before merging them, otherwise the
+// start and end of the entries are not precise.
+func Analyze(entries Entries, opts *AnalyzeOpts) Analysis {
+	sorted := sortedByStart(entries)
+
+	analysis := Analysis{
+		Overlaps: findOverlaps(sorted),
+		location: opts.Location,
+	}
+
+	if opts.IdleGapThreshold > 0 && opts.WorkdayEnd > opts.WorkdayStart {
+		analysis.Gaps = findGaps(sorted, opts)
+	}
+
+	return analysis
+}
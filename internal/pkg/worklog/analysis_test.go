@@ -0,0 +1,123 @@
+package worklog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func getAnalysisTestEntry(summary string, start time.Time, duration time.Duration) worklog.Entry {
+	entry := getCompleteTestEntry()
+	entry.Summary = summary
+	entry.Start = start
+	entry.BillableDuration = duration
+	return entry
+}
+
+func TestAnalyze_Overlaps(t *testing.T) {
+	start := time.Date(2021, 10, 2, 9, 0, 0, 0, time.Local)
+
+	first := getAnalysisTestEntry("first", start, time.Hour*2)
+	second := getAnalysisTestEntry("second", start.Add(time.Hour), time.Hour)
+	third := getAnalysisTestEntry("third", start.Add(time.Hour*2), time.Hour)
+
+	analysis := worklog.Analyze(worklog.Entries{third, second, first}, &worklog.AnalyzeOpts{})
+
+	require.True(t, analysis.HasOverlaps())
+	require.Equal(t, []worklog.Overlap{
+		{
+			Entry: first,
+			Other: second,
+			Start: start.Add(time.Hour),
+			End:   start.Add(time.Hour * 2),
+		},
+	}, analysis.Overlaps)
+	require.Nil(t, analysis.Gaps)
+
+	warnings := analysis.Warnings()
+	require.Equal(t, []string{"overlaps TASK-0123 (10:00-11:00)"}, warnings[first.Key()])
+	require.Equal(t, []string{"overlaps TASK-0123 (10:00-11:00)"}, warnings[second.Key()])
+	require.Empty(t, warnings[third.Key()])
+}
+
+func TestAnalyze_Overlaps_SameRecord(t *testing.T) {
+	start := time.Date(2021, 10, 2, 9, 0, 0, 0, time.Local)
+
+	first := getAnalysisTestEntry("split", start, time.Hour)
+	first.Task.Name = "TASK-1"
+	first.Source = "toggl"
+	first.SourceID = "123"
+
+	second := getAnalysisTestEntry("split", start, time.Hour)
+	second.Task.Name = "TASK-2"
+	second.Source = "toggl"
+	second.SourceID = "123"
+
+	analysis := worklog.Analyze(worklog.Entries{first, second}, &worklog.AnalyzeOpts{})
+	require.False(t, analysis.HasOverlaps())
+}
+
+func TestAnalyze_Gaps(t *testing.T) {
+	midnight := time.Date(2021, 10, 2, 0, 0, 0, 0, time.Local)
+
+	first := getAnalysisTestEntry("first", midnight.Add(time.Hour*9), time.Hour)
+	second := getAnalysisTestEntry("second", midnight.Add(time.Hour*12), time.Hour*4)
+	third := getAnalysisTestEntry("third", midnight.Add(time.Hour*16), time.Minute*50)
+
+	analysis := worklog.Analyze(worklog.Entries{first, second, third}, &worklog.AnalyzeOpts{
+		WorkdayStart:     time.Hour * 9,
+		WorkdayEnd:       time.Hour * 17,
+		IdleGapThreshold: time.Minute * 30,
+	})
+
+	require.False(t, analysis.HasOverlaps())
+	require.Equal(t, []worklog.Gap{
+		{
+			Entry: second,
+			Start: midnight.Add(time.Hour * 10),
+			End:   midnight.Add(time.Hour * 12),
+		},
+	}, analysis.Gaps)
+
+	warnings := analysis.Warnings()
+	require.Equal(t, []string{"idle 2h0m0s (10:00-12:00)"}, warnings[second.Key()])
+}
+
+func TestAnalyze_Gaps_Disabled(t *testing.T) {
+	midnight := time.Date(2021, 10, 2, 0, 0, 0, 0, time.Local)
+
+	entry := getAnalysisTestEntry("first", midnight.Add(time.Hour*12), time.Hour)
+
+	analysis := worklog.Analyze(worklog.Entries{entry}, &worklog.AnalyzeOpts{
+		WorkdayStart: time.Hour * 9,
+		WorkdayEnd:   time.Hour * 17,
+	})
+
+	require.Nil(t, analysis.Gaps)
+}
+
+func TestAnalyze_Gaps_Location(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.Nil(t, err)
+
+	// 09:00 in Tokyo, while it is still the previous day in UTC
+	start := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
+	entry := getAnalysisTestEntry("first", start, time.Hour)
+
+	analysis := worklog.Analyze(worklog.Entries{entry}, &worklog.AnalyzeOpts{
+		WorkdayStart:     time.Hour * 9,
+		WorkdayEnd:       time.Hour * 17,
+		IdleGapThreshold: time.Minute * 30,
+		Location:         tokyo,
+	})
+
+	require.Len(t, analysis.Gaps, 1)
+	require.Equal(t, entry, analysis.Gaps[0].Entry)
+	require.True(t, analysis.Gaps[0].Start.Equal(start.Add(time.Hour)))
+	require.True(t, analysis.Gaps[0].End.Equal(start.Add(time.Hour*8)))
+
+	warnings := analysis.Warnings()
+	require.Equal(t, []string{"idle 7h0m0s (10:00-17:00)"}, warnings[entry.Key()])
+}
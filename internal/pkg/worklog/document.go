@@ -0,0 +1,188 @@
+package worklog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// DocumentVersion is the version of the document format written by
+	// Document.Encode. The version must be increased on every incompatible
+	// change of the format.
+	DocumentVersion int = 1
+)
+
+var (
+	// ErrUnsupportedDocumentVersion returns when the document was written in
+	// a format version that cannot be read.
+	ErrUnsupportedDocumentVersion = errors.New("unsupported document version")
+	// ErrInvalidDocument returns when the document cannot be decoded.
+	ErrInvalidDocument = errors.New("invalid document")
+)
+
+// DocumentOpts specifies the metadata of the document.
+type DocumentOpts struct {
+	// Source is the name of the source the entries were fetched from.
+	Source string
+	// Start is the start of the fetched date range.
+	Start time.Time
+	// End is the end of the fetched date range.
+	End time.Time
+	// RoundToClosestMinute indicates to round the billable and unbillable
+	// duration of the entries separately to the closest minute when uploading.
+	RoundToClosestMinute bool
+}
+
+// Document is the versioned representation of the entries fetched in a run,
+// used to split the fetching and uploading into separate runs. The entries
+// are stored before merging with their raw durations, so the worklog built
+// from the document is the same as the worklog built after fetching, and the
+// durations are rounded after merging, as they are when syncing.
+type Document struct {
+	Version              int
+	Source               string
+	Start                time.Time
+	End                  time.Time
+	CreatedAt            time.Time
+	RoundToClosestMinute bool
+	Entries              Entries
+}
+
+// documentEntry is the stable JSON representation of an entry. Durations are
+// written as Go durations, like "1h30m0s", to be readable by reviewers.
+type documentEntry struct {
+	Client             IDNameField   `json:"client"`
+	Project            IDNameField   `json:"project"`
+	Task               IDNameField   `json:"task"`
+	Summary            string        `json:"summary"`
+	Notes              string        `json:"notes"`
+	Tags               []IDNameField `json:"tags"`
+	Start              time.Time     `json:"start"`
+	End                time.Time     `json:"end"`
+	BillableDuration   string        `json:"billable_duration"`
+	UnbillableDuration string        `json:"unbillable_duration"`
+	Source             string        `json:"source"`
+	SourceID           string        `json:"source_id"`
+	Running            bool          `json:"running"`
+}
+
+// document is the stable JSON representation of the Document.
+type document struct {
+	Version              int             `json:"version"`
+	Source               string          `json:"source"`
+	Start                time.Time       `json:"start"`
+	End                  time.Time       `json:"end"`
+	CreatedAt            time.Time       `json:"created_at"`
+	RoundToClosestMinute bool            `json:"round_to_closest_minute"`
+	Entries              []documentEntry `json:"entries"`
+}
+
+// NewDocument returns a new document of the current version holding the
+// entries.
+func NewDocument(entries Entries, opts *DocumentOpts) *Document {
+	documentEntries := make(Entries, len(entries))
+	copy(documentEntries, entries)
+
+	return &Document{
+		Version:              DocumentVersion,
+		Source:               opts.Source,
+		Start:                opts.Start,
+		End:                  opts.End,
+		CreatedAt:            time.Now(),
+		RoundToClosestMinute: opts.RoundToClosestMinute,
+		Entries:              documentEntries,
+	}
+}
+
+// Encode writes the document to the writer as indented JSON.
+func (d *Document) Encode(w io.Writer) error {
+	doc := document{
+		Version:              d.Version,
+		Source:               d.Source,
+		Start:                d.Start,
+		End:                  d.End,
+		CreatedAt:            d.CreatedAt,
+		RoundToClosestMinute: d.RoundToClosestMinute,
+		Entries:              make([]documentEntry, 0, len(d.Entries)),
+	}
+
+	for _, entry := range d.Entries {
+		doc.Entries = append(doc.Entries, documentEntry{
+			Client:             entry.Client,
+			Project:            entry.Project,
+			Task:               entry.Task,
+			Summary:            entry.Summary,
+			Notes:              entry.Notes,
+			Tags:               entry.Tags,
+			Start:              entry.Start,
+			End:                entry.End,
+			BillableDuration:   entry.BillableDuration.String(),
+			UnbillableDuration: entry.UnbillableDuration.String(),
+			Source:             entry.Source,
+			SourceID:           entry.SourceID,
+			Running:            entry.Running,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(doc)
+}
+
+// DecodeDocument reads the document from the reader. Documents written in an
+// unsupported version are rejected.
+func DecodeDocument(r io.Reader) (*Document, error) {
+	var doc document
+
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidDocument, err)
+	}
+
+	if doc.Version != DocumentVersion {
+		return nil, fmt.Errorf("%v: %d", ErrUnsupportedDocumentVersion, doc.Version)
+	}
+
+	entries := make(Entries, 0, len(doc.Entries))
+
+	for i, docEntry := range doc.Entries {
+		billableDuration, err := time.ParseDuration(docEntry.BillableDuration)
+		if err != nil {
+			return nil, fmt.Errorf("%v: entry %d: %v", ErrInvalidDocument, i, err)
+		}
+
+		unbillableDuration, err := time.ParseDuration(docEntry.UnbillableDuration)
+		if err != nil {
+			return nil, fmt.Errorf("%v: entry %d: %v", ErrInvalidDocument, i, err)
+		}
+
+		entries = append(entries, Entry{
+			Client:             docEntry.Client,
+			Project:            docEntry.Project,
+			Task:               docEntry.Task,
+			Summary:            docEntry.Summary,
+			Notes:              docEntry.Notes,
+			Tags:               docEntry.Tags,
+			Start:              docEntry.Start,
+			End:                docEntry.End,
+			BillableDuration:   billableDuration,
+			UnbillableDuration: unbillableDuration,
+			Source:             docEntry.Source,
+			SourceID:           docEntry.SourceID,
+			Running:            docEntry.Running,
+		})
+	}
+
+	return &Document{
+		Version:              doc.Version,
+		Source:               doc.Source,
+		Start:                doc.Start,
+		End:                  doc.End,
+		CreatedAt:            doc.CreatedAt,
+		RoundToClosestMinute: doc.RoundToClosestMinute,
+		Entries:              entries,
+	}, nil
+}
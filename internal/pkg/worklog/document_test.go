@@ -0,0 +1,94 @@
+package worklog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDocument(t *testing.T) {
+	entry := getCompleteTestEntry()
+	entry.BillableDuration = time.Hour + 40*time.Second
+	entry.UnbillableDuration = 29 * time.Second
+
+	document := worklog.NewDocument(worklog.Entries{entry}, &worklog.DocumentOpts{
+		Source:               "clockify",
+		Start:                time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+		End:                  time.Date(2021, 10, 8, 0, 0, 0, 0, time.UTC),
+		RoundToClosestMinute: true,
+	})
+
+	require.Equal(t, worklog.DocumentVersion, document.Version)
+	require.Equal(t, "clockify", document.Source)
+	require.True(t, document.RoundToClosestMinute)
+	require.False(t, document.CreatedAt.IsZero())
+	require.Len(t, document.Entries, 1)
+
+	// The durations are rounded when uploading, after merging the entries
+	require.Equal(t, time.Hour+40*time.Second, document.Entries[0].BillableDuration)
+	require.Equal(t, 29*time.Second, document.Entries[0].UnbillableDuration)
+}
+
+func TestDocument_EncodeDecode(t *testing.T) {
+	entry := getCompleteTestEntry()
+	entry.End = entry.Start.Add(2 * time.Hour)
+	entry.UnbillableDuration = 90 * time.Second
+	entry.Tags = []worklog.IDNameField{{ID: "tag-id", Name: "meeting"}}
+	entry.Source = "clockify"
+	entry.SourceID = "entry-id"
+
+	document := worklog.NewDocument(worklog.Entries{entry, getIncompleteTestEntry()}, &worklog.DocumentOpts{
+		Source: "clockify",
+		Start:  time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+		End:    time.Date(2021, 10, 8, 0, 0, 0, 0, time.UTC),
+	})
+	document.CreatedAt = time.Date(2021, 10, 8, 9, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	require.Nil(t, document.Encode(&buf))
+	require.Contains(t, buf.String(), `"version": 1`)
+	require.Contains(t, buf.String(), `"unbillable_duration": "1m30s"`)
+
+	decoded, err := worklog.DecodeDocument(&buf)
+	require.Nil(t, err)
+	require.Equal(t, document, decoded)
+
+	wl := worklog.NewWorklog(decoded.Entries, &worklog.FilterOpts{})
+	require.Len(t, wl.CompleteEntries(), 1)
+	require.Len(t, wl.IncompleteEntries(), 1)
+}
+
+func TestDecodeDocument_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		document string
+		err      error
+	}{
+		"not json": {
+			document: "week42",
+			err:      worklog.ErrInvalidDocument,
+		},
+		"unsupported version": {
+			document: `{"version": 2, "entries": []}`,
+			err:      worklog.ErrUnsupportedDocumentVersion,
+		},
+		"missing version": {
+			document: `{"entries": []}`,
+			err:      worklog.ErrUnsupportedDocumentVersion,
+		},
+		"invalid duration": {
+			document: `{"version": 1, "entries": [{"billable_duration": "one hour", "unbillable_duration": "0s"}]}`,
+			err:      worklog.ErrInvalidDocument,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := worklog.DecodeDocument(strings.NewReader(test.document))
+			require.ErrorContains(t, err, test.err.Error())
+		})
+	}
+}
@@ -54,16 +54,47 @@ func (e *Entries) GroupByTask() map[string]Entries {
 	return groups
 }
 
+// Deduplicate returns the entries without duplicates, keeping the first
+// occurrence of every entry. Entries are duplicates if they were created from
+// the same source record for the same task, since the parts of a split record
+// are sharing the source record. Entries without a SourceID are duplicates if
+// their task, summary, start, end, and durations are matching.
+func (e *Entries) Deduplicate() Entries {
+	var entries Entries
+	seen := map[string]bool{}
+
+	for _, entry := range *e {
+		key := entry.recordKey()
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
 // Entry represents the worklog entry and contains all the necessary data.
+// The Source and SourceID are identifying the record the entry was created
+// from. Multiple entries can have the same SourceID when a record is split.
 type Entry struct {
 	Client             IDNameField
 	Project            IDNameField
 	Task               IDNameField
 	Summary            string
 	Notes              string
+	Tags               []IDNameField
 	Start              time.Time
+	End                time.Time
 	BillableDuration   time.Duration
 	UnbillableDuration time.Duration
+	Source             string
+	SourceID           string
+	// Running is set if the entry was still running when it was fetched. The
+	// end of a running entry is the time of fetching.
+	Running bool
 }
 
 // Key returns a unique, per entry key used for grouping similar entries.
@@ -71,6 +102,61 @@ func (e *Entry) Key() string {
 	return fmt.Sprintf("%s:%s:%s:%s", e.Project.Name, e.Task.Name, e.Summary, e.Start.Format("2006-01-02"))
 }
 
+// recordKey returns a key identifying the entry within its source.
+func (e *Entry) recordKey() string {
+	if e.SourceID != "" {
+		return fmt.Sprintf("%s:%s:%s", e.Source, e.SourceID, e.Task.ID)
+	}
+
+	return fmt.Sprintf(
+		"%s:%s:%s:%s:%d:%d",
+		e.Task.ID,
+		e.Summary,
+		e.Start.Format(time.RFC3339Nano),
+		e.End.Format(time.RFC3339Nano),
+		e.BillableDuration,
+		e.UnbillableDuration,
+	)
+}
+
+// EndTime returns the end of the entry if set, otherwise it is calculated
+// from the start and the total time spent.
+func (e *Entry) EndTime() time.Time {
+	if !e.End.IsZero() {
+		return e.End
+	}
+
+	return e.Start.Add(e.BillableDuration + e.UnbillableDuration)
+}
+
+// IsSameRecord returns true if both entries were created from the same source
+// record, like the parts of a record split by tags.
+func (e *Entry) IsSameRecord(other *Entry) bool {
+	return e.SourceID != "" && e.Source == other.Source && e.SourceID == other.SourceID
+}
+
+// TagNames returns the name of the tags.
+func (e *Entry) TagNames() []string {
+	var names []string
+
+	for _, tag := range e.Tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
+
+// HasTag returns true if any of the entry's tag names matches the regex.
+func (e *Entry) HasTag(regex *regexp.Regexp) bool {
+	for _, tag := range e.Tags {
+		if regex.MatchString(tag.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IsComplete indicates if the entry has all the necessary fields filled.
 // If all the necessary fields are complete it returns true, otherwise, false.
 func (e *Entry) IsComplete() bool {
@@ -120,9 +206,14 @@ func (e *Entry) SplitByTagsAsTasks(summary string, regex *regexp.Regexp, tags []
 			Task:               task,
 			Summary:            summary,
 			Notes:              e.Notes,
+			Tags:               e.Tags,
 			Start:              e.Start,
+			End:                e.End,
 			BillableDuration:   splitBillable,
 			UnbillableDuration: splitUnbillable,
+			Source:             e.Source,
+			SourceID:           e.SourceID,
+			Running:            e.Running,
 		})
 	}
 
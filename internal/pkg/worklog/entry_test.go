@@ -85,6 +85,36 @@ func TestEntries_GroupByTask(t *testing.T) {
 	assert.Equal(t, 1, len(groups))
 }
 
+func TestEntries_Deduplicate(t *testing.T) {
+	entry := getCompleteTestEntry()
+
+	otherEntry := getCompleteTestEntry()
+	otherEntry.Summary = "Review worklog transfer CLI tool"
+
+	recordedEntry := getCompleteTestEntry()
+	recordedEntry.Source = "toggl"
+	recordedEntry.SourceID = "123"
+
+	// Same record, but a different part of it
+	splitEntry := recordedEntry
+	splitEntry.Task = worklog.IDNameField{ID: "other-task-id", Name: "TASK-0456"}
+
+	// Same record, but fetched again with slightly different details
+	refetchedEntry := recordedEntry
+	refetchedEntry.Notes = "Fetched twice"
+
+	entries := worklog.Entries{
+		entry,
+		otherEntry,
+		entry,
+		recordedEntry,
+		splitEntry,
+		refetchedEntry,
+	}
+
+	assert.Equal(t, worklog.Entries{entry, otherEntry, recordedEntry, splitEntry}, entries.Deduplicate())
+}
+
 func TestEntryKey(t *testing.T) {
 	entry := getCompleteTestEntry()
 	assert.Equal(t, "Internal projects:TASK-0123:Write worklog transfer CLI tool:2021-10-02", entry.Key())
@@ -191,3 +221,35 @@ func TestEntry_SplitByTag(t *testing.T) {
 
 	assert.ElementsMatch(t, expectedEntries, entries)
 }
+
+func TestEntry_HasTag(t *testing.T) {
+	entry := getCompleteTestEntry()
+	assert.False(t, entry.HasTag(regexp.MustCompile(`^meeting$`)))
+
+	entry.Tags = []worklog.IDNameField{{ID: "1", Name: "coffee"}, {ID: "2", Name: "meeting"}}
+	assert.True(t, entry.HasTag(regexp.MustCompile(`^meeting$`)))
+	assert.False(t, entry.HasTag(regexp.MustCompile(`^private$`)))
+}
+
+func TestEntry_EndTime(t *testing.T) {
+	entry := getCompleteTestEntry()
+	assert.Equal(t, entry.Start.Add(entry.BillableDuration), entry.EndTime())
+
+	entry.End = entry.Start.Add(time.Hour * 3)
+	assert.Equal(t, entry.Start.Add(time.Hour*3), entry.EndTime())
+}
+
+func TestEntry_IsSameRecord(t *testing.T) {
+	entry := getCompleteTestEntry()
+	otherEntry := getCompleteTestEntry()
+	assert.False(t, entry.IsSameRecord(&otherEntry))
+
+	entry.Source = "toggl"
+	entry.SourceID = "123"
+	otherEntry.Source = "toggl"
+	otherEntry.SourceID = "123"
+	assert.True(t, entry.IsSameRecord(&otherEntry))
+
+	otherEntry.Source = "clockify"
+	assert.False(t, entry.IsSameRecord(&otherEntry))
+}
@@ -0,0 +1,294 @@
+package worklog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// RuleMaxDailyDuration is the name of the MaxDailyDurationRule.
+	RuleMaxDailyDuration string = "max-daily-duration"
+	// RuleMinEntryDuration is the name of the MinEntryDurationRule.
+	RuleMinEntryDuration string = "min-entry-duration"
+	// RuleMaxWeeklyClientBillable is the name of the MaxWeeklyClientBillableRule.
+	RuleMaxWeeklyClientBillable string = "max-weekly-client-billable"
+	// RuleNoWeekends is the name of the NoWeekendsRule.
+	RuleNoWeekends string = "no-weekends"
+
+	validationDateFormat string = "2006-01-02"
+)
+
+var (
+	// ErrInvalidSeverity returns when the severity is not known.
+	ErrInvalidSeverity = errors.New("invalid severity")
+)
+
+// Severity represents how serious a rule violation is.
+type Severity string
+
+const (
+	// SeverityWarn indicates that the violation should be reported, but it
+	// should not block the sync.
+	SeverityWarn Severity = "warn"
+	// SeverityError indicates that the violation should block the sync.
+	SeverityError Severity = "error"
+)
+
+// ParseSeverity returns the Severity for the given string representation.
+func ParseSeverity(rawSeverity string) (Severity, error) {
+	switch severity := Severity(rawSeverity); severity {
+	case SeverityWarn, SeverityError:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("%v: %s", ErrInvalidSeverity, rawSeverity)
+	}
+}
+
+// Violation represents a broken validation rule.
+type Violation struct {
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+// ValidationReport is the collection of violations.
+type ValidationReport []Violation
+
+// HasErrors returns true if any of the violations has error severity.
+func (r ValidationReport) HasErrors() bool {
+	for _, violation := range r {
+		if violation.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rule specifies the functions used to validate entries before upload.
+type Rule interface {
+	// Name returns the name of the rule used in the reports and configuration.
+	Name() string
+	// Validate checks the entries and returns the violations. If the entries
+	// are valid, no violations will return.
+	Validate(entries Entries) []Violation
+	// ValidatesSessions returns true if the rule checks the entries before
+	// merging, otherwise the rule checks the merged entries.
+	ValidatesSessions() bool
+}
+
+// inLocation returns the time in the given location. If the location is not
+// set, the time returns in the local timezone.
+func inLocation(t time.Time, location *time.Location) time.Time {
+	if location == nil {
+		return t.Local()
+	}
+
+	return t.In(location)
+}
+
+// localDate returns the midnight of the day the given time is on in the given
+// location.
+func localDate(t time.Time, location *time.Location) time.Time {
+	localTime := inLocation(t, location)
+	year, month, day := localTime.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, localTime.Location())
+}
+
+// sortedDates returns the keys of the map in ascending order.
+func sortedDates(durations map[time.Time]time.Duration) []time.Time {
+	var dates []time.Time
+
+	for date := range durations {
+		dates = append(dates, date)
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	return dates
+}
+
+// MaxDailyDurationRule reports the days when the total time spent exceeds
+// the maximum.
+type MaxDailyDurationRule struct {
+	Severity Severity
+	Max      time.Duration
+	// Location sets the timezone of the days. If not set, the local timezone
+	// is used.
+	Location *time.Location
+}
+
+func (r *MaxDailyDurationRule) Name() string {
+	return RuleMaxDailyDuration
+}
+
+func (r *MaxDailyDurationRule) ValidatesSessions() bool {
+	return false
+}
+
+func (r *MaxDailyDurationRule) Validate(entries Entries) []Violation {
+	var violations []Violation
+	dailyDurations := map[time.Time]time.Duration{}
+
+	for _, entry := range entries {
+		dailyDurations[localDate(entry.Start, r.Location)] += entry.BillableDuration + entry.UnbillableDuration
+	}
+
+	for _, date := range sortedDates(dailyDurations) {
+		if spent := dailyDurations[date]; spent > r.Max {
+			violations = append(violations, Violation{
+				Rule:     r.Name(),
+				Severity: r.Severity,
+				Message:  fmt.Sprintf("%s: %s spent, exceeds %s", date.Format(validationDateFormat), spent, r.Max),
+			})
+		}
+	}
+
+	return violations
+}
+
+// MinEntryDurationRule reports the entries shorter than the minimum, like
+// timers started accidentally. The rule checks the entries before merging,
+// otherwise the short entries would be hidden by the merged duration.
+type MinEntryDurationRule struct {
+	Severity Severity
+	Min      time.Duration
+	// Location sets the timezone of the reported dates. If not set, the local
+	// timezone is used.
+	Location *time.Location
+}
+
+func (r *MinEntryDurationRule) Name() string {
+	return RuleMinEntryDuration
+}
+
+func (r *MinEntryDurationRule) ValidatesSessions() bool {
+	return true
+}
+
+func (r *MinEntryDurationRule) Validate(entries Entries) []Violation {
+	var violations []Violation
+
+	for _, entry := range entries {
+		if spent := entry.BillableDuration + entry.UnbillableDuration; spent < r.Min {
+			violations = append(violations, Violation{
+				Rule:     r.Name(),
+				Severity: r.Severity,
+				Message: fmt.Sprintf(
+					"%s (%s): %s spent, shorter than %s",
+					entry.Task.Name,
+					inLocation(entry.Start, r.Location).Format(validationDateFormat),
+					spent,
+					r.Min,
+				),
+			})
+		}
+	}
+
+	return violations
+}
+
+// MaxWeeklyClientBillableRule reports the clients having more billable time
+// on an ISO week than the maximum.
+type MaxWeeklyClientBillableRule struct {
+	Severity Severity
+	Max      time.Duration
+	// Location sets the timezone of the weeks. If not set, the local timezone
+	// is used.
+	Location *time.Location
+}
+
+func (r *MaxWeeklyClientBillableRule) Name() string {
+	return RuleMaxWeeklyClientBillable
+}
+
+func (r *MaxWeeklyClientBillableRule) ValidatesSessions() bool {
+	return false
+}
+
+func (r *MaxWeeklyClientBillableRule) Validate(entries Entries) []Violation {
+	var violations []Violation
+	var keys []string
+
+	weeklyDurations := map[string]time.Duration{}
+
+	for _, entry := range entries {
+		year, week := inLocation(entry.Start, r.Location).ISOWeek()
+		key := fmt.Sprintf("%s %d-W%02d", entry.Client.Name, year, week)
+
+		if _, ok := weeklyDurations[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		weeklyDurations[key] += entry.BillableDuration
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if billable := weeklyDurations[key]; billable > r.Max {
+			violations = append(violations, Violation{
+				Rule:     r.Name(),
+				Severity: r.Severity,
+				Message:  fmt.Sprintf("%s: %s billable, exceeds %s", key, billable, r.Max),
+			})
+		}
+	}
+
+	return violations
+}
+
+// NoWeekendsRule reports the entries logged on Saturday or Sunday.
+type NoWeekendsRule struct {
+	Severity Severity
+	// Location sets the timezone of the days. If not set, the local timezone
+	// is used.
+	Location *time.Location
+}
+
+func (r *NoWeekendsRule) Name() string {
+	return RuleNoWeekends
+}
+
+func (r *NoWeekendsRule) ValidatesSessions() bool {
+	return false
+}
+
+func (r *NoWeekendsRule) Validate(entries Entries) []Violation {
+	var violations []Violation
+
+	for _, entry := range entries {
+		start := inLocation(entry.Start, r.Location)
+
+		if weekday := start.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			violations = append(violations, Violation{
+				Rule:     r.Name(),
+				Severity: r.Severity,
+				Message:  fmt.Sprintf("%s (%s): logged on %s", entry.Task.Name, start.Format(validationDateFormat), weekday),
+			})
+		}
+	}
+
+	return violations
+}
+
+// Validate runs the rules on the complete entries of the worklog, since only
+// the complete entries will be uploaded. The rules validating sessions run on
+// the complete entries before merging.
+func (w *Worklog) Validate(rules []Rule) ValidationReport {
+	var report ValidationReport
+
+	for _, rule := range rules {
+		if rule.ValidatesSessions() {
+			report = append(report, rule.Validate(w.completeSessions)...)
+		} else {
+			report = append(report, rule.Validate(w.completeEntries)...)
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,223 @@
+package worklog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/worklog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverity(t *testing.T) {
+	severity, err := worklog.ParseSeverity("warn")
+	require.Nil(t, err)
+	require.Equal(t, worklog.SeverityWarn, severity)
+
+	severity, err = worklog.ParseSeverity("error")
+	require.Nil(t, err)
+	require.Equal(t, worklog.SeverityError, severity)
+
+	_, err = worklog.ParseSeverity("fatal")
+	require.EqualError(t, err, "invalid severity: fatal")
+}
+
+func TestValidationReport_HasErrors(t *testing.T) {
+	report := worklog.ValidationReport{
+		{Rule: worklog.RuleNoWeekends, Severity: worklog.SeverityWarn},
+	}
+	require.False(t, report.HasErrors())
+
+	report = append(report, worklog.Violation{Rule: worklog.RuleMaxDailyDuration, Severity: worklog.SeverityError})
+	require.True(t, report.HasErrors())
+}
+
+func TestMaxDailyDurationRule_Validate(t *testing.T) {
+	entry := getCompleteTestEntry()
+	entry.Start = time.Date(2021, 10, 2, 9, 0, 0, 0, time.Local)
+	entry.BillableDuration = time.Hour * 6
+	entry.UnbillableDuration = time.Hour * 5
+
+	rule := &worklog.MaxDailyDurationRule{Severity: worklog.SeverityError, Max: time.Hour * 10}
+
+	require.Equal(t, []worklog.Violation{
+		{
+			Rule:     worklog.RuleMaxDailyDuration,
+			Severity: worklog.SeverityError,
+			Message:  "2021-10-02: 11h0m0s spent, exceeds 10h0m0s",
+		},
+	}, rule.Validate(worklog.Entries{entry}))
+
+	entry.UnbillableDuration = 0
+	require.Empty(t, rule.Validate(worklog.Entries{entry}))
+}
+
+func TestMinEntryDurationRule_Validate(t *testing.T) {
+	entry := getCompleteTestEntry()
+	entry.BillableDuration = time.Second * 20
+
+	otherEntry := getCompleteTestEntry()
+
+	rule := &worklog.MinEntryDurationRule{Severity: worklog.SeverityWarn, Min: time.Minute}
+	violations := rule.Validate(worklog.Entries{entry, otherEntry})
+
+	require.Len(t, violations, 1)
+	require.Equal(t, worklog.RuleMinEntryDuration, violations[0].Rule)
+	require.Equal(t, worklog.SeverityWarn, violations[0].Severity)
+}
+
+func TestMaxWeeklyClientBillableRule_Validate(t *testing.T) {
+	monday := time.Date(2021, 10, 4, 9, 0, 0, 0, time.Local)
+
+	var entries worklog.Entries
+	for i := 0; i < 5; i++ {
+		entry := getCompleteTestEntry()
+		entry.Start = monday.Add(time.Hour * 24 * time.Duration(i))
+		entry.BillableDuration = time.Hour * 9
+		entries = append(entries, entry)
+	}
+
+	otherClientEntry := getCompleteTestEntry()
+	otherClientEntry.Client.Name = "Other Company"
+	otherClientEntry.Start = monday
+	entries = append(entries, otherClientEntry)
+
+	rule := &worklog.MaxWeeklyClientBillableRule{Severity: worklog.SeverityError, Max: time.Hour * 40}
+
+	require.Equal(t, []worklog.Violation{
+		{
+			Rule:     worklog.RuleMaxWeeklyClientBillable,
+			Severity: worklog.SeverityError,
+			Message:  "My Awesome Company 2021-W40: 45h0m0s billable, exceeds 40h0m0s",
+		},
+	}, rule.Validate(entries))
+}
+
+func TestNoWeekendsRule_Validate(t *testing.T) {
+	saturday := getCompleteTestEntry()
+	saturday.Start = time.Date(2021, 10, 2, 9, 0, 0, 0, time.Local)
+
+	monday := getCompleteTestEntry()
+	monday.Start = time.Date(2021, 10, 4, 9, 0, 0, 0, time.Local)
+
+	rule := &worklog.NoWeekendsRule{Severity: worklog.SeverityWarn}
+
+	require.Equal(t, []worklog.Violation{
+		{
+			Rule:     worklog.RuleNoWeekends,
+			Severity: worklog.SeverityWarn,
+			Message:  "TASK-0123 (2021-10-02): logged on Saturday",
+		},
+	}, rule.Validate(worklog.Entries{saturday, monday}))
+}
+
+func TestNoWeekendsRule_Validate_Location(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.Nil(t, err)
+
+	// Friday in UTC, but Saturday in Tokyo
+	entry := getCompleteTestEntry()
+	entry.Start = time.Date(2021, 10, 1, 20, 0, 0, 0, time.UTC)
+
+	rule := &worklog.NoWeekendsRule{Severity: worklog.SeverityWarn, Location: tokyo}
+
+	require.Equal(t, []worklog.Violation{
+		{
+			Rule:     worklog.RuleNoWeekends,
+			Severity: worklog.SeverityWarn,
+			Message:  "TASK-0123 (2021-10-02): logged on Saturday",
+		},
+	}, rule.Validate(worklog.Entries{entry}))
+
+	rule.Location = time.UTC
+	require.Empty(t, rule.Validate(worklog.Entries{entry}))
+}
+
+func TestMaxDailyDurationRule_Validate_Location(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.Nil(t, err)
+
+	// The entries are on the same day in Tokyo, but on different days in UTC
+	entry := getCompleteTestEntry()
+	entry.Start = time.Date(2021, 10, 1, 23, 0, 0, 0, time.UTC)
+	entry.BillableDuration = time.Hour * 6
+
+	otherEntry := getCompleteTestEntry()
+	otherEntry.Start = time.Date(2021, 10, 2, 5, 0, 0, 0, time.UTC)
+	otherEntry.BillableDuration = time.Hour * 5
+
+	rule := &worklog.MaxDailyDurationRule{Severity: worklog.SeverityError, Max: time.Hour * 10, Location: tokyo}
+
+	require.Equal(t, []worklog.Violation{
+		{
+			Rule:     worklog.RuleMaxDailyDuration,
+			Severity: worklog.SeverityError,
+			Message:  "2021-10-02: 11h0m0s spent, exceeds 10h0m0s",
+		},
+	}, rule.Validate(worklog.Entries{entry, otherEntry}))
+
+	rule.Location = time.UTC
+	require.Empty(t, rule.Validate(worklog.Entries{entry, otherEntry}))
+}
+
+func TestWorklog_Validate(t *testing.T) {
+	completeEntry := getCompleteTestEntry()
+	completeEntry.BillableDuration = time.Second
+
+	incompleteEntry := getIncompleteTestEntry()
+	incompleteEntry.BillableDuration = time.Second
+
+	wl := worklog.NewWorklog(worklog.Entries{completeEntry, incompleteEntry}, &worklog.FilterOpts{})
+
+	report := wl.Validate([]worklog.Rule{
+		&worklog.MinEntryDurationRule{Severity: worklog.SeverityError, Min: time.Minute},
+	})
+
+	require.Len(t, report, 1)
+	require.True(t, report.HasErrors())
+}
+
+func TestWorklog_Validate_MergedEntries(t *testing.T) {
+	entry := getCompleteTestEntry()
+	entry.BillableDuration = time.Hour
+
+	accidentalEntry := getCompleteTestEntry()
+	accidentalEntry.Start = entry.Start.Add(2 * time.Hour)
+	accidentalEntry.BillableDuration = 20 * time.Second
+
+	wl := worklog.NewWorklog(worklog.Entries{entry, accidentalEntry}, &worklog.FilterOpts{})
+	require.Len(t, wl.CompleteEntries(), 1)
+
+	// The short entry is reported, even though it is merged into a longer one
+	report := wl.Validate([]worklog.Rule{
+		&worklog.MinEntryDurationRule{Severity: worklog.SeverityWarn, Min: time.Minute},
+		&worklog.MaxDailyDurationRule{Severity: worklog.SeverityWarn, Max: time.Hour},
+	})
+
+	require.Len(t, report, 2)
+	require.Equal(t, worklog.RuleMinEntryDuration, report[0].Rule)
+	require.Contains(t, report[0].Message, "20s spent")
+	require.Equal(t, worklog.RuleMaxDailyDuration, report[1].Rule)
+}
+
+func TestWorklog_Validate_DroppedEntries(t *testing.T) {
+	entry := getCompleteTestEntry()
+	entry.BillableDuration = time.Hour
+
+	accidentalEntry := getCompleteTestEntry()
+	accidentalEntry.Start = entry.Start.Add(2 * time.Hour)
+	accidentalEntry.BillableDuration = 20 * time.Second
+
+	wl := worklog.NewWorklog(worklog.Entries{entry, accidentalEntry}, &worklog.FilterOpts{
+		MinDuration: time.Minute,
+	})
+	require.Len(t, wl.CompleteEntries(), 1)
+	require.Equal(t, time.Hour, wl.CompleteEntries()[0].BillableDuration)
+
+	// The short entry is reported, even though it is dropped
+	report := wl.Validate([]worklog.Rule{
+		&worklog.MinEntryDurationRule{Severity: worklog.SeverityWarn, Min: time.Minute},
+	})
+
+	require.Len(t, report, 1)
+	require.Contains(t, report[0].Message, "20s spent")
+}
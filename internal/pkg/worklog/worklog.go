@@ -1,6 +1,11 @@
 package worklog
 
-import "regexp"
+import (
+	"regexp"
+	"time"
+
+	"github.com/gabor-boros/minutes/internal/pkg/utils"
+)
 
 // FilterOpts represents the worklog creation filtering options.
 // When filtering options are set, the entries are matching the regex will be
@@ -8,15 +13,37 @@ import "regexp"
 // could be part of the fetching process, though that would be less flexible as
 // some APIs are not allowing filtering. Also, this way, we can filter results
 // using regex.
+// The exclusive filters are doing the opposite, the entries matching the regex
+// will be dropped. In case of tags, an entry matches if any of its tags matches.
 type FilterOpts struct {
 	Client  *regexp.Regexp
 	Project *regexp.Regexp
+	Task    *regexp.Regexp
+	Summary *regexp.Regexp
+	Tag     *regexp.Regexp
+
+	ExcludeClient  *regexp.Regexp
+	ExcludeProject *regexp.Regexp
+	ExcludeTask    *regexp.Regexp
+	ExcludeSummary *regexp.Regexp
+	ExcludeTag     *regexp.Regexp
+
+	// BillableOnly keeps the entries that have billable duration.
+	BillableOnly bool
+	// UnbillableOnly keeps the entries that have unbillable duration.
+	UnbillableOnly bool
+	// MinDuration drops the entries shorter than the duration, like timers
+	// started accidentally. The entries are dropped before merging.
+	MinDuration time.Duration
 }
 
 // Worklog is the collection of multiple Entries.
 type Worklog struct {
 	completeEntries   Entries
 	incompleteEntries Entries
+	// completeSessions are the complete entries before merging, including
+	// the ones dropped for being shorter than the minimum duration.
+	completeSessions Entries
 }
 
 // CompleteEntries returns those entries which necessary fields were filled.
@@ -29,20 +56,68 @@ func (w *Worklog) IncompleteEntries() Entries {
 	return w.incompleteEntries
 }
 
+// isIncluded returns true if the regex is not set or the value matches it.
+func isIncluded(regex *regexp.Regexp, value string) bool {
+	return regex == nil || regex.MatchString(value)
+}
+
+// isExcluded returns true if the regex is set and the value matches it.
+func isExcluded(regex *regexp.Regexp, value string) bool {
+	return utils.IsRegexSet(regex) && regex.MatchString(value)
+}
+
 // isEntryMatching returns true if the entry matching the filter options.
 func isEntryMatching(entry Entry, opts *FilterOpts) bool {
-	isClientMatching := opts.Client == nil || opts.Client.MatchString(entry.Client.Name)
-	isProjectMatching := opts.Project == nil || opts.Project.MatchString(entry.Project.Name)
+	isClientMatching := isIncluded(opts.Client, entry.Client.Name) && !isExcluded(opts.ExcludeClient, entry.Client.Name)
+	isProjectMatching := isIncluded(opts.Project, entry.Project.Name) && !isExcluded(opts.ExcludeProject, entry.Project.Name)
+	isTaskMatching := isIncluded(opts.Task, entry.Task.Name) && !isExcluded(opts.ExcludeTask, entry.Task.Name)
+	isSummaryMatching := isIncluded(opts.Summary, entry.Summary) && !isExcluded(opts.ExcludeSummary, entry.Summary)
+
+	isTagMatching := !utils.IsRegexSet(opts.Tag) || entry.HasTag(opts.Tag)
+	if utils.IsRegexSet(opts.ExcludeTag) && entry.HasTag(opts.ExcludeTag) {
+		isTagMatching = false
+	}
 
-	return isClientMatching && isProjectMatching
+	isBillableMatching := !opts.BillableOnly || entry.BillableDuration > 0
+	isUnbillableMatching := !opts.UnbillableOnly || entry.UnbillableDuration > 0
+	isDurationMatching := entry.BillableDuration+entry.UnbillableDuration >= opts.MinDuration
+
+	return isClientMatching &&
+		isProjectMatching &&
+		isTaskMatching &&
+		isSummaryMatching &&
+		isTagMatching &&
+		isBillableMatching &&
+		isUnbillableMatching &&
+		isDurationMatching
 }
 
-// NewWorklog creates a worklog from the given set of entries and merges them.
-func NewWorklog(entries Entries, opts *FilterOpts) Worklog {
-	var filteredEntries Entries
+// mergeTags returns the union of the tags, keeping the order of appearance.
+func mergeTags(tags []IDNameField, otherTags []IDNameField) []IDNameField {
+	// Copy the tags to not modify the underlying array of the original entry
+	merged := append([]IDNameField{}, tags...)
 
-	worklog := Worklog{}
-	mergedEntries := map[string]Entry{}
+	for _, otherTag := range otherTags {
+		isStored := false
+
+		for _, tag := range merged {
+			if tag == otherTag {
+				isStored = true
+				break
+			}
+		}
+
+		if !isStored {
+			merged = append(merged, otherTag)
+		}
+	}
+
+	return merged
+}
+
+// FilterEntries returns the entries matching the filter options.
+func FilterEntries(entries Entries, opts *FilterOpts) Entries {
+	var filteredEntries Entries
 
 	for _, entry := range entries {
 		if isEntryMatching(entry, opts) {
@@ -50,7 +125,29 @@ func NewWorklog(entries Entries, opts *FilterOpts) Worklog {
 		}
 	}
 
-	for _, entry := range filteredEntries {
+	return filteredEntries
+}
+
+// NewWorklog creates a worklog from the given set of entries and merges them.
+func NewWorklog(entries Entries, opts *FilterOpts) Worklog {
+	worklog := Worklog{}
+	mergedEntries := map[string]Entry{}
+
+	// The sessions are kept regardless of the minimum duration, so the rules
+	// can report the dropped entries too
+	sessionOpts := *opts
+	sessionOpts.MinDuration = 0
+	sessions := FilterEntries(entries, &sessionOpts)
+
+	for _, entry := range sessions {
+		if entry.IsComplete() {
+			worklog.completeSessions = append(worklog.completeSessions, entry)
+		}
+
+		if !isEntryMatching(entry, opts) {
+			continue
+		}
+
 		key := entry.Key()
 		storedEntry, isStored := mergedEntries[key]
 
@@ -61,6 +158,13 @@ func NewWorklog(entries Entries, opts *FilterOpts) Worklog {
 
 		storedEntry.BillableDuration += entry.BillableDuration
 		storedEntry.UnbillableDuration += entry.UnbillableDuration
+		storedEntry.Tags = mergeTags(storedEntry.Tags, entry.Tags)
+
+		if entry.End.After(storedEntry.End) {
+			storedEntry.End = entry.End
+		}
+
+		storedEntry.Running = storedEntry.Running || entry.Running
 
 		noteSeparator := ""
 		if storedEntry.Notes != "" && entry.Notes != storedEntry.Notes {
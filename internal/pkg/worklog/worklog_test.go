@@ -179,3 +179,147 @@ func TestWorklogFilterEntries(t *testing.T) {
 
 	assert.ElementsMatch(t, worklog.Entries{entry1, entry2}, wl.CompleteEntries())
 }
+
+func TestWorklogFilterEntries_Exclude(t *testing.T) {
+	entry1 := getCompleteTestEntry()
+	entry1.Client.Name = "ACME Inc."
+	entry1.Project.Name = "redesign website"
+
+	entry2 := getCompleteTestEntry()
+	entry2.Client.Name = "ACME Inc."
+	entry2.Project.Name = "internal meetings"
+
+	entry3 := getCompleteTestEntry()
+	entry3.Client.Name = "Other Inc."
+	entry3.Project.Name = "redesign website"
+
+	filterOpts := &worklog.FilterOpts{
+		ExcludeClient:  regexp.MustCompile(`^Other`),
+		ExcludeProject: regexp.MustCompile(`meetings`),
+	}
+
+	wl := worklog.NewWorklog(worklog.Entries{
+		entry1,
+		entry2,
+		entry3,
+	}, filterOpts)
+
+	assert.ElementsMatch(t, worklog.Entries{entry1}, wl.CompleteEntries())
+}
+
+func TestWorklogFilterEntries_TaskAndSummary(t *testing.T) {
+	entry1 := getCompleteTestEntry()
+	entry1.Task.Name = "TASK-1"
+	entry1.Summary = "Implement the feature"
+
+	entry2 := getCompleteTestEntry()
+	entry2.Task.Name = "TASK-2"
+	entry2.Summary = "Implement the other feature"
+
+	entry3 := getCompleteTestEntry()
+	entry3.Task.Name = "TASK-3"
+	entry3.Summary = "Review the feature"
+
+	entry4 := getCompleteTestEntry()
+	entry4.Task.Name = "OTHER-1"
+	entry4.Summary = "Implement the feature"
+
+	filterOpts := &worklog.FilterOpts{
+		Task:           regexp.MustCompile(`^TASK-\d+$`),
+		Summary:        regexp.MustCompile(`^Implement`),
+		ExcludeSummary: regexp.MustCompile(`other`),
+	}
+
+	entries := worklog.FilterEntries(worklog.Entries{
+		entry1,
+		entry2,
+		entry3,
+		entry4,
+	}, filterOpts)
+
+	assert.ElementsMatch(t, worklog.Entries{entry1}, entries)
+}
+
+func TestWorklogFilterEntries_Tags(t *testing.T) {
+	entry1 := getCompleteTestEntry()
+	entry1.Tags = []worklog.IDNameField{{ID: "1", Name: "meeting"}}
+
+	entry2 := getCompleteTestEntry()
+	entry2.Tags = []worklog.IDNameField{{ID: "1", Name: "meeting"}, {ID: "2", Name: "#private"}}
+
+	entry3 := getCompleteTestEntry()
+
+	entries := worklog.FilterEntries(worklog.Entries{
+		entry1,
+		entry2,
+		entry3,
+	}, &worklog.FilterOpts{
+		ExcludeTag: regexp.MustCompile(`^#private$`),
+	})
+	assert.ElementsMatch(t, worklog.Entries{entry1, entry3}, entries)
+
+	entries = worklog.FilterEntries(worklog.Entries{
+		entry1,
+		entry2,
+		entry3,
+	}, &worklog.FilterOpts{
+		Tag: regexp.MustCompile(`^meeting$`),
+	})
+	assert.ElementsMatch(t, worklog.Entries{entry1, entry2}, entries)
+}
+
+func TestWorklogFilterEntries_Billable(t *testing.T) {
+	billableEntry := getCompleteTestEntry()
+
+	unbillableEntry := getCompleteTestEntry()
+	unbillableEntry.UnbillableDuration = billableEntry.BillableDuration
+	unbillableEntry.BillableDuration = 0
+
+	entries := worklog.FilterEntries(worklog.Entries{
+		billableEntry,
+		unbillableEntry,
+	}, &worklog.FilterOpts{
+		BillableOnly: true,
+	})
+	assert.Equal(t, worklog.Entries{billableEntry}, entries)
+
+	entries = worklog.FilterEntries(worklog.Entries{
+		billableEntry,
+		unbillableEntry,
+	}, &worklog.FilterOpts{
+		UnbillableOnly: true,
+	})
+	assert.Equal(t, worklog.Entries{unbillableEntry}, entries)
+}
+
+func TestWorklogFilterEntries_MinDuration(t *testing.T) {
+	entry := getCompleteTestEntry()
+	entry.BillableDuration = time.Hour
+
+	accidentalEntry := getCompleteTestEntry()
+	accidentalEntry.BillableDuration = 20 * time.Second
+
+	entries := worklog.FilterEntries(worklog.Entries{
+		entry,
+		accidentalEntry,
+	}, &worklog.FilterOpts{
+		MinDuration: time.Minute,
+	})
+	assert.Equal(t, worklog.Entries{entry}, entries)
+}
+
+func TestWorklogMergeTags(t *testing.T) {
+	entry := getCompleteTestEntry()
+	entry.Tags = []worklog.IDNameField{{ID: "1", Name: "meeting"}}
+
+	otherEntry := getCompleteTestEntry()
+	otherEntry.Tags = []worklog.IDNameField{{ID: "1", Name: "meeting"}, {ID: "2", Name: "coffee"}}
+
+	wl := worklog.NewWorklog(worklog.Entries{entry, otherEntry}, &worklog.FilterOpts{})
+
+	assert.Equal(t, []worklog.IDNameField{
+		{ID: "1", Name: "meeting"},
+		{ID: "2", Name: "coffee"},
+	}, wl.CompleteEntries()[0].Tags)
+	assert.Len(t, entry.Tags, 1)
+}